@@ -22,24 +22,31 @@ type Call struct {
 
 	Parent *ir.Function
 
-	invalids []string
+	invalids []invalid
 }
 
-func (c *Call) Invalid(msg string) {
-	c.invalids = append(c.invalids, msg)
+// invalid is a problem found by a check, with the options, such as
+// suggested fixes, that it will be reported with.
+type invalid struct {
+	msg  string
+	opts []report.Option
+}
+
+func (c *Call) Invalid(msg string, opts ...report.Option) {
+	c.invalids = append(c.invalids, invalid{msg, opts})
 }
 
 type Argument struct {
 	Value    Value
-	invalids []string
+	invalids []invalid
 }
 
 type Value struct {
 	Value ir.Value
 }
 
-func (arg *Argument) Invalid(msg string) {
-	arg.invalids = append(arg.invalids, msg)
+func (arg *Argument) Invalid(msg string, opts ...report.Option) {
+	arg.invalids = append(arg.invalids, invalid{msg, opts})
 }
 
 type Check func(call *Call)
@@ -100,22 +107,22 @@ func checkCalls(pass *analysis.Pass, rules map[string]Check) (interface{}, error
 			for _, e := range arg.invalids {
 				if astcall != nil {
 					if idx < len(astcall.Args) {
-						report.Report(pass, astcall.Args[idx], e)
+						report.Report(pass, astcall.Args[idx], e.msg, e.opts...)
 					} else {
 						// this is an instance of fn1(fn2()) where fn2
 						// returns multiple values. Report the error
 						// at the next-best position that we have, the
 						// first argument. An example of a check that
 						// triggers this is checkEncodingBinaryRules.
-						report.Report(pass, astcall.Args[0], e)
+						report.Report(pass, astcall.Args[0], e.msg, e.opts...)
 					}
 				} else {
-					report.Report(pass, site, e)
+					report.Report(pass, site, e.msg, e.opts...)
 				}
 			}
 		}
 		for _, e := range call.invalids {
-			report.Report(pass, call.Instr, e)
+			report.Report(pass, call.Instr, e.msg, e.opts...)
 		}
 	}
 	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
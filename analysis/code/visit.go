@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"go/ast"
 	"go/format"
+	"reflect"
 
 	"honnef.co/go/tools/pattern"
 
@@ -29,11 +30,63 @@ func Match(pass *analysis.Pass, q pattern.Pattern, node ast.Node) (*pattern.Matc
 	// Note that we ignore q.Relevant – callers of Match usually use
 	// AST inspectors that already filter on nodes we're interested
 	// in.
-	m := &pattern.Matcher{TypesInfo: pass.TypesInfo}
+	m := &pattern.Matcher{TypesInfo: pass.TypesInfo, Pkg: pass.Pkg}
 	ok := m.Match(q, node)
 	return m, ok
 }
 
+// A FileFilter reports whether MatchesFiltered should look for
+// matches in a file.
+type FileFilter func(pass *analysis.Pass, f *ast.File) bool
+
+// SkipGenerated is a FileFilter that skips generated files. Analyzers
+// using it must require generated.Analyzer.
+func SkipGenerated(pass *analysis.Pass, f *ast.File) bool {
+	return !IsGenerated(pass, f.Pos())
+}
+
+// Matches calls fn for every node in the package that matches q.
+// Only nodes whose types are in q.Relevant are considered.
+func Matches(pass *analysis.Pass, q pattern.Pattern, fn func(node ast.Node, m *pattern.Matcher)) {
+	MatchesFiltered(pass, q, fn)
+}
+
+// MatchesFiltered is like Matches, but skips files that are rejected
+// by any of the filters. Unlike report.FilterGenerated, which drops
+// diagnostics after the fact, this doesn't traverse skipped files at
+// all.
+func MatchesFiltered(pass *analysis.Pass, q pattern.Pattern, fn func(node ast.Node, m *pattern.Matcher), filters ...FileFilter) {
+	if len(q.Relevant) == 0 {
+		return
+	}
+	types := make([]ast.Node, 0, len(q.Relevant)+1)
+	for typ := range q.Relevant {
+		types = append(types, reflect.Zero(typ).Interface().(ast.Node))
+	}
+	if len(filters) > 0 {
+		types = append(types, (*ast.File)(nil))
+	}
+	pass.ResultOf[inspect.Analyzer].(*inspector.Inspector).Nodes(types, func(node ast.Node, push bool) (proceed bool) {
+		if !push {
+			return true
+		}
+		if f, ok := node.(*ast.File); ok && len(filters) > 0 {
+			for _, filter := range filters {
+				if !filter(pass, f) {
+					return false
+				}
+			}
+			if _, ok := q.Relevant[reflect.TypeOf(node)]; !ok {
+				return true
+			}
+		}
+		if m, ok := Match(pass, q, node); ok {
+			fn(node, m)
+		}
+		return true
+	})
+}
+
 func MatchAndEdit(pass *analysis.Pass, before, after pattern.Pattern, node ast.Node) (*pattern.Matcher, []analysis.TextEdit, bool) {
 	m, ok := Match(pass, before, node)
 	if !ok {
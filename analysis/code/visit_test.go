@@ -0,0 +1,88 @@
+package code
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+var printlnQ = pattern.MustParse(`(CallExpr (Ident "println") _)`)
+
+// newMatchesPass returns a pass over n files, every other one of
+// which is generated, each containing calls calls of println.
+func newMatchesPass(tb testing.TB, n, calls int) *analysis.Pass {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	gen := map[string]generated.Generator{}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("f%d.go", i)
+		src := "package p\n\nfunc f() {\n"
+		if i%2 == 1 {
+			src = "// Code generated by hand. DO NOT EDIT.\n\n" + src
+			gen[name] = generated.Unknown
+		}
+		for j := 0; j < calls; j++ {
+			src += "\tprintln(1)\n"
+		}
+		src += "}\n"
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	return &analysis.Pass{
+		Fset:      fset,
+		Files:     files,
+		TypesInfo: &types.Info{},
+		ResultOf: map[*analysis.Analyzer]interface{}{
+			inspect.Analyzer:   inspector.New(files),
+			generated.Analyzer: gen,
+		},
+	}
+}
+
+func TestMatchesFiltered(t *testing.T) {
+	pass := newMatchesPass(t, 4, 3)
+	count := func(filters ...FileFilter) (n int) {
+		MatchesFiltered(pass, printlnQ, func(node ast.Node, m *pattern.Matcher) {
+			if IsGenerated(pass, node.Pos()) && len(filters) > 0 {
+				t.Errorf("unexpected match in generated file at %s", pass.Fset.Position(node.Pos()))
+			}
+			n++
+		}, filters...)
+		return n
+	}
+	if n := count(); n != 12 {
+		t.Errorf("got %d matches without filters, want 12", n)
+	}
+	if n := count(SkipGenerated); n != 6 {
+		t.Errorf("got %d matches skipping generated files, want 6", n)
+	}
+}
+
+func BenchmarkMatches(b *testing.B) {
+	pass := newMatchesPass(b, 100, 100)
+	b.Run("FilterDiagnostics", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Matches(pass, printlnQ, func(node ast.Node, m *pattern.Matcher) {
+				_ = IsGenerated(pass, node.Pos())
+			})
+		}
+	})
+	b.Run("SkipGenerated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MatchesFiltered(pass, printlnQ, func(node ast.Node, m *pattern.Matcher) {}, SkipGenerated)
+		}
+	})
+}
@@ -3,9 +3,13 @@ package edit
 
 import (
 	"bytes"
+	"cmp"
+	"fmt"
 	"go/ast"
 	"go/format"
 	"go/token"
+	"slices"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"honnef.co/go/tools/pattern"
@@ -58,6 +62,26 @@ func ReplaceWithPattern(fset *token.FileSet, old Ranger, new pattern.Pattern, st
 	}
 }
 
+// InsertBefore inserts text immediately before a range. Callers are
+// responsible for any newlines and indentation text needs.
+func InsertBefore(node Ranger, text string) analysis.TextEdit {
+	return analysis.TextEdit{
+		Pos:     node.Pos(),
+		End:     node.Pos(),
+		NewText: []byte(text),
+	}
+}
+
+// InsertAfter inserts text immediately after a range. Callers are
+// responsible for any newlines and indentation text needs.
+func InsertAfter(node Ranger, text string) analysis.TextEdit {
+	return analysis.TextEdit{
+		Pos:     node.End(),
+		End:     node.End(),
+		NewText: []byte(text),
+	}
+}
+
 // Delete deletes a range of code.
 func Delete(old Ranger) analysis.TextEdit {
 	return analysis.TextEdit{
@@ -74,6 +98,36 @@ func Fix(msg string, edits ...analysis.TextEdit) analysis.SuggestedFix {
 	}
 }
 
+// Combine merges several fixes into one, whose message lists the
+// messages of all fixes. It returns an error if any two edits
+// overlap. Edits that are adjacent, or that insert text at the same
+// position, don't overlap.
+func Combine(fixes ...analysis.SuggestedFix) (analysis.SuggestedFix, error) {
+	var msgs []string
+	var edits []analysis.TextEdit
+	for _, fix := range fixes {
+		if fix.Message != "" {
+			msgs = append(msgs, fix.Message)
+		}
+		edits = append(edits, fix.TextEdits...)
+	}
+	// Sort insertions before replacements that start at the same
+	// position.
+	sorted := slices.Clone(edits)
+	slices.SortStableFunc(sorted, func(a, b analysis.TextEdit) int {
+		return cmp.Or(cmp.Compare(a.Pos, b.Pos), cmp.Compare(a.End, b.End))
+	})
+	for i := 1; i < len(sorted); i++ {
+		if prev, cur := sorted[i-1], sorted[i]; prev.End > cur.Pos {
+			return analysis.SuggestedFix{}, fmt.Errorf("edit of range [%d, %d) overlaps edit of range [%d, %d)", prev.Pos, prev.End, cur.Pos, cur.End)
+		}
+	}
+	return analysis.SuggestedFix{
+		Message:   strings.Join(msgs, "; "),
+		TextEdits: edits,
+	}, nil
+}
+
 // Selector creates a new selector expression.
 func Selector(x, sel string) *ast.SelectorExpr {
 	return &ast.SelectorExpr{
@@ -0,0 +1,126 @@
+package edit
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits [][]analysis.TextEdit
+		ok    bool
+	}{
+		{"adjacent", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 20}, "a")},
+			{ReplaceWithString(Range{20, 30}, "b"), Delete(Range{0, 10})},
+		}, true},
+		{"insertions at the same position", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 10}, "a")},
+			{ReplaceWithString(Range{10, 10}, "b")},
+			{ReplaceWithString(Range{10, 20}, "c")},
+		}, true},
+		{"overlapping", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 20}, "a")},
+			{ReplaceWithString(Range{15, 25}, "b")},
+		}, false},
+		{"overlapping within one fix", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 20}, "a"), Delete(Range{19, 20})},
+		}, false},
+		{"contained", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 30}, "a")},
+			{ReplaceWithString(Range{15, 15}, "b")},
+		}, false},
+		{"identical", [][]analysis.TextEdit{
+			{ReplaceWithString(Range{10, 20}, "a")},
+			{ReplaceWithString(Range{10, 20}, "a")},
+		}, false},
+	}
+	for _, tt := range tests {
+		var fixes []analysis.SuggestedFix
+		var want []analysis.TextEdit
+		for i, edits := range tt.edits {
+			fixes = append(fixes, Fix(string(rune('a'+i)), edits...))
+			want = append(want, edits...)
+		}
+		fix, err := Combine(fixes...)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got error %v, want error: %t", tt.name, err, !tt.ok)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if len(fix.TextEdits) != len(want) {
+			t.Errorf("%s: got %d edits, want %d", tt.name, len(fix.TextEdits), len(want))
+		}
+		for i := range want {
+			if got := fix.TextEdits[i]; got.Pos != want[i].Pos || got.End != want[i].End || string(got.NewText) != string(want[i].NewText) {
+				t.Errorf("%s: edit %d is %v, want %v", tt.name, i, got, want[i])
+			}
+		}
+	}
+
+	fix, err := Combine(Fix("first", Delete(Range{1, 2})), Fix("", Delete(Range{2, 3})), Fix("second", Delete(Range{3, 4})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fix.Message != "first; second" {
+		t.Errorf("got message %q, want %q", fix.Message, "first; second")
+	}
+}
+
+func TestInsert(t *testing.T) {
+	const src = `package p
+
+func f() {
+	g()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := f.Decls[0].(*ast.FuncDecl).Body.List[0]
+	tf := fset.File(f.Pos())
+	apply := func(edit analysis.TextEdit) string {
+		if edit.Pos != edit.End {
+			t.Fatalf("edit replaces [%d, %d), want an insertion", edit.Pos, edit.End)
+		}
+		off := tf.Offset(edit.Pos)
+		return src[:off] + string(edit.NewText) + src[off:]
+	}
+
+	if off := tf.Offset(InsertBefore(call, "x").Pos); off != 23 {
+		t.Errorf("InsertBefore inserts at offset %d, want 23", off)
+	}
+	if off := tf.Offset(InsertAfter(call, "x").Pos); off != 26 {
+		t.Errorf("InsertAfter inserts at offset %d, want 26", off)
+	}
+
+	const before = `package p
+
+func f() {
+	h()
+	g()
+}
+`
+	if got := apply(InsertBefore(call, "h()\n\t")); got != before {
+		t.Errorf("got\n%s\nwant\n%s", got, before)
+	}
+	const after = `package p
+
+func f() {
+	g()
+	return
+}
+`
+	if got := apply(InsertAfter(call, "\n\treturn")); got != after {
+		t.Errorf("got\n%s\nwant\n%s", got, after)
+	}
+}
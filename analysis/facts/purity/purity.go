@@ -226,6 +226,10 @@ func purity(pass *analysis.Pass) (interface{}, error) {
 					return false
 				case *ir.Send:
 					return false
+				case *ir.Recv:
+					// Receiving is observable by the sender, even if the
+					// channel was created locally.
+					return false
 				case *ir.Go:
 					return false
 				case *ir.Panic:
@@ -0,0 +1,31 @@
+package pkg
+
+import "strings"
+
+func callsPure(a, b int) int            { return foo(a, b) * 2 } // want callsPure:"is pure"
+func callsImpure(a, b int) int          { return bar(a, b) * 2 }
+func callsTransitivelyImpure(a int) int { return callsImpure(a, a) }
+
+func recv() int {
+	ch := make(chan int, 1)
+	return <-ch
+}
+
+func send() int {
+	ch := make(chan int, 1)
+	ch <- 1
+	return 0
+}
+
+func store(x int) int      { X = x; return x }
+func callsStore(x int) int { return store(x) + 1 }
+
+func pureLibrary(s string) string { return strings.TrimSpace(s) } // want pureLibrary:"is pure"
+func impureLibrary(s string) int  { return strings.NewReader(s).Len() }
+
+func fact(n int) int { // want fact:"is pure"
+	if n <= 1 {
+		return 1
+	}
+	return n * fact(n-1)
+}
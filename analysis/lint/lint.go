@@ -40,6 +40,25 @@ const (
 	SeverityHint
 )
 
+func (s Severity) String() string {
+	switch s {
+	case SeverityNone:
+		return ""
+	case SeverityError:
+		return "error"
+	case SeverityDeprecated:
+		return "deprecated"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
 // MergeStrategy sets how merge mode should behave for diagnostics of an analyzer.
 type MergeStrategy int
 
@@ -58,6 +77,15 @@ type RawDocumentation struct {
 	Options    []string
 	Severity   Severity
 	MergeIf    MergeStrategy
+	// YieldsTo lists the names of analyzers that take precedence over
+	// this one. Diagnostics of this analyzer are dropped if one of
+	// these analyzers reported a diagnostic with an overlapping
+	// position range.
+	YieldsTo []string
+	// SafeFixes declares that the analyzer's suggested fixes are
+	// purely mechanical and don't change the behavior of the code,
+	// so that they can be applied without review.
+	SafeFixes bool
 }
 
 type Documentation struct {
@@ -74,6 +102,8 @@ type Documentation struct {
 	Options    []string
 	Severity   Severity
 	MergeIf    MergeStrategy
+	YieldsTo   []string
+	SafeFixes  bool
 }
 
 func (doc RawDocumentation) Compile() *Documentation {
@@ -91,6 +121,8 @@ func (doc RawDocumentation) Compile() *Documentation {
 		Options:    doc.Options,
 		Severity:   doc.Severity,
 		MergeIf:    doc.MergeIf,
+		YieldsTo:   doc.YieldsTo,
+		SafeFixes:  doc.SafeFixes,
 	}
 }
 
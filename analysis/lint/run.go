@@ -0,0 +1,226 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"os"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// RunOne loads the package matching pkgPath and runs a on it,
+// returning the diagnostics that a reported, sorted by position.
+// pkgPath may be anything that go/packages accepts as a pattern, such
+// as an import path or a relative directory, as long as it matches
+// exactly one package. Packages are loaded relative to the current
+// working directory.
+//
+// All analyzers that a requires, directly or indirectly, run as well.
+// This includes config.Analyzer, which loads the staticcheck.conf
+// files that apply to the package, just like it does when running
+// staticcheck. If any of the analyzers use facts, the package's
+// dependencies are loaded from source and analyzed first, which is
+// considerably slower.
+//
+// RunOne is meant for tests and for tools that embed individual
+// checks. It does not support caching, ignore directives or any of
+// the other features of staticcheck's own runner.
+func RunOne(a *analysis.Analyzer, pkgPath string) ([]analysis.Diagnostic, error) {
+	if err := analysis.Validate([]*analysis.Analyzer{a}); err != nil {
+		return nil, err
+	}
+
+	// Order the analyzers so that each analyzer comes after the
+	// analyzers it requires.
+	var all []*analysis.Analyzer
+	seen := map[*analysis.Analyzer]bool{}
+	var visit func(a *analysis.Analyzer)
+	visit = func(a *analysis.Analyzer) {
+		if seen[a] {
+			return
+		}
+		seen[a] = true
+		for _, req := range a.Requires {
+			visit(req)
+		}
+		all = append(all, a)
+	}
+	visit(a)
+
+	// Dependencies only need to be analyzed by analyzers that produce
+	// facts, and the analyzers those require.
+	forFacts := map[*analysis.Analyzer]bool{}
+	var markForFacts func(a *analysis.Analyzer)
+	markForFacts = func(a *analysis.Analyzer) {
+		if forFacts[a] {
+			return
+		}
+		forFacts[a] = true
+		for _, req := range a.Requires {
+			markForFacts(req)
+		}
+	}
+	for _, a := range all {
+		if len(a.FactTypes) > 0 {
+			markForFacts(a)
+		}
+	}
+
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+		packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo
+	if len(forFacts) > 0 {
+		mode |= packages.NeedDeps
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: mode}, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("pattern %q matched %d packages, expected exactly one", pkgPath, len(pkgs))
+	}
+	var errs []error
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			errs = append(errs, err)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	r := &oneRunner{
+		objFacts: map[objFactKey]analysis.Fact{},
+		pkgFacts: map[pkgFactKey]analysis.Fact{},
+	}
+	root := pkgs[0]
+	if len(forFacts) > 0 {
+		var order []*packages.Package
+		packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+			if pkg != root {
+				order = append(order, pkg)
+			}
+		})
+		for _, pkg := range order {
+			var analyzers []*analysis.Analyzer
+			for _, a := range all {
+				if forFacts[a] {
+					analyzers = append(analyzers, a)
+				}
+			}
+			if _, err := r.run(pkg, analyzers, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	diags, err := r.run(root, all, a)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Pos < diags[j].Pos
+	})
+	return diags, nil
+}
+
+type objFactKey struct {
+	obj types.Object
+	typ reflect.Type
+}
+
+type pkgFactKey struct {
+	pkg *types.Package
+	typ reflect.Type
+}
+
+// oneRunner holds the facts of all analyzed packages. Because all
+// packages are loaded from source by a single call to packages.Load,
+// they share their types.Objects, and facts can be stored in memory
+// without having to be serialized.
+type oneRunner struct {
+	objFacts map[objFactKey]analysis.Fact
+	pkgFacts map[pkgFactKey]analysis.Fact
+}
+
+// run runs analyzers, which must be sorted topologically, on pkg, and
+// returns the diagnostics reported by report.
+func (r *oneRunner) run(pkg *packages.Package, analyzers []*analysis.Analyzer, report *analysis.Analyzer) ([]analysis.Diagnostic, error) {
+	var diags []analysis.Diagnostic
+	results := map[*analysis.Analyzer]interface{}{}
+	for _, a := range analyzers {
+		resultOf := map[*analysis.Analyzer]interface{}{}
+		for _, req := range a.Requires {
+			resultOf[req] = results[req]
+		}
+		factTypes := map[reflect.Type]bool{}
+		for _, f := range a.FactTypes {
+			factTypes[reflect.TypeOf(f)] = true
+		}
+
+		pass := &analysis.Pass{
+			Analyzer:     a,
+			Fset:         pkg.Fset,
+			Files:        pkg.Syntax,
+			OtherFiles:   pkg.OtherFiles,
+			IgnoredFiles: pkg.IgnoredFiles,
+			Pkg:          pkg.Types,
+			TypesInfo:    pkg.TypesInfo,
+			TypesSizes:   pkg.TypesSizes,
+			ResultOf:     resultOf,
+			ReadFile:     os.ReadFile,
+			Report: func(d analysis.Diagnostic) {
+				if a == report {
+					diags = append(diags, d)
+				}
+			},
+			ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+				f, ok := r.objFacts[objFactKey{obj, reflect.TypeOf(fact)}]
+				if ok {
+					reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(f).Elem())
+				}
+				return ok
+			},
+			ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+				f, ok := r.pkgFacts[pkgFactKey{pkg, reflect.TypeOf(fact)}]
+				if ok {
+					reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(f).Elem())
+				}
+				return ok
+			},
+			ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+				r.objFacts[objFactKey{obj, reflect.TypeOf(fact)}] = fact
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				r.pkgFacts[pkgFactKey{pkg.Types, reflect.TypeOf(fact)}] = fact
+			},
+			AllObjectFacts: func() []analysis.ObjectFact {
+				var out []analysis.ObjectFact
+				for k, f := range r.objFacts {
+					if factTypes[k.typ] {
+						out = append(out, analysis.ObjectFact{Object: k.obj, Fact: f})
+					}
+				}
+				return out
+			},
+			AllPackageFacts: func() []analysis.PackageFact {
+				var out []analysis.PackageFact
+				for k, f := range r.pkgFacts {
+					if factTypes[k.typ] {
+						out = append(out, analysis.PackageFact{Package: k.pkg, Fact: f})
+					}
+				}
+				return out
+			},
+		}
+		res, err := a.Run(pass)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %s failed on package %s: %w", a.Name, pkg.PkgPath, err)
+		}
+		results[a] = res
+	}
+	return diags, nil
+}
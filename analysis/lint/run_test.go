@@ -0,0 +1,55 @@
+package lint_test
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/staticcheck/sa1012"
+	"honnef.co/go/tools/staticcheck/sa1019"
+	"honnef.co/go/tools/stylecheck/st1013"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestRunOne(t *testing.T) {
+	tests := []struct {
+		analyzer *analysis.Analyzer
+		pkg      string
+		want     []string
+	}{
+		{sa1012.Analyzer, "./testdata/nilctx", []string{"do not pass a nil Context, even if a function permits it; pass context.TODO if you are unsure about which Context to use"}},
+		// staticcheck.conf whitelists 404.
+		{st1013.Analyzer, "./testdata/httpcodes", []string{"should use constant http.StatusInternalServerError instead of numeric literal 500"}},
+		// Deprecation is communicated via facts.
+		{sa1019.Analyzer, "./testdata/deprecated", []string{"dep.Old is deprecated: Use New instead. "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.analyzer.Name, func(t *testing.T) {
+			diags, err := lint.RunOne(tt.analyzer, tt.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.Message)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got diagnostics %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got diagnostic %q, want %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunOneErrors(t *testing.T) {
+	if _, err := lint.RunOne(sa1012.Analyzer, "./testdata/deprecated/..."); err == nil {
+		t.Error("expected an error for a pattern matching multiple packages")
+	}
+	if _, err := lint.RunOne(sa1012.Analyzer, "./testdata/doesnotexist"); err == nil {
+		t.Error("expected an error for a nonexistent package")
+	}
+}
@@ -0,0 +1,6 @@
+package dep
+
+// Deprecated: Use New instead.
+func Old() {}
+
+func New() {}
@@ -0,0 +1,8 @@
+package deprecated
+
+import "honnef.co/go/tools/analysis/lint/testdata/deprecated/dep"
+
+func _() {
+	dep.Old()
+	dep.New()
+}
@@ -0,0 +1,8 @@
+package httpcodes
+
+import "net/http"
+
+func _(w http.ResponseWriter) {
+	http.Error(w, "not found", 404)
+	http.Error(w, "internal error", 500)
+}
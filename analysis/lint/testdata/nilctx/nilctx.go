@@ -0,0 +1,10 @@
+package nilctx
+
+import "context"
+
+func fn(ctx context.Context) {}
+
+func _() {
+	fn(nil)
+	fn(context.TODO())
+}
@@ -10,9 +10,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"honnef.co/go/tools/analysis/code"
 	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/go/ast/astutil"
 
 	"golang.org/x/tools/go/analysis"
@@ -27,6 +29,7 @@ type Options struct {
 	MaximumLanguageVersion string
 	MinimumStdlibVersion   string
 	MaximumStdlibVersion   string
+	Severity               lint.Severity
 }
 
 type Option func(*Options)
@@ -77,6 +80,27 @@ func MaximumStdlibVersion(vers string) Option {
 	return func(opts *Options) { opts.MaximumStdlibVersion = vers }
 }
 
+// Severity overrides the severity of the analyzer for a single
+// diagnostic.
+func Severity(severity lint.Severity) Option {
+	return func(opts *Options) { opts.Severity = severity }
+}
+
+// The go/analysis framework has no notion of severities, so drivers
+// that support overridden severities register a function per pass
+// that Report calls instead of Pass.Report.
+var severityHandlers sync.Map // map[*analysis.Pass]func(analysis.Diagnostic, lint.Severity)
+
+// HandleSeverities makes Report call fn instead of pass.Report for
+// diagnostics whose severity has been overridden with the Severity
+// option, until the returned function is called. Other drivers don't
+// see the overrides; they get diagnostics with the analyzer's
+// severity.
+func HandleSeverities(pass *analysis.Pass, fn func(analysis.Diagnostic, lint.Severity)) (unregister func()) {
+	severityHandlers.Store(pass, fn)
+	return func() { severityHandlers.Delete(pass) }
+}
+
 type Positioner interface {
 	Pos() token.Pos
 }
@@ -223,6 +247,12 @@ func Report(pass *analysis.Pass, node Positioner, message string, opts ...Option
 		SuggestedFixes: cfg.Fixes,
 		Related:        cfg.Related,
 	}
+	if cfg.Severity != lint.SeverityNone {
+		if fn, ok := severityHandlers.Load(pass); ok {
+			fn.(func(analysis.Diagnostic, lint.Severity))(d, cfg.Severity)
+			return
+		}
+	}
 	pass.Report(d)
 }
 
@@ -1,6 +1,17 @@
 package report
 
-import "testing"
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"honnef.co/go/tools/analysis/facts/tokenfile"
+	"honnef.co/go/tools/analysis/lint"
+
+	"golang.org/x/tools/go/analysis"
+)
 
 func TestOrdinal(t *testing.T) {
 	tests := []struct {
@@ -17,3 +28,61 @@ func TestOrdinal(t *testing.T) {
 		}
 	}
 }
+
+func TestSeverity(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", "package foo", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{FileVersions: map[*ast.File]string{}}
+	pkg, err := (&types.Config{GoVersion: "go1.22"}).Check("foo", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:  &analysis.Analyzer{Name: "SA0000"},
+		Fset:      fset,
+		Files:     []*ast.File{f},
+		Pkg:       pkg,
+		TypesInfo: info,
+		ResultOf: map[*analysis.Analyzer]interface{}{
+			tokenfile.Analyzer: map[*token.File]*ast.File{fset.File(f.Pos()): f},
+		},
+		Report: func(d analysis.Diagnostic) { diags = append(diags, d) },
+	}
+	Report(pass, f.Name, "default severity")
+	Report(pass, f.Name, "overridden severity", Severity(lint.SeverityError))
+	// Drivers that don't handle severities get diagnostics that look
+	// like any other.
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(diags))
+	}
+	for _, d := range diags {
+		if d.Category != "" {
+			t.Errorf("diagnostic %q has category %q, want none", d.Message, d.Category)
+		}
+	}
+
+	diags = nil
+	var severities []lint.Severity
+	unregister := HandleSeverities(pass, func(d analysis.Diagnostic, severity lint.Severity) {
+		diags = append(diags, d)
+		severities = append(severities, severity)
+	})
+	Report(pass, f.Name, "default severity")
+	Report(pass, f.Name, "overridden severity", Severity(lint.SeverityError))
+	unregister()
+	Report(pass, f.Name, "unregistered", Severity(lint.SeverityError))
+	if len(diags) != 3 || len(severities) != 1 {
+		t.Fatalf("got %d diagnostics and %d severities, want 3 and 1", len(diags), len(severities))
+	}
+	if diags[0].Message != "default severity" || diags[1].Message != "overridden severity" || severities[0] != lint.SeverityError {
+		t.Errorf("got overridden severity %s for %q, want error for %q", severities[0], diags[1].Message, "overridden severity")
+	}
+	if diags[1].Category != "" {
+		t.Errorf("diagnostic with overridden severity has category %q, want none", diags[1].Category)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -119,6 +121,16 @@ func (cfg Config) Merge(ocfg Config) Config {
 	if ocfg.Checks != nil {
 		cfg.Checks = mergeLists(cfg.Checks, ocfg.Checks)
 	}
+	if ocfg.ChecksByPath != nil {
+		m := make(map[string][]string, len(cfg.ChecksByPath)+len(ocfg.ChecksByPath))
+		for glob, checks := range cfg.ChecksByPath {
+			m[glob] = checks
+		}
+		for glob, checks := range ocfg.ChecksByPath {
+			m[glob] = checks
+		}
+		cfg.ChecksByPath = m
+	}
 	if ocfg.Initialisms != nil {
 		cfg.Initialisms = mergeLists(cfg.Initialisms, ocfg.Initialisms)
 	}
@@ -128,6 +140,12 @@ func (cfg Config) Merge(ocfg Config) Config {
 	if ocfg.HTTPStatusCodeWhitelist != nil {
 		cfg.HTTPStatusCodeWhitelist = mergeLists(cfg.HTTPStatusCodeWhitelist, ocfg.HTTPStatusCodeWhitelist)
 	}
+	if ocfg.HTTPStatusCodeMin != 0 {
+		cfg.HTTPStatusCodeMin = ocfg.HTTPStatusCodeMin
+	}
+	if ocfg.MaxCyclomaticComplexity != 0 {
+		cfg.MaxCyclomaticComplexity = ocfg.MaxCyclomaticComplexity
+	}
 	return cfg
 }
 
@@ -143,15 +161,26 @@ type Config struct {
 	Initialisms             []string `toml:"initialisms"`
 	DotImportWhitelist      []string `toml:"dot_import_whitelist"`
 	HTTPStatusCodeWhitelist []string `toml:"http_status_code_whitelist"`
+	HTTPStatusCodeMin       int      `toml:"http_status_code_min"`
+	MaxCyclomaticComplexity int      `toml:"max_cyclomatic_complexity"`
+
+	// ChecksByPath maps globs to lists of checks that are merged with
+	// Checks for packages whose directories match the globs. Globs
+	// are relative to the directory of the configuration file that
+	// contains them; after loading, they are absolute.
+	ChecksByPath map[string][]string `toml:"checks_by_path"`
 }
 
 func (c Config) String() string {
 	buf := &bytes.Buffer{}
 
 	fmt.Fprintf(buf, "Checks: %#v\n", c.Checks)
+	fmt.Fprintf(buf, "ChecksByPath: %#v\n", c.ChecksByPath)
 	fmt.Fprintf(buf, "Initialisms: %#v\n", c.Initialisms)
 	fmt.Fprintf(buf, "DotImportWhitelist: %#v\n", c.DotImportWhitelist)
-	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v\n", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "HTTPStatusCodeMin: %d\n", c.HTTPStatusCodeMin)
+	fmt.Fprintf(buf, "MaxCyclomaticComplexity: %d", c.MaxCyclomaticComplexity)
 
 	return buf.String()
 }
@@ -179,6 +208,8 @@ var DefaultConfig = Config{
 		"github.com/mmcloughlin/avo/reg",
 	},
 	HTTPStatusCodeWhitelist: []string{"200", "400", "404", "500"},
+	HTTPStatusCodeMin:       0,
+	MaxCyclomaticComplexity: 30,
 }
 
 const ConfigName = "staticcheck.conf"
@@ -217,6 +248,9 @@ func parseConfigs(dir string) ([]Config, error) {
 			}
 			return nil, err
 		}
+		if err := resolveGlobs(&cfg, dir); err != nil {
+			return nil, fmt.Errorf("%s: %s", filepath.Join(dir, ConfigName), err)
+		}
 		out = append(out, cfg)
 		ndir := filepath.Dir(dir)
 		if ndir == dir {
@@ -234,18 +268,116 @@ func parseConfigs(dir string) ([]Config, error) {
 	return out, nil
 }
 
-func mergeConfigs(confs []Config) Config {
+// resolveGlobs makes the globs in cfg.ChecksByPath, which are relative
+// to dir, absolute and validates them.
+func resolveGlobs(cfg *Config, dir string) error {
+	if cfg.ChecksByPath == nil {
+		return nil
+	}
+	m := make(map[string][]string, len(cfg.ChecksByPath))
+	for glob, checks := range cfg.ChecksByPath {
+		if path.IsAbs(glob) {
+			return fmt.Errorf("glob %q in checks_by_path must be relative", glob)
+		}
+		for _, seg := range strings.Split(glob, "/") {
+			if _, err := path.Match(seg, ""); err != nil {
+				return fmt.Errorf("invalid glob %q in checks_by_path: %s", glob, err)
+			}
+		}
+		m[path.Join(filepath.ToSlash(dir), glob)] = checks
+	}
+	cfg.ChecksByPath = m
+	return nil
+}
+
+// matchGlob reports whether the slash-separated path name matches the
+// glob. In addition to the syntax of path.Match, a segment consisting
+// of "**" matches zero or more segments.
+func matchGlob(glob, name string) bool {
+	var match func(globs, names []string) bool
+	match = func(globs, names []string) bool {
+		for len(globs) > 0 {
+			if globs[0] == "**" {
+				for i := 0; i <= len(names); i++ {
+					if match(globs[1:], names[i:]) {
+						return true
+					}
+				}
+				return false
+			}
+			if len(names) == 0 {
+				return false
+			}
+			if ok, _ := path.Match(globs[0], names[0]); !ok {
+				return false
+			}
+			globs, names = globs[1:], names[1:]
+		}
+		return len(names) == 0
+	}
+	return match(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+// specificity returns the number of segments of glob that don't
+// contain wildcards and the number of "**" segments.
+func specificity(glob string) (literal, globstars int) {
+	for _, seg := range strings.Split(glob, "/") {
+		if seg == "**" {
+			globstars++
+		} else if !strings.ContainsAny(seg, `*?[\`) {
+			literal++
+		}
+	}
+	return literal, globstars
+}
+
+// checksForDir merges the lists in byPath whose globs match dir with
+// checks. Lists are merged in order of increasing specificity, so that
+// the most specific glob wins. A glob is more specific than another if
+// it has more segments without wildcards or, failing that, fewer "**"
+// segments. Globs that are equally specific are merged in lexical
+// order.
+func checksForDir(checks []string, byPath map[string][]string, dir string) []string {
+	var globs []string
+	for glob := range byPath {
+		if matchGlob(glob, dir) {
+			globs = append(globs, glob)
+		}
+	}
+	sort.Slice(globs, func(i, j int) bool {
+		li, si := specificity(globs[i])
+		lj, sj := specificity(globs[j])
+		if li != lj {
+			return li < lj
+		}
+		if si != sj {
+			return si > sj
+		}
+		return globs[i] < globs[j]
+	})
+	for _, glob := range globs {
+		checks = mergeLists(checks, byPath[glob])
+	}
+	return checks
+}
+
+// mergeConfigs merges confs, which are ordered from the least to the
+// most specific configuration. The checks_by_path entries of each
+// configuration are applied for the package in dir right after the
+// configuration's checks, so that configuration files further down
+// the tree still override them.
+func mergeConfigs(confs []Config, dir string) Config {
 	if len(confs) == 0 {
 		// This shouldn't happen because we always have at least a
 		// default config.
 		panic("trying to merge zero configs")
 	}
-	if len(confs) == 1 {
-		return confs[0]
-	}
+	dir = filepath.ToSlash(dir)
 	conf := confs[0]
+	conf.Checks = checksForDir(conf.Checks, conf.ChecksByPath, dir)
 	for _, oconf := range confs[1:] {
 		conf = conf.Merge(oconf)
+		conf.Checks = checksForDir(conf.Checks, oconf.ChecksByPath, dir)
 	}
 	return conf
 }
@@ -255,7 +387,7 @@ func Load(dir string) (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
-	conf := mergeConfigs(confs)
+	conf := mergeConfigs(confs, dir)
 
 	conf.Checks = normalizeList(conf.Checks)
 	conf.Initialisms = normalizeList(conf.Initialisms)
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	for _, tt := range []struct {
+		glob, name string
+		want       bool
+	}{
+		{"/a/legacy/**", "/a/legacy", true},
+		{"/a/legacy/**", "/a/legacy/b/c", true},
+		{"/a/legacy/**", "/a/legacyx", false},
+		{"/a/legacy/*", "/a/legacy", false},
+		{"/a/legacy/*", "/a/legacy/b", true},
+		{"/a/legacy/*", "/a/legacy/b/c", false},
+		{"/a/**/gen", "/a/x/y/gen", true},
+		{"/a/**/gen", "/a/gen", true},
+		{"/a/**/gen", "/a/gen/x", false},
+		{"/a/*_old", "/a/foo_old", true},
+	} {
+		if got := matchGlob(tt.glob, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %t, want %t", tt.glob, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChecksByPath(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, data string) {
+		t.Helper()
+		dir = filepath.Join(root, dir)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".", `
+checks = ["all"]
+
+[checks_by_path]
+"legacy/**" = ["inherit", "-SA1019", "-ST1000"]
+"legacy/*" = ["inherit", "-S1000"]
+"legacy/keep/**" = ["inherit", "ST1000"]
+"**/gen" = ["inherit", "-SA4006"]
+`)
+	write("legacy/override", `
+checks = ["inherit", "SA1019"]
+`)
+
+	for _, tt := range []struct {
+		dir  string
+		want []string
+	}{
+		{".", []string{"all"}},
+		{"other", []string{"all"}},
+		{"legacy", []string{"all", "-SA1019", "-ST1000"}},
+		{"legacy/x/y", []string{"all", "-SA1019", "-ST1000"}},
+		// legacy/* is more specific than legacy/**
+		{"legacy/x", []string{"all", "-SA1019", "-ST1000", "-S1000"}},
+		// legacy/keep/** is more specific than legacy/*
+		{"legacy/keep", []string{"all", "-SA1019", "-ST1000", "-S1000", "ST1000"}},
+		{"legacy/keep/gen", []string{"all", "-SA4006", "-SA1019", "-ST1000", "ST1000"}},
+		// Configuration files further down the tree override
+		// checks_by_path of files further up.
+		{"legacy/override", []string{"all", "-SA1019", "-ST1000", "-S1000", "SA1019"}},
+	} {
+		cfg, err := Load(filepath.Join(root, tt.dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cfg.Checks, tt.want) {
+			t.Errorf("checks for %s: got %q, want %q", tt.dir, cfg.Checks, tt.want)
+		}
+	}
+}
+
+func TestChecksByPathInvalidGlob(t *testing.T) {
+	root := t.TempDir()
+	data := "[checks_by_path]\n\"legacy/[\" = [\"inherit\"]\n"
+	if err := os.WriteFile(filepath.Join(root, ConfigName), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root); err == nil {
+		t.Fatal("expected error for invalid glob")
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"go/types"
 	"go/version"
 	"os"
+	"strings"
 
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/go/types/typeutil"
@@ -84,7 +85,7 @@ func jDone() *Const {
 type builder struct {
 	printFunc string
 
-	blocksets [5]BlockSet
+	blocksets [6]BlockSet
 }
 
 // cond emits to fn code to evaluate boolean condition e and jump
@@ -222,6 +223,38 @@ func (b *builder) exprN(fn *Function, e ast.Expr) Value {
 	panic(fmt.Sprintf("exprN(%T) in %s", e, fn))
 }
 
+// atomicOp returns the operation performed by the call c if it
+// calls one of the package-level functions of sync/atomic, and zero
+// otherwise.
+func atomicOp(c *CallCommon) AtomicOp {
+	callee := c.StaticCallee()
+	if callee == nil || callee.Signature.Recv() != nil {
+		return 0
+	}
+	obj := callee.Object()
+	if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != "sync/atomic" {
+		return 0
+	}
+	for _, prefix := range [...]struct {
+		name string
+		op   AtomicOp
+	}{
+		{"Load", AtomicLoad},
+		{"Store", AtomicStore},
+		{"Add", AtomicAdd},
+		{"Swap", AtomicSwap},
+		{"CompareAndSwap", AtomicCompareAndSwap},
+		{"And", AtomicAnd},
+		{"Or", AtomicOr},
+	} {
+		switch strings.TrimPrefix(obj.Name(), prefix.name) {
+		case "Int32", "Int64", "Uint32", "Uint64", "Uintptr", "Pointer":
+			return prefix.op
+		}
+	}
+	return 0
+}
+
 // builtin emits to fn IR instructions to implement a call to the
 // built-in function obj with the specified arguments
 // and return type.  It returns the value defined by the result.
@@ -656,6 +689,17 @@ func (b *builder) expr0(fn *Function, e ast.Expr, tv types.TypeAndValue) Value {
 		// Regular function call.
 		var v Call
 		b.setCall(fn, e, &v.Call)
+		if fn.Prog.mode&AtomicOperations != 0 {
+			if op := atomicOp(&v.Call); op != 0 {
+				a := &Atomic{
+					Op:   op,
+					Addr: v.Call.Args[0],
+					Args: v.Call.Args[1:],
+				}
+				a.setType(tv.Type)
+				return fn.emit(a, e)
+			}
+		}
 		v.setType(tv.Type)
 		return fn.emit(&v, e)
 
@@ -2467,7 +2511,12 @@ func (b *builder) rangeStmt(fn *Function, s *ast.RangeStmt, label *lblock, sourc
 
 	afterGo122 := version.Compare(fn.goversion, "go1.22") >= 0
 
-	if s.Tok == token.DEFINE && !afterGo122 {
+	// The iteration variables of range-over-func loops are local to
+	// the yield function holding the loop body, which creates them
+	// itself.
+	_, rangeOverFunc := typeutil.CoreType(fn.Pkg.typeOf(s.X)).Underlying().(*types.Signature)
+
+	if s.Tok == token.DEFINE && !afterGo122 && !rangeOverFunc {
 		// pre-go1.22: If iteration variables are defined (:=), this
 		// occurs once outside the loop.
 		createVars()
@@ -3434,6 +3483,8 @@ func (p *Package) build() {
 	emitJump(init, done, nil)
 	init.finishBody()
 
+	markRecursive(p)
+
 	// We no longer need ASTs or go/types deductions.
 	p.info = nil
 	p.initVersion = nil
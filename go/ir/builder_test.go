@@ -15,6 +15,7 @@ import (
 	"go/types"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
 	"testing"
 
@@ -669,3 +670,72 @@ func TestUnreachableExit(t *testing.T) {
 		pkg.Build()
 	}
 }
+
+// atomicInput uses sync/atomic's functions and methods. It is shared
+// by the tests of the AtomicOperations mode and of parsing Atomic
+// instructions.
+const atomicInput = `
+package p
+
+import "sync/atomic"
+
+func f(p *int64, q *uint32) int64 {
+	atomic.StoreInt64(p, 1)
+	n := atomic.AddInt64(p, 2)
+	if atomic.CompareAndSwapUint32(q, 0, 1) {
+		return atomic.LoadInt64(p)
+	}
+	var v atomic.Int64
+	v.Add(1)
+	return n
+}
+`
+
+func TestAtomicOperations(t *testing.T) {
+	ops := func(fn *ir.Function) []string {
+		var out []string
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ir.Atomic:
+					out = append(out, instr.Op.String())
+				case *ir.Call:
+					if callee := instr.Call.StaticCallee(); callee != nil {
+						out = append(out, "Call "+callee.Name())
+					}
+				}
+			}
+		}
+		return out
+	}
+
+	fn := buildPackageWithMode(t, atomicInput, ir.AtomicOperations|ir.SanityCheckFunctions).Func("f")
+	want := []string{"Store", "Add", "CompareAndSwap", "Load", "Call Add"}
+	if got := ops(fn); !slices.Equal(got, want) {
+		t.Errorf("got operations %q, want %q", got, want)
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			a, ok := instr.(*ir.Atomic)
+			if !ok {
+				continue
+			}
+			if _, ok := a.Addr.Type().(*types.Pointer); !ok {
+				t.Errorf("%s: address has type %s, want a pointer", a, a.Addr.Type())
+			}
+			if want := map[ir.AtomicOp]int{ir.AtomicLoad: 0, ir.AtomicStore: 1, ir.AtomicAdd: 1, ir.AtomicCompareAndSwap: 2}[a.Op]; len(a.Args) != want {
+				t.Errorf("%s: got %d arguments, want %d", a, len(a.Args), want)
+			}
+			if !slices.Contains(*a.Addr.Referrers(), ir.Instruction(a)) {
+				t.Errorf("%s is missing from the referrers of its address", a)
+			}
+		}
+	}
+
+	// Without the mode, calls to sync/atomic are ordinary calls.
+	fn = buildPackage(t, atomicInput).Func("f")
+	want = []string{"Call StoreInt64", "Call AddInt64", "Call CompareAndSwapUint32", "Call LoadInt64", "Call Add"}
+	if got := ops(fn); !slices.Equal(got, want) {
+		t.Errorf("got operations %q, want %q", got, want)
+	}
+}
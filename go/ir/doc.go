@@ -50,6 +50,7 @@
 //
 //	                   Value?          Instruction?    Member?
 //	*Alloc                ✔               ✔
+//	*Atomic               ✔               ✔
 //	*BinOp                ✔               ✔
 //	*BlankStore                           ✔
 //	*Builtin              ✔
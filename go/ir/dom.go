@@ -18,18 +18,29 @@ package ir
 // to avoid the need for buckets of size > 1.
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
 	"sort"
+	"strings"
+	"sync"
 )
 
 // Idom returns the block that immediately dominates b:
 // its parent in the dominator tree, if any.
 // The entry node (b.Index==0) does not have a parent.
-func (b *BasicBlock) Idom() *BasicBlock { return b.dom.idom }
+//
+// The dominator tree is built as part of building a function. Idom,
+// Dominees, Dominates and Function.DomPreorder return meaningless
+// results for functions that are still being built.
+func (b *BasicBlock) Idom() *BasicBlock {
+	if b.dom.idom == b {
+		// Internally, the entry block is its own immediate dominator.
+		return nil
+	}
+	return b.dom.idom
+}
 
 // Dominees returns the list of blocks that b immediately dominates:
 // its children in the dominator tree.
@@ -40,6 +51,26 @@ func (b *BasicBlock) Dominates(c *BasicBlock) bool {
 	return b.dom.pre <= c.dom.pre && c.dom.post <= b.dom.post
 }
 
+// InstructionDominates reports whether instruction a dominates
+// instruction b. Like BasicBlock.Dominates, dominance is reflexive:
+// every instruction dominates itself. Instructions in different
+// functions never dominate each other.
+//
+// Within a block, instructions are ordered by their IDs. IDs are only
+// assigned once a function has been fully built, and calling
+// InstructionDominates on the instructions of a function that is still
+// being built yields meaningless results.
+func InstructionDominates(a, b Instruction) bool {
+	ab, bb := a.Block(), b.Block()
+	if ab == nil || bb == nil || ab.parent != bb.parent {
+		return false
+	}
+	if ab != bb {
+		return ab.Dominates(bb)
+	}
+	return a.ID() <= b.ID()
+}
+
 type byDomPreorder []*BasicBlock
 
 func (a byDomPreorder) Len() int           { return len(a) }
@@ -73,6 +104,10 @@ func buildDomTree(fn *Function) {
 	for _, b := range fn.Blocks {
 		b.dom = domInfo{}
 	}
+	fn.domFrontierOnce = sync.Once{}
+	fn.domFrontier = nil
+	fn.reachabilityOnce = sync.Once{}
+	fn.reachability = nil
 
 	idoms := make([]*BasicBlock, len(fn.Blocks))
 
@@ -176,6 +211,8 @@ func buildPostDomTree(fn *Function) {
 	for _, b := range fn.Blocks {
 		b.pdom = domInfo{}
 	}
+	fn.postDomFrontierOnce = sync.Once{}
+	fn.postDomFrontier = nil
 
 	idoms := make([]*BasicBlock, len(fn.Blocks))
 
@@ -387,11 +424,36 @@ func sanityCheckDomTree(f *Function) {
 
 // Printing functions ----------------------------------------
 
+// WriteDomTree writes the dominator tree of f to w, as an outline
+// rooted at the entry block in which each block is indented below its
+// immediate dominator.
+func WriteDomTree(w io.Writer, f *Function) {
+	if len(f.Blocks) == 0 {
+		return
+	}
+	printDomTreeText(w, f.Blocks[0], 0)
+}
+
+// WritePostDomTree is like WriteDomTree, but writes the post-dominator
+// tree, which is rooted at the exit block.
+func WritePostDomTree(w io.Writer, f *Function) {
+	if f.Exit == nil {
+		return
+	}
+	printPostDomTreeText(w, f.Exit, 0)
+}
+
+func printDomTreeBlock(w io.Writer, b *BasicBlock, indent int) {
+	fmt.Fprintf(w, "%*sb%d", 4*indent, "", b.Index)
+	if b.Comment != "" {
+		fmt.Fprintf(w, " # %s", b.Comment)
+	}
+	fmt.Fprintln(w)
+}
+
 // printDomTree prints the dominator tree as text, using indentation.
-//
-//lint:ignore U1000 used during debugging
-func printDomTreeText(buf *bytes.Buffer, v *BasicBlock, indent int) {
-	fmt.Fprintf(buf, "%*s%s\n", 4*indent, "", v)
+func printDomTreeText(buf io.Writer, v *BasicBlock, indent int) {
+	printDomTreeBlock(buf, v, indent)
 	for _, child := range v.dom.children {
 		printDomTreeText(buf, child, indent+1)
 	}
@@ -426,11 +488,10 @@ func printDomTreeDot(buf io.Writer, f *Function) {
 	fmt.Fprintln(buf, "}")
 }
 
-// printDomTree prints the dominator tree as text, using indentation.
-//
-//lint:ignore U1000 used during debugging
+// printPostDomTree prints the post-dominator tree as text, using
+// indentation.
 func printPostDomTreeText(buf io.Writer, v *BasicBlock, indent int) {
-	fmt.Fprintf(buf, "%*s%s\n", 4*indent, "", v)
+	printDomTreeBlock(buf, v, indent)
 	for _, child := range v.pdom.children {
 		printPostDomTreeText(buf, child, indent+1)
 	}
@@ -464,3 +525,92 @@ func printPostDomTreeDot(buf io.Writer, f *Function) {
 	}
 	fmt.Fprintln(buf, "}")
 }
+
+// WriteDOT writes the control-flow graph of f to w in Graphviz DOT
+// format. Each block is a node labelled with its instructions,
+// including φ- and σ-nodes, and each edge leads from a block to one
+// of its successors, labelled with the successor's position. The
+// entry block is drawn bold and the exit block with a double outline.
+// Fake edges from infinite loops to the exit block are drawn as red
+// dotted lines.
+func WriteDOT(w io.Writer, f *Function) {
+	writeDOT(w, f, false)
+}
+
+// WriteDOTFrontier is like WriteDOT, but also draws a dashed blue
+// edge from each block to each block in its dominance frontier.
+func WriteDOTFrontier(w io.Writer, f *Function) {
+	writeDOT(w, f, true)
+}
+
+func writeDOT(w io.Writer, f *Function, frontier bool) {
+	fmt.Fprintf(w, "digraph \"%s\" {\n", dotEscape(f.String()))
+	fmt.Fprintln(w, "\tnode [shape=box,fontname=\"monospace\"];")
+	for _, b := range f.Blocks {
+		// Every line of the label is terminated by \l, which
+		// left-justifies it.
+		var label strings.Builder
+		fmt.Fprintf(&label, "b%d:", b.Index)
+		if b.Comment != "" {
+			fmt.Fprintf(&label, " # %s", dotEscape(b.Comment))
+		}
+		label.WriteString(`\l`)
+		for _, instr := range b.Instrs {
+			label.WriteString(dotEscape(dotInstr(instr)))
+			label.WriteString(`\l`)
+		}
+		attrs := ""
+		if b.Index == 0 {
+			attrs += ",style=bold"
+		}
+		if b == f.Exit {
+			attrs += ",peripheries=2"
+		}
+		fmt.Fprintf(w, "\tb%d [label=\"%s\"%s];\n", b.Index, label.String(), attrs)
+	}
+	for _, b := range f.Blocks {
+		for i, succ := range b.Succs {
+			fmt.Fprintf(w, "\tb%d -> b%d [label=\"%d\"];\n", b.Index, succ.Index, i)
+		}
+		if f.Exit != nil && f.fakeExits.Has(b) {
+			fmt.Fprintf(w, "\tb%d -> b%d [style=dotted,color=red];\n", b.Index, f.Exit.Index)
+		}
+	}
+	if frontier && len(f.Blocks) > 0 {
+		df := dedupFrontier(BlockMap[[]*BasicBlock](buildDomFrontier(f)))
+		for _, b := range f.Blocks {
+			for _, v := range df[b.Index] {
+				fmt.Fprintf(w, "\tb%d -> b%d [style=dashed,color=blue,constraint=false];\n", b.Index, v.Index)
+			}
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// dotInstr returns the textual representation of instr, in the same
+// format as WriteFunction.
+func dotInstr(instr Instruction) string {
+	var s string
+	switch v := instr.(type) {
+	case Value:
+		s = instr.String()
+		if name := v.Name(); name != "" {
+			s = name + " = " + s
+		}
+	case nil:
+		return "<deleted>"
+	default:
+		s = instr.String()
+	}
+	if instr.Comment() != "" {
+		s += " # " + instr.Comment()
+	}
+	return s
+}
+
+// dotEscape escapes s for use in a quoted DOT string.
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\l`)
+}
@@ -0,0 +1,387 @@
+//lint:file-ignore SA1019 go/ssa's test suite is built around the deprecated go/loader. We'll leave fixing that to upstream.
+
+package ir_test
+
+import (
+	"bytes"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// buildPackage builds the package in src.
+func buildPackage(t *testing.T, src string) *ir.Package {
+	t.Helper()
+	return buildPackageWithMode(t, src, 0)
+}
+
+// buildPackageWithMode is like buildPackage but uses the given
+// builder mode.
+func buildPackageWithMode(t *testing.T, src string, mode ir.BuilderMode) *ir.Package {
+	t.Helper()
+	conf := loader.Config{Fset: token.NewFileSet()}
+	f, err := parser.ParseFile(conf.Fset, "<input>", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	conf.CreateFromFiles("p", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+	prog := irutil.CreateProgram(lprog, mode)
+	pkg := prog.Package(lprog.Created[0].Pkg)
+	pkg.Build()
+	return pkg
+}
+
+// buildFunction builds the package in src and returns the function
+// with the given name.
+func buildFunction(t *testing.T, src, name string) *ir.Function {
+	t.Helper()
+	fn := buildPackage(t, src).Func(name)
+	if fn == nil {
+		t.Fatalf("couldn't find function %s", name)
+	}
+	return fn
+}
+
+// markers returns the calls to the function mark in fn, indexed by
+// their constant argument.
+func markers(fn *ir.Function) map[int64]*ir.Call {
+	out := map[int64]*ir.Call{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(*ir.Call)
+			if !ok {
+				continue
+			}
+			callee := call.Call.StaticCallee()
+			if callee == nil || callee.Name() != "mark" {
+				continue
+			}
+			k := call.Call.Args[0].(*ir.Const)
+			n, _ := constant.Int64Val(k.Value)
+			out[n] = call
+		}
+	}
+	return out
+}
+
+func TestInstructionDominates(t *testing.T) {
+	const input = `
+package p
+
+func mark(int)
+
+func f(b bool) {
+	mark(1)
+	mark(2)
+	if b {
+		mark(3)
+	} else {
+		mark(4)
+	}
+	mark(5)
+}
+`
+	fn := buildFunction(t, input, "f")
+	m := markers(fn)
+	if len(m) != 5 {
+		t.Fatalf("found %d markers, expected 5", len(m))
+	}
+	if m[1].Block() != m[2].Block() {
+		t.Fatalf("expected mark(1) and mark(2) to be in the same block")
+	}
+
+	tests := []struct {
+		a, b int64
+		want bool
+	}{
+		// same instruction
+		{1, 1, true},
+		// same block
+		{1, 2, true},
+		{2, 1, false},
+		// across blocks
+		{1, 3, true},
+		{2, 5, true},
+		{3, 5, false},
+		{4, 5, false},
+		{3, 4, false},
+		{5, 1, false},
+	}
+	for _, tt := range tests {
+		if got := ir.InstructionDominates(m[tt.a], m[tt.b]); got != tt.want {
+			t.Errorf("InstructionDominates(mark(%d), mark(%d)) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDomTree(t *testing.T) {
+	const input = `
+package p
+
+func mark(int)
+
+func f(b bool, n int) {
+	mark(1)
+	if b {
+		mark(2)
+	} else {
+		mark(3)
+	}
+	for i := 0; i < n; i++ {
+		mark(4)
+	}
+	mark(5)
+}
+`
+	fn := buildFunction(t, input, "f")
+	m := markers(fn)
+	entry := fn.Blocks[0]
+	if entry.Idom() != nil {
+		t.Errorf("entry block has immediate dominator %s", entry.Idom())
+	}
+	for _, n := range []int64{2, 3} {
+		if idom := m[n].Block().Idom(); idom != entry {
+			t.Errorf("immediate dominator of mark(%d) is %s, want %s", n, idom, entry)
+		}
+	}
+
+	for _, b := range fn.Blocks[1:] {
+		idom := b.Idom()
+		if idom == nil {
+			t.Errorf("%s has no immediate dominator", b)
+			continue
+		}
+		if !slices.Contains(idom.Dominees(), b) {
+			t.Errorf("%s isn't among the dominees of its immediate dominator %s", b, idom)
+		}
+		if !idom.Dominates(b) {
+			t.Errorf("immediate dominator %s doesn't dominate %s", idom, b)
+		}
+	}
+
+	order := fn.DomPreorder()
+	if len(order) != len(fn.Blocks) || order[0] != entry {
+		t.Fatalf("got preorder %v", order)
+	}
+	seen := map[*ir.BasicBlock]bool{}
+	for _, b := range order {
+		if idom := b.Idom(); idom != nil && !seen[idom] {
+			t.Errorf("%s comes before its immediate dominator %s in preorder", b, idom)
+		}
+		seen[b] = true
+	}
+}
+
+func TestDominanceFrontier(t *testing.T) {
+	const input = `
+package p
+
+func mark(int)
+
+func f(b bool, n int) {
+	mark(1)
+	if b {
+		mark(2)
+	} else {
+		mark(3)
+	}
+	for i := 0; i < n; i++ {
+		mark(4)
+	}
+	mark(5)
+}
+`
+	fn := buildFunction(t, input, "f")
+	m := markers(fn)
+	if len(m) != 5 {
+		t.Fatalf("found %d markers, expected 5", len(m))
+	}
+	// The block of mark(4) is the body of the loop, which jumps back
+	// to the loop header.
+	header := m[4].Block().Succs[0]
+	join := header.Preds[0]
+
+	frontier := func(df ir.BlockMap[[]*ir.BasicBlock], n int64) []*ir.BasicBlock {
+		return df[m[n].Block().Index]
+	}
+	eq := func(a, b []*ir.BasicBlock) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	df := fn.DominanceFrontier()
+	tests := []struct {
+		n    int64
+		want []*ir.BasicBlock
+	}{
+		{1, nil},
+		{2, []*ir.BasicBlock{join}},
+		{3, []*ir.BasicBlock{join}},
+		{4, []*ir.BasicBlock{header}},
+		{5, nil},
+	}
+	for _, tt := range tests {
+		if got := frontier(df, tt.n); !eq(got, tt.want) {
+			t.Errorf("dominance frontier of mark(%d)'s block = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	// The branches of the if statement depend on mark(1)'s block, the
+	// loop body depends on the loop header.
+	pdf := fn.PostDominanceFrontier()
+	ptests := []struct {
+		n    int64
+		want []*ir.BasicBlock
+	}{
+		{1, nil},
+		{2, []*ir.BasicBlock{m[1].Block()}},
+		{3, []*ir.BasicBlock{m[1].Block()}},
+		{4, []*ir.BasicBlock{header}},
+		{5, nil},
+	}
+	for _, tt := range ptests {
+		if got := frontier(pdf, tt.n); !eq(got, tt.want) {
+			t.Errorf("post-dominance frontier of mark(%d)'s block = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	if df2 := fn.DominanceFrontier(); &df2[0] != &df[0] {
+		t.Errorf("DominanceFrontier wasn't cached")
+	}
+}
+
+func TestReachability(t *testing.T) {
+	const input = `
+package p
+
+func mark(int)
+
+func f(b bool, n int) {
+	mark(1)
+	if b {
+		mark(2)
+		return
+	}
+	for i := 0; i < n; i++ {
+		mark(3)
+	}
+	mark(4)
+	for {
+		mark(5)
+	}
+}
+`
+	fn := buildFunction(t, input, "f")
+	m := markers(fn)
+	if len(m) != 5 {
+		t.Fatalf("found %d markers, expected 5", len(m))
+	}
+
+	tests := []struct {
+		a, b int64
+		want bool
+	}{
+		{1, 1, true},
+		{1, 2, true},
+		{1, 5, true},
+		{2, 3, false},
+		{3, 3, true},
+		{3, 4, true},
+		{4, 3, false},
+		{5, 4, false},
+		{5, 5, true},
+	}
+	r := fn.Reachability()
+	for _, tt := range tests {
+		if got := r.Reaches(m[tt.a].Block(), m[tt.b].Block()); got != tt.want {
+			t.Errorf("Reaches(mark(%d), mark(%d)) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for _, b := range fn.Blocks {
+		if !r.Reaches(b, fn.Exit) {
+			t.Errorf("%s doesn't reach the exit block", b)
+		}
+	}
+	if fn.Reachability() != r {
+		t.Errorf("reachability isn't cached")
+	}
+}
+
+func TestWriteDomTree(t *testing.T) {
+	const input = `
+package p
+
+func f(b bool) int {
+	x := 0
+	if b {
+		x = 1
+	} else {
+		x = 2
+	}
+	return x
+}
+`
+	fn := buildFunction(t, input, "f")
+	var buf bytes.Buffer
+	buf.WriteString("dominator tree:\n")
+	ir.WriteDomTree(&buf, fn)
+	buf.WriteString("\npost-dominator tree:\n")
+	ir.WritePostDomTree(&buf, fn)
+
+	want, err := os.ReadFile(filepath.Join("testdata", "domtree.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	const input = `
+package p
+
+func f(n int) int {
+	x := 0
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			x += i
+		}
+	}
+	return x
+}
+`
+	fn := buildFunction(t, input, "f")
+	var buf bytes.Buffer
+	ir.WriteDOT(&buf, fn)
+	buf.WriteString("\n")
+	ir.WriteDOTFrontier(&buf, fn)
+
+	want, err := os.ReadFile(filepath.Join("testdata", "dot.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
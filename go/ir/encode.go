@@ -0,0 +1,1296 @@
+package ir
+
+// This file implements the serialization of a package's functions,
+// which allows caching the IR of packages that haven't changed.
+
+import (
+	"encoding/gob"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// encodingVersion is incremented whenever the encoding changes in
+// incompatible ways.
+const encodingVersion = 1
+
+// The following types make up the encoding of a package. Values,
+// functions and types are referred to by their indices in the
+// respective lists.
+
+type encPackage struct {
+	Version int
+	Path    string
+	Types   []encType
+	// Funcs contains the package's functions, each followed by its
+	// anonymous functions, in pre-order.
+	Funcs []encFunction
+}
+
+type typeTag uint8
+
+const (
+	tagBasic typeTag = iota
+	tagNamed
+	tagTypeParam
+	tagPointer
+	tagSlice
+	tagArray
+	tagMap
+	tagChan
+	tagSignature
+	tagTuple
+	tagStruct
+	tagInterface
+	tagIterator
+	tagDeferStack
+)
+
+// encType describes a type. Types only refer to types that precede
+// them in encPackage.Types.
+type encType struct {
+	Tag       typeTag
+	Kind      types.BasicKind // basic types
+	Pkg, Name string          // basic and named types; Pkg is empty for predeclared types
+	TypeArgs  []int           // instantiated named types
+	Elem, Key int             // pointers, slices, arrays, maps, channels and iterators
+	Len       int64           // arrays
+	Dir       types.ChanDir   // channels
+	Params    int             // signatures
+	Results   int             // signatures
+	Variadic  bool            // signatures
+	Vars      []encVar        // tuple elements, struct fields and interface methods
+	Tags      []string        // struct tags
+	Embeddeds []int           // interfaces
+	Owner     int             // type parameters: the function declaring them
+	Index     int             // type parameters
+	Recv      bool            // type parameters declared by a method's receiver
+}
+
+type encVar struct {
+	Pkg      string // the package of unexported names
+	Name     string
+	Type     int
+	Embedded bool
+}
+
+type encFunction struct {
+	Name string
+	// Recv is the name of the receiver's base type for methods.
+	Recv string
+	// Parent is the index of the enclosing function of anonymous
+	// functions, and -1 otherwise.
+	Parent int
+	// Signature is the function's type for functions that can't be
+	// looked up by their name, and -1 otherwise.
+	Signature int
+	Synthetic Synthetic
+	NoReturn  NoReturn
+	HasLoops  bool
+	FreeVars  []encVar
+	Locals    []int
+	Blocks    []encBlock
+	Exit      int
+	AnonFuncs []int
+}
+
+type encBlock struct {
+	Comment string
+	Preds   []int
+	Succs   []int
+	Instrs  []encInstr
+}
+
+type encInstr struct {
+	Op       string
+	ID       ID
+	Type     int
+	Comment  string
+	Operands []encRef
+	Token    token.Token     // BinOp and UnOp
+	Index    int             // field and tuple indices, Sigma.From, parameters and Atomic.Op
+	Flag     bool            // Alloc.Heap, the CommaOk fields, Next.IsString and Select.Blocking
+	Name     string          // Parameter.name, and the method of invoke-mode calls
+	Pkg      string          // the package of unexported methods
+	Types    []int           // type arguments, TypeSwitch.Conds and TypeAssert.AssertedType
+	Dirs     []types.ChanDir // Select
+	Bits     []byte          // CompositeValue.Bitmap
+	Const    encConst
+}
+
+type refKind uint8
+
+const (
+	refNil refKind = iota
+	refValue
+	refFreeVar
+	refFunction
+	refObject
+	refInit
+	refGlobal
+	refBuiltin
+)
+
+// encRef refers to an operand of an instruction.
+type encRef struct {
+	Kind refKind
+	// Index is the index of the value among the values defined by
+	// the function, of the free variable, or of the function in
+	// encPackage.Funcs.
+	Index int
+	// Pkg, Recv and Name identify functions declared in other
+	// packages and globals.
+	Pkg  string
+	Recv string
+	Name string
+	Type int // the signature of builtins
+}
+
+// encConst describes a constant.Value. Numeric values are stored as
+// fractions of integers.
+type encConst struct {
+	Kind         constant.Kind // constant.Unknown represents nil
+	Bool         bool
+	String       string
+	Num, Denom   string
+	INum, IDenom string // imaginary parts
+}
+
+type encodingError struct {
+	msg string
+}
+
+func (err encodingError) Error() string { return err.msg }
+
+func encodingErrorf(format string, args ...interface{}) {
+	panic(encodingError{fmt.Sprintf(format, args...)})
+}
+
+func recoverEncodingError(err *error) {
+	if r := recover(); r != nil {
+		eerr, ok := r.(encodingError)
+		if !ok {
+			panic(r)
+		}
+		*err = eerr
+	}
+}
+
+// Encode writes the IR of the package's functions to w, so that it
+// can be restored with DecodePackage without building the package
+// again. The package must have been built.
+//
+// The encoding doesn't identify the sources the package was built
+// from. Callers should store it keyed by a hash of the package's
+// contents and dependencies, and only decode it if that hash still
+// matches.
+//
+// Source positions and DebugRef instructions refer to the syntax
+// tree and are not part of the encoding. Neither are the bodies of
+// blank functions. Encode returns an error for functions that refer
+// to function-local types, to synthetic functions that aren't part of
+// the package, such as wrappers and instantiations, or that contain
+// Copy or MultiConvert instructions.
+func (pkg *Package) Encode(w io.Writer) (err error) {
+	defer recoverEncodingError(&err)
+
+	e := &encoder{
+		pkg:   pkg,
+		funcs: map[*Function]int{},
+		types: map[types.Type]int{},
+		out:   encPackage{Version: encodingVersion, Path: pkg.Pkg.Path()},
+	}
+	var order []*Function
+	var add func(fn *Function)
+	add = func(fn *Function) {
+		e.funcs[fn] = len(order)
+		order = append(order, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	for _, fn := range pkg.Functions {
+		if fn.name != "_" {
+			add(fn)
+		}
+	}
+	for _, fn := range order {
+		e.out.Funcs = append(e.out.Funcs, e.function(fn))
+	}
+	return gob.NewEncoder(w).Encode(&e.out)
+}
+
+type encoder struct {
+	pkg   *Package
+	funcs map[*Function]int
+	types map[types.Type]int
+	out   encPackage
+
+	// The function being encoded and the values it defines
+	fn     *Function
+	values map[Value]int
+}
+
+// declaredInScope reports whether fn can be looked up by name in
+// its package.
+func declaredInScope(fn *Function) bool {
+	return fn.parent == nil && fn.object != nil && !(fn.Signature.Recv() == nil && fn.object.Name() == "init")
+}
+
+// recvName returns the name of the base type of a method's receiver.
+func recvName(sig *types.Signature) string {
+	if sig.Recv() == nil {
+		return ""
+	}
+	T := types.Unalias(sig.Recv().Type())
+	if ptr, ok := T.(*types.Pointer); ok {
+		T = types.Unalias(ptr.Elem())
+	}
+	if named, ok := T.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+func (e *encoder) function(fn *Function) encFunction {
+	e.fn = fn
+	e.values = map[Value]int{}
+
+	ef := encFunction{
+		Name:      fn.name,
+		Recv:      recvName(fn.Signature),
+		Parent:    -1,
+		Signature: -1,
+		Synthetic: fn.Synthetic,
+		NoReturn:  fn.NoReturn,
+		HasLoops:  fn.hasLoops,
+		Exit:      -1,
+	}
+	if fn.parent != nil {
+		ef.Parent = e.funcs[fn.parent]
+	}
+	if !declaredInScope(fn) {
+		ef.Signature = e.typ(fn.Signature)
+	}
+	for _, fv := range fn.FreeVars {
+		ef.FreeVars = append(ef.FreeVars, encVar{Name: fv.name, Type: e.typ(fv.typ)})
+	}
+	for _, anon := range fn.AnonFuncs {
+		ef.AnonFuncs = append(ef.AnonFuncs, e.funcs[anon])
+	}
+	if fn.Exit != nil {
+		ef.Exit = fn.Exit.Index
+	}
+
+	// Number the values first, as φ-nodes can refer to values that
+	// are defined later.
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(Value); ok {
+				e.values[v] = len(e.values)
+			}
+		}
+	}
+	for _, l := range fn.Locals {
+		ef.Locals = append(ef.Locals, e.values[l])
+	}
+	for _, b := range fn.Blocks {
+		eb := encBlock{Comment: b.Comment}
+		for _, pred := range b.Preds {
+			eb.Preds = append(eb.Preds, pred.Index)
+		}
+		for _, succ := range b.Succs {
+			eb.Succs = append(eb.Succs, succ.Index)
+		}
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*DebugRef); ok {
+				continue
+			}
+			eb.Instrs = append(eb.Instrs, e.instr(instr))
+		}
+		ef.Blocks = append(ef.Blocks, eb)
+	}
+	return ef
+}
+
+func (e *encoder) instr(instr Instruction) encInstr {
+	ei := encInstr{
+		ID:      instr.ID(),
+		Type:    -1,
+		Comment: instr.Comment(),
+	}
+	if v, ok := instr.(Value); ok {
+		ei.Type = e.typ(v.Type())
+	}
+	for _, rand := range instr.Operands(nil) {
+		ei.Operands = append(ei.Operands, e.ref(*rand))
+	}
+
+	switch instr := instr.(type) {
+	case *Const:
+		ei.Op = "Const"
+		ei.Const = encodeConst(instr.Value)
+	case *AggregateConst:
+		ei.Op = "AggregateConst"
+	case *ArrayConst:
+		ei.Op = "ArrayConst"
+	case *GenericConst:
+		ei.Op = "GenericConst"
+	case *CompositeValue:
+		ei.Op = "CompositeValue"
+		ei.Bits = instr.Bitmap.Bytes()
+		ei.Index = instr.NumSet
+	case *Parameter:
+		ei.Op = "Parameter"
+		ei.Name = instr.name
+		ei.Index = -1
+		for i, v := range signatureVars(e.fn.Signature) {
+			if v == instr.object {
+				ei.Index = i
+			}
+		}
+	case *Alloc:
+		ei.Op = "Alloc"
+		ei.Flag = instr.Heap
+	case *Sigma:
+		ei.Op = "Sigma"
+		ei.Index = instr.From.Index
+	case *Phi:
+		ei.Op = "Phi"
+	case *Call:
+		ei.Op = "Call"
+		e.callCommon(&ei, &instr.Call)
+	case *Atomic:
+		ei.Op = "Atomic"
+		ei.Index = int(instr.Op)
+	case *Go:
+		ei.Op = "Go"
+		e.callCommon(&ei, &instr.Call)
+	case *Defer:
+		ei.Op = "Defer"
+		e.callCommon(&ei, &instr.Call)
+	case *BinOp:
+		ei.Op = "BinOp"
+		ei.Token = instr.Op
+	case *UnOp:
+		ei.Op = "UnOp"
+		ei.Token = instr.Op
+	case *Load:
+		ei.Op = "Load"
+	case *ChangeType:
+		ei.Op = "ChangeType"
+	case *Convert:
+		ei.Op = "Convert"
+	case *ChangeInterface:
+		ei.Op = "ChangeInterface"
+	case *SliceToArrayPointer:
+		ei.Op = "SliceToArrayPointer"
+	case *SliceToArray:
+		ei.Op = "SliceToArray"
+	case *MakeInterface:
+		ei.Op = "MakeInterface"
+	case *MakeClosure:
+		ei.Op = "MakeClosure"
+	case *MakeMap:
+		ei.Op = "MakeMap"
+	case *MakeChan:
+		ei.Op = "MakeChan"
+	case *MakeSlice:
+		ei.Op = "MakeSlice"
+	case *Slice:
+		ei.Op = "Slice"
+	case *FieldAddr:
+		ei.Op = "FieldAddr"
+		ei.Index = instr.Field
+	case *Field:
+		ei.Op = "Field"
+		ei.Index = instr.Field
+	case *IndexAddr:
+		ei.Op = "IndexAddr"
+	case *Index:
+		ei.Op = "Index"
+	case *MapLookup:
+		ei.Op = "MapLookup"
+		ei.Flag = instr.CommaOk
+	case *StringLookup:
+		ei.Op = "StringLookup"
+	case *Range:
+		ei.Op = "Range"
+	case *Next:
+		ei.Op = "Next"
+		ei.Flag = instr.IsString
+	case *TypeAssert:
+		ei.Op = "TypeAssert"
+		ei.Flag = instr.CommaOk
+		ei.Types = []int{e.typ(instr.AssertedType)}
+	case *Extract:
+		ei.Op = "Extract"
+		ei.Index = instr.Index
+	case *Select:
+		ei.Op = "Select"
+		ei.Flag = instr.Blocking
+		for _, st := range instr.States {
+			ei.Dirs = append(ei.Dirs, st.Dir)
+		}
+	case *Recv:
+		ei.Op = "Recv"
+		ei.Flag = instr.CommaOk
+	case *TypeSwitch:
+		ei.Op = "TypeSwitch"
+		for _, cond := range instr.Conds {
+			ei.Types = append(ei.Types, e.typ(cond))
+		}
+	case *Jump:
+		ei.Op = "Jump"
+	case *Unreachable:
+		ei.Op = "Unreachable"
+	case *If:
+		ei.Op = "If"
+	case *ConstantSwitch:
+		ei.Op = "ConstantSwitch"
+	case *Return:
+		ei.Op = "Return"
+	case *RunDefers:
+		ei.Op = "RunDefers"
+	case *Panic:
+		ei.Op = "Panic"
+	case *Send:
+		ei.Op = "Send"
+	case *Store:
+		ei.Op = "Store"
+	case *BlankStore:
+		ei.Op = "BlankStore"
+	case *MapUpdate:
+		ei.Op = "MapUpdate"
+	default:
+		encodingErrorf("%s: unsupported instruction %T", e.fn, instr)
+	}
+	return ei
+}
+
+func (e *encoder) callCommon(ei *encInstr, call *CallCommon) {
+	if call.Method != nil {
+		ei.Name = call.Method.Name()
+		if !call.Method.Exported() {
+			ei.Pkg = call.Method.Pkg().Path()
+		}
+	}
+	for _, targ := range call.TypeArgs {
+		ei.Types = append(ei.Types, e.typ(targ))
+	}
+}
+
+// signatureVars returns the receiver and parameters of a signature,
+// which correspond to a function's Params.
+func signatureVars(sig *types.Signature) []*types.Var {
+	var vars []*types.Var
+	if sig.Recv() != nil {
+		vars = append(vars, sig.Recv())
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		vars = append(vars, sig.Params().At(i))
+	}
+	return vars
+}
+
+func (e *encoder) ref(v Value) encRef {
+	switch v := v.(type) {
+	case nil:
+		return encRef{Kind: refNil}
+	case *Function:
+		if i, ok := e.funcs[v]; ok {
+			return encRef{Kind: refFunction, Index: i}
+		}
+		if v.Synthetic == SyntheticPackageInitializer {
+			return encRef{Kind: refInit, Pkg: v.Pkg.Pkg.Path()}
+		}
+		if v.object == nil || v.object.Origin() != v.object || v.Synthetic != 0 && v.Synthetic != SyntheticLoadedFromExportData {
+			encodingErrorf("%s: unsupported reference to %s", e.fn, v)
+		}
+		return encRef{Kind: refObject, Pkg: v.object.Pkg().Path(), Recv: recvName(v.Signature), Name: v.object.Name()}
+	case *Global:
+		return encRef{Kind: refGlobal, Pkg: v.Pkg.Pkg.Path(), Name: v.name}
+	case *Builtin:
+		return encRef{Kind: refBuiltin, Name: v.name, Type: e.typ(v.sig)}
+	case *FreeVar:
+		for i, fv := range e.fn.FreeVars {
+			if fv == v {
+				return encRef{Kind: refFreeVar, Index: i}
+			}
+		}
+	default:
+		if i, ok := e.values[v]; ok {
+			return encRef{Kind: refValue, Index: i}
+		}
+	}
+	encodingErrorf("%s: %s is not defined by the function", e.fn, v.Name())
+	panic("unreachable")
+}
+
+func encodeConst(v constant.Value) encConst {
+	if v == nil {
+		return encConst{Kind: constant.Unknown}
+	}
+	c := encConst{Kind: v.Kind()}
+	switch v.Kind() {
+	case constant.Bool:
+		c.Bool = constant.BoolVal(v)
+	case constant.String:
+		c.String = constant.StringVal(v)
+	case constant.Int:
+		c.Num = v.ExactString()
+	case constant.Float:
+		c.Num, c.Denom = constant.Num(v).ExactString(), constant.Denom(v).ExactString()
+	case constant.Complex:
+		re, im := constant.Real(v), constant.Imag(v)
+		c.Num, c.Denom = constant.Num(re).ExactString(), constant.Denom(re).ExactString()
+		c.INum, c.IDenom = constant.Num(im).ExactString(), constant.Denom(im).ExactString()
+	default:
+		encodingErrorf("unsupported constant %s", v)
+	}
+	return c
+}
+
+func (e *encoder) typ(T types.Type) int {
+	if i, ok := e.types[T]; ok {
+		return i
+	}
+
+	var et encType
+	switch T := types.Unalias(T).(type) {
+	case *types.Basic:
+		et = encType{Tag: tagBasic, Kind: T.Kind(), Name: T.Name()}
+	case *types.Named:
+		obj := T.Obj()
+		et = encType{Tag: tagNamed, Name: obj.Name()}
+		if obj.Pkg() != nil {
+			if obj.Parent() != obj.Pkg().Scope() {
+				encodingErrorf("%s: unsupported function-local type %s", e.fn, T)
+			}
+			et.Pkg = obj.Pkg().Path()
+		}
+		if targs := T.TypeArgs(); targs != nil {
+			for i := 0; i < targs.Len(); i++ {
+				et.TypeArgs = append(et.TypeArgs, e.typ(targs.At(i)))
+			}
+		}
+	case *types.TypeParam:
+		et = encType{Tag: tagTypeParam, Owner: -1}
+		for fn := e.fn; fn != nil && et.Owner == -1; fn = fn.parent {
+			tparams := fn.Signature.TypeParams()
+			for i := 0; i < tparams.Len(); i++ {
+				if tparams.At(i) == T {
+					et.Owner, et.Index = e.funcs[fn], i
+				}
+			}
+			tparams = fn.Signature.RecvTypeParams()
+			for i := 0; i < tparams.Len(); i++ {
+				if tparams.At(i) == T {
+					et.Owner, et.Index, et.Recv = e.funcs[fn], i, true
+				}
+			}
+		}
+		if et.Owner == -1 {
+			encodingErrorf("%s: unsupported type parameter %s", e.fn, T)
+		}
+	case *types.Pointer:
+		et = encType{Tag: tagPointer, Elem: e.typ(T.Elem())}
+	case *types.Slice:
+		et = encType{Tag: tagSlice, Elem: e.typ(T.Elem())}
+	case *types.Array:
+		et = encType{Tag: tagArray, Elem: e.typ(T.Elem()), Len: T.Len()}
+	case *types.Map:
+		et = encType{Tag: tagMap, Key: e.typ(T.Key()), Elem: e.typ(T.Elem())}
+	case *types.Chan:
+		et = encType{Tag: tagChan, Elem: e.typ(T.Elem()), Dir: T.Dir()}
+	case *types.Signature:
+		if T.TypeParams().Len() != 0 {
+			encodingErrorf("%s: unsupported generic signature %s", e.fn, T)
+		}
+		et = encType{Tag: tagSignature, Params: e.typ(T.Params()), Results: e.typ(T.Results()), Variadic: T.Variadic()}
+	case *types.Tuple:
+		et = encType{Tag: tagTuple}
+		for i := 0; i < T.Len(); i++ {
+			et.Vars = append(et.Vars, e.encVar(T.At(i)))
+		}
+	case *types.Struct:
+		et = encType{Tag: tagStruct}
+		for i := 0; i < T.NumFields(); i++ {
+			et.Vars = append(et.Vars, e.encVar(T.Field(i)))
+			et.Tags = append(et.Tags, T.Tag(i))
+		}
+	case *types.Interface:
+		et = encType{Tag: tagInterface}
+		for i := 0; i < T.NumExplicitMethods(); i++ {
+			et.Vars = append(et.Vars, e.encVar(T.ExplicitMethod(i)))
+		}
+		for i := 0; i < T.NumEmbeddeds(); i++ {
+			et.Embeddeds = append(et.Embeddeds, e.typ(T.EmbeddedType(i)))
+		}
+	case *typeutil.Iterator:
+		et = encType{Tag: tagIterator, Elem: e.typ(T.Elem())}
+	case *typeutil.DeferStack:
+		et = encType{Tag: tagDeferStack}
+	default:
+		encodingErrorf("%s: unsupported type %s", e.fn, T)
+	}
+
+	i := len(e.out.Types)
+	e.out.Types = append(e.out.Types, et)
+	e.types[T] = i
+	return i
+}
+
+func (e *encoder) encVar(obj types.Object) encVar {
+	ev := encVar{Name: obj.Name(), Type: e.typ(obj.Type())}
+	if obj.Pkg() != nil && !obj.Exported() {
+		ev.Pkg = obj.Pkg().Path()
+	}
+	if v, ok := obj.(*types.Var); ok {
+		ev.Embedded = v.Embedded()
+	}
+	return ev
+}
+
+// DecodePackage restores the functions of a package encoded by
+// Package.Encode, in place of building the package.
+//
+// The package, as well as all packages it refers to, must have been
+// created in prog using the same type information that was used when
+// encoding the package, but the package must not have been built.
+// Building the package after it has been decoded does nothing.
+func DecodePackage(r io.Reader, prog *Program) (pkg *Package, err error) {
+	var ep encPackage
+	if err := gob.NewDecoder(r).Decode(&ep); err != nil {
+		return nil, err
+	}
+	if ep.Version != encodingVersion {
+		return nil, fmt.Errorf("unsupported encoding version %d, want %d", ep.Version, encodingVersion)
+	}
+
+	defer recoverEncodingError(&err)
+	d := &decoder{
+		prog:  prog,
+		in:    &ep,
+		pkgs:  map[string]*types.Package{},
+		ctxt:  types.NewContext(),
+		funcs: make([]*Function, len(ep.Funcs)),
+	}
+	for tpkg := range prog.packages {
+		d.addPackage(tpkg)
+	}
+	for _, p := range prog.packages {
+		if p.Pkg.Path() == ep.Path {
+			pkg = p
+		}
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("package %s has not been created", ep.Path)
+	}
+	if pkg.init.Blocks != nil {
+		return nil, fmt.Errorf("package %s has already been built", ep.Path)
+	}
+	d.pkg = pkg
+
+	// Functions that are declared in the package's scope have to be
+	// known before decoding types, as they own type parameters, and
+	// types have to be known before creating the remaining functions.
+	for i, ef := range ep.Funcs {
+		if ef.Signature == -1 {
+			d.funcs[i] = d.scopeFunc(ef)
+		}
+	}
+	d.types = make([]types.Type, 0, len(ep.Types))
+	for _, et := range ep.Types {
+		d.types = append(d.types, d.typ(et))
+	}
+	for i, ef := range ep.Funcs {
+		if ef.Signature != -1 {
+			d.funcs[i] = d.newFunc(ef)
+		}
+	}
+	for i, ef := range ep.Funcs {
+		d.body(d.funcs[i], ef)
+	}
+
+	pkg.buildOnce.Do(func() {})
+	pkg.info = nil
+	pkg.files = nil
+	pkg.initVersion = nil
+	markRecursive(pkg)
+
+	if prog.mode&SanityCheckFunctions != 0 {
+		sanityCheckPackage(pkg)
+	}
+	return pkg, nil
+}
+
+type decoder struct {
+	prog  *Program
+	pkg   *Package
+	in    *encPackage
+	pkgs  map[string]*types.Package
+	ctxt  *types.Context
+	types []types.Type
+	funcs []*Function
+}
+
+func (d *decoder) addPackage(tpkg *types.Package) {
+	if _, ok := d.pkgs[tpkg.Path()]; ok {
+		return
+	}
+	d.pkgs[tpkg.Path()] = tpkg
+	for _, imp := range tpkg.Imports() {
+		d.addPackage(imp)
+	}
+}
+
+func (d *decoder) lookup(path, name string) types.Object {
+	if path == "" {
+		return types.Universe.Lookup(name)
+	}
+	tpkg, ok := d.pkgs[path]
+	if !ok {
+		encodingErrorf("unknown package %s", path)
+	}
+	obj := tpkg.Scope().Lookup(name)
+	if obj == nil {
+		encodingErrorf("%s.%s is not declared", path, name)
+	}
+	return obj
+}
+
+// lookupFunc returns the object of a function or method.
+func (d *decoder) lookupFunc(path, recv, name string) *types.Func {
+	if recv == "" {
+		if fn, ok := d.lookup(path, name).(*types.Func); ok {
+			return fn
+		}
+		encodingErrorf("%s.%s is not a function", path, name)
+	}
+	if named, ok := types.Unalias(d.lookup(path, recv).Type()).(*types.Named); ok {
+		for i := 0; i < named.NumMethods(); i++ {
+			if m := named.Method(i); m.Name() == name {
+				return m
+			}
+		}
+	}
+	encodingErrorf("%s.%s has no method %s", path, recv, name)
+	panic("unreachable")
+}
+
+func (d *decoder) scopeFunc(ef encFunction) *Function {
+	obj := d.lookupFunc(d.pkg.Pkg.Path(), ef.Recv, ef.Name)
+	fn, ok := d.pkg.values[obj].(*Function)
+	if !ok {
+		encodingErrorf("no function for %s", obj)
+	}
+	return fn
+}
+
+func (d *decoder) newFunc(ef encFunction) *Function {
+	if ef.Parent == -1 {
+		// Init functions, including the package initializer, have been
+		// created if the package was created from source.
+		if fn, ok := d.pkg.Members[ef.Name].(*Function); ok {
+			return fn
+		}
+	}
+	sig, ok := d.types[ef.Signature].(*types.Signature)
+	if !ok {
+		encodingErrorf("%s doesn't have a signature", ef.Name)
+	}
+	fn := &Function{
+		name:      ef.Name,
+		Signature: sig,
+		Pkg:       d.pkg,
+		Prog:      d.prog,
+	}
+	if ef.Parent != -1 {
+		fn.parent = d.funcs[ef.Parent]
+	} else {
+		d.pkg.Members[fn.name] = fn
+		d.pkg.Functions = append(d.pkg.Functions, fn)
+	}
+	return fn
+}
+
+func (d *decoder) typ(et encType) types.Type {
+	T := func(i int) types.Type {
+		if i < 0 || i >= len(d.types) {
+			encodingErrorf("invalid type reference %d", i)
+		}
+		return d.types[i]
+	}
+	vars := func() []*types.Var {
+		var vars []*types.Var
+		for _, ev := range et.Vars {
+			vars = append(vars, types.NewField(token.NoPos, d.varPkg(ev), ev.Name, T(ev.Type), ev.Embedded))
+		}
+		return vars
+	}
+
+	switch et.Tag {
+	case tagBasic:
+		if obj, ok := types.Universe.Lookup(et.Name).(*types.TypeName); ok {
+			if basic, ok := obj.Type().(*types.Basic); ok && basic.Kind() == et.Kind {
+				return basic
+			}
+		}
+		if int(et.Kind) >= len(types.Typ) {
+			encodingErrorf("invalid basic kind %d", et.Kind)
+		}
+		return types.Typ[et.Kind]
+	case tagNamed:
+		tn, ok := d.lookup(et.Pkg, et.Name).(*types.TypeName)
+		if !ok {
+			encodingErrorf("%s.%s is not a type", et.Pkg, et.Name)
+		}
+		if et.TypeArgs == nil {
+			return tn.Type()
+		}
+		var targs []types.Type
+		for _, targ := range et.TypeArgs {
+			targs = append(targs, T(targ))
+		}
+		inst, err := types.Instantiate(d.ctxt, tn.Type(), targs, false)
+		if err != nil {
+			encodingErrorf("%s", err)
+		}
+		return inst
+	case tagTypeParam:
+		if et.Owner < 0 || et.Owner >= len(d.funcs) || d.funcs[et.Owner] == nil {
+			encodingErrorf("invalid owner of type parameter")
+		}
+		sig := d.funcs[et.Owner].Signature
+		tparams := sig.TypeParams()
+		if et.Recv {
+			tparams = sig.RecvTypeParams()
+		}
+		if et.Index >= tparams.Len() {
+			encodingErrorf("invalid type parameter index %d", et.Index)
+		}
+		return tparams.At(et.Index)
+	case tagPointer:
+		return types.NewPointer(T(et.Elem))
+	case tagSlice:
+		return types.NewSlice(T(et.Elem))
+	case tagArray:
+		return types.NewArray(T(et.Elem), et.Len)
+	case tagMap:
+		return types.NewMap(T(et.Key), T(et.Elem))
+	case tagChan:
+		return types.NewChan(et.Dir, T(et.Elem))
+	case tagSignature:
+		params, ok1 := T(et.Params).(*types.Tuple)
+		results, ok2 := T(et.Results).(*types.Tuple)
+		if !ok1 || !ok2 {
+			encodingErrorf("invalid signature")
+		}
+		return types.NewSignatureType(nil, nil, nil, params, results, et.Variadic)
+	case tagTuple:
+		var vars []*types.Var
+		for _, ev := range et.Vars {
+			vars = append(vars, types.NewParam(token.NoPos, d.varPkg(ev), ev.Name, T(ev.Type)))
+		}
+		return types.NewTuple(vars...)
+	case tagStruct:
+		return types.NewStruct(vars(), et.Tags)
+	case tagInterface:
+		var methods []*types.Func
+		for _, ev := range et.Vars {
+			sig, ok := T(ev.Type).(*types.Signature)
+			if !ok {
+				encodingErrorf("invalid method %s", ev.Name)
+			}
+			methods = append(methods, types.NewFunc(token.NoPos, d.varPkg(ev), ev.Name, sig))
+		}
+		var embeddeds []types.Type
+		for _, embedded := range et.Embeddeds {
+			embeddeds = append(embeddeds, T(embedded))
+		}
+		return types.NewInterfaceType(methods, embeddeds).Complete()
+	case tagIterator:
+		return typeutil.NewIterator(T(et.Elem))
+	case tagDeferStack:
+		return typeutil.NewDeferStack()
+	default:
+		encodingErrorf("invalid type tag %d", et.Tag)
+		panic("unreachable")
+	}
+}
+
+func (d *decoder) varPkg(ev encVar) *types.Package {
+	if ev.Pkg == "" {
+		return nil
+	}
+	tpkg, ok := d.pkgs[ev.Pkg]
+	if !ok {
+		encodingErrorf("unknown package %s", ev.Pkg)
+	}
+	return tpkg
+}
+
+func (d *decoder) body(fn *Function, ef encFunction) {
+	fn.Synthetic = ef.Synthetic
+	fn.NoReturn = ef.NoReturn
+	fn.hasLoops = ef.HasLoops
+	for _, i := range ef.AnonFuncs {
+		fn.AnonFuncs = append(fn.AnonFuncs, d.funcs[i])
+	}
+	fn.FreeVars = nil
+	for _, ev := range ef.FreeVars {
+		fn.FreeVars = append(fn.FreeVars, &FreeVar{name: ev.Name, typ: d.types[ev.Type], parent: fn})
+	}
+	if len(ef.Blocks) == 0 {
+		// Like the builder, give external functions parameters even
+		// though there is no code to reference them.
+		for _, v := range signatureVars(fn.Signature) {
+			fn.addParamVar(v, nil)
+		}
+		return
+	}
+
+	blocks := make([]*BasicBlock, len(ef.Blocks))
+	for i, eb := range ef.Blocks {
+		blocks[i] = &BasicBlock{Index: i, Comment: eb.Comment, parent: fn}
+	}
+	block := func(i int) *BasicBlock {
+		if i < 0 || i >= len(blocks) {
+			encodingErrorf("%s: invalid block reference %d", fn, i)
+		}
+		return blocks[i]
+	}
+
+	var values []Value
+	type pending struct {
+		instr Instruction
+		refs  []encRef
+		ei    *encInstr
+	}
+	var instrs []pending
+	for i := range ef.Blocks {
+		eb := &ef.Blocks[i]
+		b := blocks[i]
+		for _, pred := range eb.Preds {
+			b.Preds = append(b.Preds, block(pred))
+		}
+		for _, succ := range eb.Succs {
+			b.Succs = append(b.Succs, block(succ))
+		}
+		for j := range eb.Instrs {
+			ei := &eb.Instrs[j]
+			instr := d.instr(fn, ei, block)
+			instr.setBlock(b)
+			instr.setID(ei.ID)
+			if ei.Comment != "" {
+				instr.(interface{ setComment(string) }).setComment(ei.Comment)
+			}
+			if v, ok := instr.(Value); ok {
+				values = append(values, v)
+			}
+			b.Instrs = append(b.Instrs, instr)
+			instrs = append(instrs, pending{instr, ei.Operands, ei})
+		}
+	}
+
+	for _, p := range instrs {
+		rands := p.instr.Operands(nil)
+		if len(rands) != len(p.refs) {
+			encodingErrorf("%s: %s has %d operands, want %d", fn, p.ei.Op, len(p.refs), len(rands))
+		}
+		for i, ref := range p.refs {
+			*rands[i] = d.value(fn, values, ref)
+		}
+		if call, ok := p.instr.(CallInstruction); ok && p.ei.Name != "" {
+			common := call.Common()
+			common.Method = d.method(common.Value.Type(), p.ei.Name, p.ei.Pkg)
+		}
+	}
+
+	fn.Blocks = blocks
+	if ef.Exit != -1 {
+		fn.Exit = block(ef.Exit)
+	}
+	fn.Params = nil
+	for _, v := range values {
+		if param, ok := v.(*Parameter); ok {
+			fn.Params = append(fn.Params, param)
+		}
+	}
+	fn.Locals = nil
+	for _, i := range ef.Locals {
+		if i < 0 || i >= len(values) {
+			encodingErrorf("%s: invalid local %d", fn, i)
+		}
+		alloc, ok := values[i].(*Alloc)
+		if !ok {
+			encodingErrorf("%s: local %s isn't an Alloc", fn, values[i].Name())
+		}
+		fn.Locals = append(fn.Locals, alloc)
+	}
+
+	fn.functionBody = new(functionBody)
+	buildReferrers(fn)
+	buildFakeExits(fn)
+	buildDomTree(fn)
+	buildPostDomTree(fn)
+	fn.functionBody = nil
+}
+
+func (d *decoder) value(fn *Function, values []Value, ref encRef) Value {
+	switch ref.Kind {
+	case refNil:
+		return nil
+	case refValue:
+		if ref.Index >= 0 && ref.Index < len(values) {
+			return values[ref.Index]
+		}
+	case refFreeVar:
+		if ref.Index >= 0 && ref.Index < len(fn.FreeVars) {
+			return fn.FreeVars[ref.Index]
+		}
+	case refFunction:
+		if ref.Index >= 0 && ref.Index < len(d.funcs) {
+			return d.funcs[ref.Index]
+		}
+	case refObject:
+		obj := d.lookupFunc(ref.Pkg, ref.Recv, ref.Name)
+		if fn := d.prog.FuncValue(obj); fn != nil {
+			return fn
+		}
+		encodingErrorf("no function for %s", obj)
+	case refInit:
+		return d.irPackage(ref.Pkg).init
+	case refGlobal:
+		if g, ok := d.irPackage(ref.Pkg).Members[ref.Name].(*Global); ok {
+			return g
+		}
+		encodingErrorf("%s.%s is not a global", ref.Pkg, ref.Name)
+	case refBuiltin:
+		sig, ok := d.types[ref.Type].(*types.Signature)
+		if !ok {
+			encodingErrorf("builtin %s doesn't have a signature", ref.Name)
+		}
+		return &Builtin{name: ref.Name, sig: sig}
+	}
+	encodingErrorf("%s: invalid operand", fn)
+	panic("unreachable")
+}
+
+func (d *decoder) irPackage(path string) *Package {
+	tpkg, ok := d.pkgs[path]
+	if !ok || d.prog.packages[tpkg] == nil {
+		encodingErrorf("package %s has not been created", path)
+	}
+	return d.prog.packages[tpkg]
+}
+
+// method returns the method of the interface type T that is called by
+// an invoke-mode call.
+func (d *decoder) method(T types.Type, name, path string) *types.Func {
+	if tparam, ok := types.Unalias(T).(*types.TypeParam); ok {
+		T = tparam.Constraint()
+	}
+	if iface, ok := T.Underlying().(*types.Interface); ok {
+		for i := 0; i < iface.NumMethods(); i++ {
+			m := iface.Method(i)
+			if m.Name() == name && (path == "" || m.Pkg().Path() == path) {
+				return m
+			}
+		}
+	}
+	encodingErrorf("%s has no method %s", T, name)
+	panic("unreachable")
+}
+
+// instr returns an instruction with the encoded fields, and room for
+// the encoded operands.
+func (d *decoder) instr(fn *Function, ei *encInstr, block func(int) *BasicBlock) Instruction {
+	var typ types.Type
+	if ei.Type != -1 {
+		typ = d.types[ei.Type]
+	}
+	reg := register{typ: typ}
+	n := len(ei.Operands)
+	nonempty := func(want int) {
+		if n < want {
+			encodingErrorf("%s: %s has %d operands, want at least %d", fn, ei.Op, n, want)
+		}
+	}
+	var targs []types.Type
+	for _, i := range ei.Types {
+		targs = append(targs, d.types[i])
+	}
+
+	switch ei.Op {
+	case "Const":
+		return &Const{register: reg, Value: decodeConst(ei.Const)}
+	case "AggregateConst":
+		return &AggregateConst{register: reg, Values: make([]Value, n)}
+	case "ArrayConst":
+		return &ArrayConst{register: reg}
+	case "GenericConst":
+		return &GenericConst{register: reg}
+	case "CompositeValue":
+		v := &CompositeValue{register: reg, Values: make([]Value, n), NumSet: ei.Index}
+		v.Bitmap.SetBytes(ei.Bits)
+		return v
+	case "Parameter":
+		v := &Parameter{register: reg, name: ei.Name}
+		if vars := signatureVars(fn.Signature); ei.Index >= 0 && ei.Index < len(vars) {
+			v.object = vars[ei.Index]
+		} else {
+			v.object = types.NewParam(token.NoPos, nil, ei.Name, typ)
+		}
+		return v
+	case "Alloc":
+		return &Alloc{register: reg, Heap: ei.Flag}
+	case "Sigma":
+		return &Sigma{register: reg, From: block(ei.Index)}
+	case "Phi":
+		return &Phi{register: reg, Edges: make([]Value, n)}
+	case "Call":
+		nonempty(1)
+		return &Call{register: reg, Call: CallCommon{Args: make([]Value, n-1), TypeArgs: targs}}
+	case "Atomic":
+		nonempty(1)
+		return &Atomic{register: reg, Op: AtomicOp(ei.Index), Args: make([]Value, n-1)}
+	case "Go":
+		nonempty(1)
+		return &Go{Call: CallCommon{Args: make([]Value, n-1), TypeArgs: targs}}
+	case "Defer":
+		nonempty(2)
+		return &Defer{Call: CallCommon{Args: make([]Value, n-2), TypeArgs: targs}}
+	case "BinOp":
+		return &BinOp{register: reg, Op: ei.Token}
+	case "UnOp":
+		return &UnOp{register: reg, Op: ei.Token}
+	case "Load":
+		return &Load{register: reg}
+	case "ChangeType":
+		return &ChangeType{register: reg}
+	case "Convert":
+		return &Convert{register: reg}
+	case "ChangeInterface":
+		return &ChangeInterface{register: reg}
+	case "SliceToArrayPointer":
+		return &SliceToArrayPointer{register: reg}
+	case "SliceToArray":
+		return &SliceToArray{register: reg}
+	case "MakeInterface":
+		return &MakeInterface{register: reg}
+	case "MakeClosure":
+		nonempty(1)
+		return &MakeClosure{register: reg, Bindings: make([]Value, n-1)}
+	case "MakeMap":
+		return &MakeMap{register: reg}
+	case "MakeChan":
+		return &MakeChan{register: reg}
+	case "MakeSlice":
+		return &MakeSlice{register: reg}
+	case "Slice":
+		return &Slice{register: reg}
+	case "FieldAddr":
+		return &FieldAddr{register: reg, Field: ei.Index}
+	case "Field":
+		return &Field{register: reg, Field: ei.Index}
+	case "IndexAddr":
+		return &IndexAddr{register: reg}
+	case "Index":
+		return &Index{register: reg}
+	case "MapLookup":
+		return &MapLookup{register: reg, CommaOk: ei.Flag}
+	case "StringLookup":
+		return &StringLookup{register: reg}
+	case "Range":
+		return &Range{register: reg}
+	case "Next":
+		return &Next{register: reg, IsString: ei.Flag}
+	case "TypeAssert":
+		if len(targs) != 1 {
+			encodingErrorf("%s: type assertion without type", fn)
+		}
+		return &TypeAssert{register: reg, AssertedType: targs[0], CommaOk: ei.Flag}
+	case "Extract":
+		return &Extract{register: reg, Index: ei.Index}
+	case "Select":
+		v := &Select{register: reg, Blocking: ei.Flag}
+		if n != 2*len(ei.Dirs) {
+			encodingErrorf("%s: select has %d operands, want %d", fn, n, 2*len(ei.Dirs))
+		}
+		for _, dir := range ei.Dirs {
+			v.States = append(v.States, &SelectState{Dir: dir})
+		}
+		return v
+	case "Recv":
+		return &Recv{register: reg, CommaOk: ei.Flag}
+	case "TypeSwitch":
+		return &TypeSwitch{register: reg, Conds: targs}
+	case "Jump":
+		return &Jump{}
+	case "Unreachable":
+		return &Unreachable{}
+	case "If":
+		return &If{}
+	case "ConstantSwitch":
+		nonempty(1)
+		return &ConstantSwitch{Conds: make([]Value, n-1)}
+	case "Return":
+		return &Return{Results: make([]Value, n)}
+	case "RunDefers":
+		return &RunDefers{}
+	case "Panic":
+		return &Panic{}
+	case "Send":
+		return &Send{}
+	case "Store":
+		return &Store{}
+	case "BlankStore":
+		return &BlankStore{}
+	case "MapUpdate":
+		return &MapUpdate{}
+	default:
+		encodingErrorf("%s: unknown instruction %q", fn, ei.Op)
+		panic("unreachable")
+	}
+}
+
+func decodeConst(c encConst) constant.Value {
+	switch c.Kind {
+	case constant.Unknown:
+		return nil
+	case constant.Bool:
+		return constant.MakeBool(c.Bool)
+	case constant.String:
+		return constant.MakeString(c.String)
+	case constant.Int:
+		return decodeInt(c.Num)
+	case constant.Float:
+		return constant.BinaryOp(decodeInt(c.Num), token.QUO, decodeInt(c.Denom))
+	case constant.Complex:
+		re := constant.BinaryOp(decodeInt(c.Num), token.QUO, decodeInt(c.Denom))
+		im := constant.BinaryOp(decodeInt(c.INum), token.QUO, decodeInt(c.IDenom))
+		return constant.BinaryOp(re, token.ADD, constant.MakeImag(im))
+	default:
+		encodingErrorf("invalid constant kind %s", c.Kind)
+		panic("unreachable")
+	}
+}
+
+func decodeInt(s string) constant.Value {
+	lit, neg := strings.CutPrefix(s, "-")
+	v := constant.MakeFromLiteral(lit, token.INT, 0)
+	if v.Kind() != constant.Int {
+		encodingErrorf("invalid integer %q", s)
+	}
+	if neg {
+		v = constant.UnaryOp(token.SUB, v, 0)
+	}
+	return v
+}
@@ -0,0 +1,174 @@
+package ir_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+)
+
+func TestEncodePackage(t *testing.T) {
+	const input = `
+package p
+
+import "strings"
+
+type T struct {
+	a, b int
+	s    []string
+}
+
+func (t *T) Sum() int { return t.a + t.b }
+
+type I interface{ M(int) error }
+
+type E struct{}
+
+func (E) Error() string { return "" }
+
+var global T
+
+func init() { global.a = 1 }
+
+func sink(...interface{})
+
+func f(t *T, i I, m map[string]int, ch chan int, x interface{}, s string) (ret int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret = -1
+		}
+	}()
+	global.a++
+	t.s = append(t.s, strings.ToUpper(s))
+	for k, v := range m {
+		if k == "" {
+			continue
+		}
+		ret += v * t.a
+	}
+	for i, r := range s {
+		ret += i + int(r) + int(s[i])
+	}
+	select {
+	case v := <-ch:
+		ret += v
+	case ch <- 1:
+	default:
+	}
+	switch x := x.(type) {
+	case int:
+		ret += x
+	case string, []byte:
+		sink(x)
+	}
+	if v, ok := m["k"]; ok {
+		m["k"] = v + 1
+	}
+	go sink(1.5, 1.0/3, 2i, true, nil, [2]int{1, 2}, T{b: 1}, (*[1]byte)([]byte(s)))
+	arr := [3]int{1, 2, 3}
+	sl := arr[1:2:3]
+	sink(sl[0], arr[2], t.s[1:], *t, t.Sum(), E{})
+	if err := i.M(len(sl)); err != nil {
+		panic(err)
+	}
+	return ret, E{}
+}
+
+func counter() func() int {
+	n := 0
+	return func() int {
+		n++
+		return n
+	}
+}
+
+func g[T ~int | ~int8](x T, xs ...T) T {
+	for _, y := range xs {
+		x += y
+	}
+	return x
+}
+`
+	pkg := buildPackage(t, input)
+	var buf bytes.Buffer
+	if err := pkg.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	prog := ir.NewProgram(pkg.Prog.Fset, ir.SanityCheckFunctions)
+	for _, p := range pkg.Prog.AllPackages() {
+		prog.CreatePackage(p.Pkg, nil, nil, true)
+	}
+	decoded, err := ir.DecodePackage(&buf, prog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pkg != pkg.Pkg {
+		t.Fatalf("decoded package %s, want %s", decoded.Pkg, pkg.Pkg)
+	}
+
+	funcs := map[string]*ir.Function{}
+	var add func(fn *ir.Function)
+	add = func(fn *ir.Function) {
+		funcs[fn.String()] = fn
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	for _, fn := range decoded.Functions {
+		add(fn)
+	}
+
+	var check func(fn *ir.Function)
+	check = func(fn *ir.Function) {
+		dfn, ok := funcs[fn.String()]
+		if !ok {
+			t.Errorf("%s is missing", fn)
+			return
+		}
+		var want, got bytes.Buffer
+		ir.WriteFunction(&want, fn)
+		ir.WriteFunction(&got, dfn)
+		if got, want := withoutSourceInfo(got.String()), withoutSourceInfo(want.String()); got != want {
+			t.Errorf("round trip of %s failed, got:\n%s\nwant:\n%s", fn, got, want)
+		}
+		if fn.Blocks != nil {
+			checkParsedStructure(t, fn, dfn)
+		} else if len(dfn.Params) != len(fn.Params) {
+			t.Errorf("%s: got %d parameters, want %d", fn, len(dfn.Params), len(fn.Params))
+		}
+		if len(dfn.AnonFuncs) != len(fn.AnonFuncs) {
+			t.Errorf("%s: got %d anonymous functions, want %d", fn, len(dfn.AnonFuncs), len(fn.AnonFuncs))
+		}
+		for _, anon := range fn.AnonFuncs {
+			check(anon)
+		}
+	}
+	for _, fn := range pkg.Functions {
+		check(fn)
+	}
+
+	// The decoded package counts as built.
+	decoded.Build()
+	if fn := decoded.Func("f"); fn.Blocks == nil || fn.Params[0].Object() != pkg.Func("f").Params[0].Object() {
+		t.Errorf("decoded parameters don't refer to the declared parameters")
+	}
+}
+
+func TestEncodePackageUnsupported(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`package p; func f() interface{} { type local struct{}; return local{} }`, "unsupported function-local type"},
+		{`package p; func g[T any](x T) T { return x }; func f() int { return g(1) }`, "unsupported reference to g"},
+	}
+	for _, tt := range tests {
+		pkg := buildPackage(t, tt.src)
+		err := pkg.Encode(new(bytes.Buffer))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error %v, want error containing %q", err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ir
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// switchCFG returns the body of a loop around a switch with n cases,
+// each containing a branch. This results in roughly 3n blocks with
+// small dominance frontiers.
+func switchCFG(n int) string {
+	var sb strings.Builder
+	sb.WriteString("switch i {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "case %d:\n\tif b > %d {\n\t\tb--\n\t\tcontinue\n\t}\n\tsink(b)\n", i, i)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// sequenceCFG returns the body of a loop consisting of n branches that
+// continue the loop. This results in roughly 2n blocks, and the
+// frontier of the loop's post block is computed many times over.
+func sequenceCFG(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "if b > %d {\n\tsink(b)\n\tcontinue\n}\nsink(%d)\n", i, i)
+	}
+	return sb.String()
+}
+
+// buildLoop builds a function f whose body is a loop around body.
+func buildLoop(tb testing.TB, body string) *Function {
+	tb.Helper()
+	src := "package p\n\nfunc sink(int)\n\nfunc f(a, b int) {\n\tfor i := 0; i < a; i++ {\n" + body + "\tsink(i)\n\t}\n}\n"
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	prog := NewProgram(fset, 0)
+	irpkg := prog.CreatePackage(pkg, []*ast.File{f}, info, true)
+	irpkg.Build()
+	return irpkg.Func("f")
+}
+
+func TestDomFrontierBits(t *testing.T) {
+	for _, n := range []int{1, 10, 300} {
+		for _, body := range []string{switchCFG(n), sequenceCFG(n)} {
+			fn := buildLoop(t, body)
+			want := dedupFrontier(BlockMap[[]*BasicBlock](buildDomFrontier(fn)))
+			got := buildDomFrontierBits(fn).frontier(fn)
+			for _, b := range fn.Blocks {
+				if !slices.Equal(got[b.Index], want[b.Index]) {
+					t.Errorf("%d blocks: frontier of %s is %v, want %v", len(fn.Blocks), b, got[b.Index], want[b.Index])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDomFrontier(b *testing.B) {
+	cfgs := []struct {
+		name string
+		body string
+	}{
+		{"switch", switchCFG(1350)},
+		{"sequence", sequenceCFG(2000)},
+	}
+	for _, cfg := range cfgs {
+		fn := buildLoop(b, cfg.body)
+		if len(fn.Blocks) < 4000 {
+			b.Fatalf("%s: got %d blocks, want at least 4000", cfg.name, len(fn.Blocks))
+		}
+
+		b.Run(cfg.name+"/slice", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				df := make(domFrontier, len(fn.Blocks))
+				df.build(fn)
+			}
+		})
+		b.Run(cfg.name+"/bits", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buildDomFrontierBits(fn).frontier(fn)
+			}
+		})
+	}
+}
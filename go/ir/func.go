@@ -15,6 +15,7 @@ import (
 	"go/types"
 	"io"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
@@ -321,6 +322,8 @@ func (f *Function) blockset(i int) *BlockSet {
 			bs.Clear()
 		} else {
 			bs.values = make([]bool, len(f.Blocks))
+			bs.count = 0
+			bs.idx = 0
 		}
 	} else {
 		bs.Clear()
@@ -328,6 +331,41 @@ func (f *Function) blockset(i int) *BlockSet {
 	return bs
 }
 
+// AcquireBlockSet returns an empty BlockSet that can hold all of f's
+// blocks. Sets are reused from a pool owned by f, to which
+// ReleaseBlockSet returns them, so that passes that repeatedly need
+// scratch sets, such as dominator or liveness analyses, don't have to
+// allocate them anew.
+//
+// The pool isn't safe for concurrent use: passes that acquire or
+// release sets of the same function mustn't run concurrently. A set
+// mustn't be used after it has been released, and a set acquired
+// before blocks are added to f cannot hold the new blocks.
+func (f *Function) AcquireBlockSet() *BlockSet {
+	n := len(f.blocksetPool)
+	if n == 0 {
+		return NewBlockSet(len(f.Blocks))
+	}
+	bs := f.blocksetPool[n-1]
+	f.blocksetPool[n-1] = nil
+	f.blocksetPool = f.blocksetPool[:n-1]
+	if cap(bs.values) >= len(f.Blocks) {
+		bs.values = bs.values[:len(f.Blocks)]
+		bs.Clear()
+	} else {
+		bs.values = make([]bool, len(f.Blocks))
+		bs.count = 0
+		bs.idx = 0
+	}
+	return bs
+}
+
+// ReleaseBlockSet returns a set acquired with AcquireBlockSet to f's
+// pool.
+func (f *Function) ReleaseBlockSet(bs *BlockSet) {
+	f.blocksetPool = append(f.blocksetPool, bs)
+}
+
 func (f *Function) exitBlock() {
 	old := f.currentBlock
 
@@ -412,6 +450,104 @@ func (fn *Function) createDeferStack() {
 	emitStore(fn, spill, deferstack, nil)
 }
 
+// hasBackEdges reports whether f's CFG has any back edges, that is,
+// edges whose target dominates their source.
+// hasRangeFuncLoops reports whether f contains range-over-func loops.
+// The bodies of these loops are built as separate yield functions,
+// which the iterator calls once per iteration, so the loops don't
+// show up as back edges in f's control flow graph.
+func hasRangeFuncLoops(f *Function) bool {
+	for _, anon := range f.AnonFuncs {
+		if anon.Synthetic == SyntheticRangeOverFuncYield && anon.parent == f {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBackEdges(f *Function) bool {
+	for _, b := range f.Blocks {
+		for _, succ := range b.Succs {
+			if succ.Dominates(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markRecursive sets Function.recursive for all functions in p, by
+// finding the strongly connected components of the static call graph
+// using Tarjan's algorithm. Only calls to functions in p are
+// considered, as functions in other packages cannot statically call
+// back into p.
+func markRecursive(p *Package) {
+	index := map[*Function]int{}
+	lowlink := map[*Function]int{}
+	onStack := map[*Function]bool{}
+	var stack []*Function
+
+	var strongconnect func(fn *Function)
+	strongconnect = func(fn *Function) {
+		index[fn] = len(index)
+		lowlink[fn] = index[fn]
+		stack = append(stack, fn)
+		onStack[fn] = true
+
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				site, ok := instr.(CallInstruction)
+				if !ok {
+					continue
+				}
+				callee := site.Common().StaticCallee()
+				if callee == nil || callee.Pkg != p {
+					continue
+				}
+				if callee == fn {
+					fn.recursive = true
+				}
+				if _, ok := index[callee]; !ok {
+					strongconnect(callee)
+					if lowlink[callee] < lowlink[fn] {
+						lowlink[fn] = lowlink[callee]
+					}
+				} else if onStack[callee] && index[callee] < lowlink[fn] {
+					lowlink[fn] = index[callee]
+				}
+			}
+		}
+
+		if lowlink[fn] == index[fn] {
+			i := len(stack) - 1
+			for stack[i] != fn {
+				i--
+			}
+			scc := stack[i:]
+			for _, f := range scc {
+				if len(scc) > 1 {
+					f.recursive = true
+				}
+				onStack[f] = false
+			}
+			stack = stack[:i]
+		}
+	}
+
+	var visit func(fn *Function)
+	visit = func(fn *Function) {
+		if _, ok := index[fn]; !ok {
+			strongconnect(fn)
+		}
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+	for _, fn := range p.Functions {
+		visit(fn)
+	}
+}
+
 func numberNodes(f *Function) {
 	var base ID
 	for _, b := range f.Blocks {
@@ -595,6 +731,7 @@ func (f *Function) finishBody() {
 	buildPostDomTree(f)
 
 	if f.Prog.mode&NaiveForm == 0 {
+		splitAggregates(f)
 		for lift(f) {
 		}
 		if doSimplifyConstantCompositeValues {
@@ -609,6 +746,7 @@ func (f *Function) finishBody() {
 
 	if f.Prog.mode&SplitAfterNewInformation != 0 {
 		splitOnNewInformation(f.Blocks[0], &StackMap{})
+		foldCopies(f)
 	}
 
 	// clear remaining builder state
@@ -617,6 +755,8 @@ func (f *Function) finishBody() {
 	f.vars = nil       // (used by lifting)
 	f.goversion = ""
 
+	f.hasLoops = hasBackEdges(f) || f.Synthetic == SyntheticRangeOverFuncYield || hasRangeFuncLoops(f)
+
 	numberNodes(f)
 
 	defer f.wr.Close()
@@ -737,6 +877,158 @@ func (f *Function) emit(instr Instruction, source ast.Node) Value {
 	return f.currentBlock.emit(instr, source)
 }
 
+// HasLoops reports whether the function's control flow graph
+// contains any loops. It returns false for functions without bodies.
+//
+// Range-over-func loops count as loops, both for the function
+// containing them and for the yield functions holding their bodies,
+// even though the repetition happens in the iterator, not in the
+// control flow graph of either function.
+func (f *Function) HasLoops() bool { return f.hasLoops }
+
+// DedupeReferrers removes duplicate instructions from v's referrers,
+// keeping the first occurrence of each. Afterwards, instructions that
+// have v as an operand more than once appear only once. Transformations
+// don't avoid adding duplicates, as that would require scanning the
+// referrers on every change, but long lists of duplicates slow down
+// later scans. It is safe to call DedupeReferrers after a batch of
+// transformations, but not while iterating over v's referrers.
+func DedupeReferrers(v Value) {
+	dedupeReferrers(v, map[Instruction]struct{}{})
+}
+
+// DedupeAllReferrers calls DedupeReferrers for all of f's free
+// variables and the values defined by its instructions, with the same
+// restrictions.
+func (f *Function) DedupeAllReferrers() {
+	seen := map[Instruction]struct{}{}
+	for _, fv := range f.FreeVars {
+		dedupeReferrers(fv, seen)
+	}
+	for _, b := range f.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(Value); ok {
+				dedupeReferrers(v, seen)
+			}
+		}
+	}
+}
+
+// dedupeReferrers implements DedupeReferrers, using seen, which must
+// be empty, as scratch space.
+func dedupeReferrers(v Value, seen map[Instruction]struct{}) {
+	refs := v.Referrers()
+	if refs == nil || len(*refs) < 2 {
+		return
+	}
+	*refs = slices.DeleteFunc(*refs, func(instr Instruction) bool {
+		if _, ok := seen[instr]; ok {
+			return true
+		}
+		seen[instr] = struct{}{}
+		return false
+	})
+	clear(seen)
+}
+
+// RangeFuncLoop returns the range-over-func loop whose body f holds,
+// or nil if f isn't the yield function of such a loop. Each call of f
+// by the iterator executes one iteration of the loop; returning true
+// continues the loop and returning false exits it.
+func (f *Function) RangeFuncLoop() *ast.RangeStmt {
+	if f.Synthetic != SyntheticRangeOverFuncYield {
+		return nil
+	}
+	rng, _ := f.source.(*ast.RangeStmt)
+	return rng
+}
+
+// IsRecursive reports whether the function can call itself, either
+// directly or via other functions in the same package. Only static
+// calls are considered; calls of interface methods and function
+// values are not. The result is only available once the function's
+// package has been built.
+func (f *Function) IsRecursive() bool { return f.recursive }
+
+// CyclomaticComplexity returns the cyclomatic complexity of fn,
+// computed as E - N + 2 over fn's control flow graph, where E is the
+// number of edges and N the number of blocks. Blocks that have no
+// successors, such as those ending in a panic, are treated as having
+// an edge to the exit block, so that the graph has a single exit. It
+// returns 0 for functions without bodies.
+func CyclomaticComplexity(fn *Function) int {
+	if len(fn.Blocks) == 0 {
+		return 0
+	}
+	edges := 0
+	for _, b := range fn.Blocks {
+		if len(b.Succs) == 0 && b != fn.Exit {
+			edges++
+		} else {
+			edges += len(b.Succs)
+		}
+	}
+	return edges - len(fn.Blocks) + 2
+}
+
+// Callers returns the call instructions in f's package that may call
+// f. This includes all calls whose static callee is f and, if f is a
+// method, all calls of interface methods with the same name and
+// signature, as these may dynamically dispatch to f. Calls via
+// function values and calls from other packages aren't included.
+//
+// The call sites of a package are indexed on the first call of
+// Callers, which must happen after the package has been built.
+func (f *Function) Callers() []CallInstruction {
+	p := f.Pkg
+	if p == nil {
+		return nil
+	}
+	p.callersOnce.Do(p.indexCallers)
+
+	out := append([]CallInstruction(nil), p.staticCallers[f]...)
+	if f.Signature.Recv() == nil {
+		return out
+	}
+	for _, site := range p.invokeSites[f.name] {
+		if types.Identical(site.Common().Method.Type(), f.Signature) {
+			out = append(out, site)
+		}
+	}
+	return out
+}
+
+// indexCallers collects the static and invoke-mode call sites of all
+// functions in p.
+func (p *Package) indexCallers() {
+	p.staticCallers = map[*Function][]CallInstruction{}
+	p.invokeSites = map[string][]CallInstruction{}
+
+	var visit func(fn *Function)
+	visit = func(fn *Function) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				site, ok := instr.(CallInstruction)
+				if !ok {
+					continue
+				}
+				common := site.Common()
+				if common.IsInvoke() {
+					p.invokeSites[common.Method.Name()] = append(p.invokeSites[common.Method.Name()], site)
+				} else if callee := common.StaticCallee(); callee != nil {
+					p.staticCallers[callee] = append(p.staticCallers[callee], site)
+				}
+			}
+		}
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+	for _, fn := range p.Functions {
+		visit(fn)
+	}
+}
+
 // RelString returns the full name of this function, qualified by
 // package name, receiver type, etc.
 //
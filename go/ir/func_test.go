@@ -0,0 +1,396 @@
+package ir_test
+
+import (
+	"go/types"
+	"reflect"
+	"slices"
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+)
+
+func TestFunctionMetadata(t *testing.T) {
+	const input = `
+package p
+
+func straight(x int) int {
+	if x > 0 {
+		return x
+	}
+	return -x
+}
+
+func loop(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+
+func fact(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return n * fact(n-1)
+}
+
+func even(n int) bool {
+	if n == 0 {
+		return true
+	}
+	return odd(n - 1)
+}
+
+func odd(n int) bool {
+	if n == 0 {
+		return false
+	}
+	return even(n - 1)
+}
+
+func closure() {
+	var fn func()
+	fn = func() { fn() }
+	fn()
+}
+
+func callsRecursive() int {
+	return fact(3)
+}
+`
+	tests := []struct {
+		name      string
+		loops     bool
+		recursive bool
+	}{
+		{"straight", false, false},
+		{"loop", true, false},
+		{"fact", false, true},
+		{"even", false, true},
+		{"odd", false, true},
+		// Calls via function values aren't considered.
+		{"closure", false, false},
+		{"callsRecursive", false, false},
+	}
+	pkg := buildPackage(t, input)
+	for _, tt := range tests {
+		fn := pkg.Func(tt.name)
+		if got := fn.HasLoops(); got != tt.loops {
+			t.Errorf("%s.HasLoops() = %t, want %t", tt.name, got, tt.loops)
+		}
+		if got := fn.IsRecursive(); got != tt.recursive {
+			t.Errorf("%s.IsRecursive() = %t, want %t", tt.name, got, tt.recursive)
+		}
+	}
+}
+
+func TestRangeFuncLoop(t *testing.T) {
+	const input = `
+package p
+
+func rangeFunc(seq func(func(int) bool)) int {
+	s := 0
+	for x := range seq {
+		y := x * 2
+		if y > 10 {
+			break
+		}
+		s += y
+	}
+	return s
+}
+`
+	fn := buildFunction(t, input, "rangeFunc")
+	if !fn.HasLoops() {
+		t.Errorf("%s.HasLoops() = false, want true", fn)
+	}
+	if fn.RangeFuncLoop() != nil {
+		t.Errorf("%s.RangeFuncLoop() = %v, want nil", fn, fn.RangeFuncLoop())
+	}
+	if len(fn.AnonFuncs) != 1 {
+		t.Fatalf("got %d anonymous functions, want 1", len(fn.AnonFuncs))
+	}
+	yield := fn.AnonFuncs[0]
+	if !yield.HasLoops() {
+		t.Errorf("%s.HasLoops() = false, want true", yield)
+	}
+	if rng := yield.RangeFuncLoop(); rng == nil || rng != yield.Source() {
+		t.Errorf("%s.RangeFuncLoop() = %v, want the range statement", yield, rng)
+	}
+
+	// x and y are local to the loop body and are lifted, whereas s is
+	// shared between the function and its loop body and escapes.
+	var allocs []string
+	for _, f := range []*ir.Function{fn, yield} {
+		for _, b := range f.Blocks {
+			for _, instr := range b.Instrs {
+				if alloc, ok := instr.(*ir.Alloc); ok {
+					allocs = append(allocs, alloc.Comment())
+					if !alloc.Heap {
+						t.Errorf("%s: %s isn't heap-allocated", f, alloc)
+					}
+				}
+			}
+		}
+	}
+	if !slices.Contains(allocs, "s") || slices.Contains(allocs, "x") || slices.Contains(allocs, "y") {
+		t.Errorf("got allocs %v, want s but neither x nor y", allocs)
+	}
+}
+
+func TestFunctionCallers(t *testing.T) {
+	const input = `
+package p
+
+type Closer interface{ Close() error }
+type Stringer interface{ String() string }
+
+type T struct{}
+
+func (T) Close() error   { return nil }
+func (T) String() string { return "" }
+
+func helper() int { return 0 }
+
+func direct() int {
+	return helper() + helper()
+}
+
+func viaClosure() func() int {
+	return func() int { return helper() }
+}
+
+func viaValue(fn func() int) {
+	fn()
+}
+
+func passesValue() {
+	viaValue(helper)
+}
+
+func viaMethod(t T) {
+	t.Close()
+}
+
+func viaInterface(c Closer, s Stringer) {
+	c.Close()
+	_ = s.String()
+}
+`
+	pkg := buildPackage(t, input)
+	callers := func(fn *ir.Function) map[string]int {
+		out := map[string]int{}
+		for _, site := range fn.Callers() {
+			out[site.Parent().Name()]++
+		}
+		return out
+	}
+
+	method := func(name string) *ir.Function {
+		obj, _, _ := types.LookupFieldOrMethod(pkg.Type("T").Type(), false, pkg.Pkg, name)
+		return pkg.Prog.FuncValue(obj.(*types.Func))
+	}
+
+	tests := []struct {
+		fn   *ir.Function
+		want map[string]int
+	}{
+		// Calls via function values aren't included.
+		{pkg.Func("helper"), map[string]int{"direct": 2, "viaClosure$1": 1}},
+		{method("Close"), map[string]int{"viaMethod": 1, "viaInterface": 1}},
+		{method("String"), map[string]int{"viaInterface": 1}},
+		{pkg.Func("direct"), map[string]int{}},
+	}
+	for _, tt := range tests {
+		if got := callers(tt.fn); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.Callers() = %v, want %v", tt.fn.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestCyclomaticComplexity(t *testing.T) {
+	const input = `
+package p
+
+func external()
+
+func straight(x int) int {
+	x++
+	return x * 2
+}
+
+func single(x int) int {
+	if x > 0 {
+		return x
+	}
+	return -x
+}
+
+func loop(xs []int) int {
+	n := 0
+	for _, x := range xs {
+		if x > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func panics(x int) {
+	if x < 0 {
+		panic("negative")
+	}
+}
+
+func cases(x int) string {
+	switch x {
+	case 1:
+		return "one"
+	case 2:
+		return "two"
+	default:
+		return "many"
+	}
+}
+`
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"external", 0},
+		{"straight", 1},
+		{"single", 2},
+		{"loop", 3},
+		{"panics", 2},
+		{"cases", 3},
+	}
+	pkg := buildPackage(t, input)
+	for _, tt := range tests {
+		if got := ir.CyclomaticComplexity(pkg.Func(tt.name)); got != tt.want {
+			t.Errorf("CyclomaticComplexity(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlockSetPool(t *testing.T) {
+	const input = `
+package p
+
+func f(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			s += i
+		}
+	}
+	return s
+}
+`
+	fn := buildFunction(t, input, "f")
+
+	bs1 := fn.AcquireBlockSet()
+	bs2 := fn.AcquireBlockSet()
+	if bs1 == bs2 {
+		t.Fatal("acquired the same set twice")
+	}
+	for _, b := range fn.Blocks {
+		if bs1.Has(b) {
+			t.Errorf("new set contains %s", b)
+		}
+		bs1.Add(b)
+	}
+	fn.ReleaseBlockSet(bs1)
+
+	bs3 := fn.AcquireBlockSet()
+	if bs3 != bs1 {
+		t.Errorf("released set wasn't reused")
+	}
+	if n := bs3.Num(); n != 0 {
+		t.Errorf("reused set has %d elements, want 0", n)
+	}
+	for _, b := range fn.Blocks {
+		if bs3.Has(b) {
+			t.Errorf("reused set contains %s", b)
+		}
+		// The set must be able to hold every block.
+		bs3.Add(b)
+	}
+	if n := bs3.Num(); n != len(fn.Blocks) {
+		t.Errorf("set has %d elements, want %d", n, len(fn.Blocks))
+	}
+	fn.ReleaseBlockSet(bs2)
+	fn.ReleaseBlockSet(bs3)
+
+	if allocs := testing.AllocsPerRun(10, func() {
+		fn.ReleaseBlockSet(fn.AcquireBlockSet())
+	}); allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestDedupeReferrers(t *testing.T) {
+	const input = `
+package p
+
+func g() int
+func sink(int, int, int)
+
+func f() {
+	x := g()
+	y := x + 1
+	sink(x, y, x)
+	sink(x, x, y)
+}
+`
+	// Lifting replaces each load of x with the call of g, adding the
+	// calls of sink to the call's referrers once per argument.
+	callOfG := func(fn *ir.Function) ir.Value {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if call, ok := instr.(*ir.Call); ok && call.Call.StaticCallee().Name() == "g" {
+					return call
+				}
+			}
+		}
+		t.Fatal("couldn't find call of g")
+		return nil
+	}
+	dedupe := func(refs []ir.Instruction) []ir.Instruction {
+		var out []ir.Instruction
+		for _, ref := range refs {
+			if !slices.Contains(out, ref) {
+				out = append(out, ref)
+			}
+		}
+		return out
+	}
+
+	v := callOfG(buildFunction(t, input, "f"))
+	refs := slices.Clone(*v.Referrers())
+	want := dedupe(refs)
+	if len(refs) != 5 || len(want) != 3 {
+		t.Fatalf("got %d referrers, %d of them unique, want 5 and 3", len(refs), len(want))
+	}
+	ir.DedupeReferrers(v)
+	if got := *v.Referrers(); !slices.Equal(got, want) {
+		t.Errorf("got referrers %v, want %v", got, want)
+	}
+
+	fn := buildFunction(t, input, "f")
+	fn.DedupeAllReferrers()
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			v, ok := instr.(ir.Value)
+			if !ok || v.Referrers() == nil {
+				continue
+			}
+			if refs := *v.Referrers(); !slices.Equal(refs, dedupe(refs)) {
+				t.Errorf("%s has duplicate referrers %v", v.Name(), refs)
+			}
+		}
+	}
+	if refs := *callOfG(fn).Referrers(); len(refs) != 3 {
+		t.Errorf("got %d referrers of the call of g, want 3", len(refs))
+	}
+}
@@ -843,6 +843,8 @@ func valueLongHTML(v Node) string {
 		s += fmt.Sprintf(" {%s}", html.EscapeString(v.Op.String()))
 	case *UnOp:
 		s += fmt.Sprintf(" {%s}", html.EscapeString(v.Op.String()))
+	case *Atomic:
+		s += fmt.Sprintf(" {%s}", html.EscapeString(v.Op.String()))
 	case *Extract:
 		name := v.Tuple.Type().(*types.Tuple).At(v.Index).Name()
 		s += fmt.Sprintf(" [%d] (%s)", v.Index, name)
@@ -176,3 +176,35 @@ func Flatten(v ir.Value) ir.Value {
 	}
 	return out
 }
+
+// IsDiscarded reports whether v is never used, other than by being
+// assigned to the blank identifier. Uses by ir.Phi and ir.Sigma nodes
+// only count if the resulting values are used.
+func IsDiscarded(v ir.Value) bool {
+	seen := map[ir.Value]struct{}{}
+	var dfs func(v ir.Value) bool
+	dfs = func(v ir.Value) bool {
+		if _, ok := seen[v]; ok {
+			return true
+		}
+		seen[v] = struct{}{}
+
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ir.BlankStore, *ir.DebugRef:
+			case *ir.Phi:
+				if !dfs(ref) {
+					return false
+				}
+			case *ir.Sigma:
+				if !dfs(ref) {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	return dfs(v)
+}
@@ -37,15 +37,16 @@ package ir
 // whole function then renaming all alloc cells, it may be cheaper to
 // compute the DF for each alloc cell separately and throw it away.
 //
-// Consider exploiting liveness information to avoid creating dead
-// φ-nodes which we then immediately remove.
-//
 // Also see many other "TODO: opt" suggestions in the code.
 
 import (
+	"cmp"
 	"encoding/binary"
 	"fmt"
+	"go/types"
+	"math/bits"
 	"os"
+	"reflect"
 	"slices"
 )
 
@@ -56,11 +57,8 @@ const debugLifting = false
 // domFrontier maps each block to the set of blocks in its dominance
 // frontier.  The outer slice is conceptually a map keyed by
 // Block.Index.  The inner slice is conceptually a set, possibly
-// containing duplicates.
-//
-// TODO(adonovan): opt: measure impact of dups; consider a packed bit
-// representation, e.g. big.Int, and bitwise parallel operations for
-// the union step in the Children loop.
+// containing duplicates. For functions with many blocks, it is built
+// from a domFrontierBits instead, and contains no duplicates.
 //
 // domFrontier's methods mutate the slice's elements but not its
 // length, so their receivers needn't be pointers.
@@ -99,12 +97,137 @@ func (df domFrontier) build(fn *Function) {
 	}
 }
 
+// domFrontierBitsThreshold is the number of blocks above which
+// buildDomFrontier uses domFrontierBits. The bitsets need n²/8 bytes,
+// but for large functions that is cheaper than the many duplicates of
+// the slice representation.
+const domFrontierBitsThreshold = 1024
+
 func buildDomFrontier(fn *Function) domFrontier {
+	if len(fn.Blocks) > domFrontierBitsThreshold {
+		return buildDomFrontierBits(fn).frontier(fn)
+	}
 	df := make(domFrontier, len(fn.Blocks))
 	df.build(fn)
 	return df
 }
 
+// domFrontierBits is a packed representation of the dominance
+// frontier. Each block's frontier is a row of w words in bits, with
+// one bit per block index. This makes the union step of the
+// bottom-up algorithm a bitwise OR, and sets never contain
+// duplicates.
+type domFrontierBits struct {
+	w    int
+	bits []uint64
+}
+
+func (df domFrontierBits) row(b *BasicBlock) []uint64 {
+	return df.bits[b.Index*df.w : (b.Index+1)*df.w]
+}
+
+func setBit(row []uint64, b *BasicBlock) {
+	row[b.Index/64] |= 1 << (b.Index % 64)
+}
+
+// build builds the dominance frontier df for the dominator tree of
+// fn, using the bottom-up algorithm of Cytron et al: the frontier of
+// b is the union of b's successors and its children's frontiers,
+// minus the blocks b immediately dominates.
+func (df domFrontierBits) build(fn *Function) {
+	children := make([]uint64, df.w)
+	var visit func(b *BasicBlock)
+	visit = func(b *BasicBlock) {
+		for _, c := range b.dom.children {
+			visit(c)
+		}
+		row := df.row(b)
+		for _, s := range b.Succs {
+			setBit(row, s)
+		}
+		if fn.fakeExits.Has(b) {
+			setBit(row, fn.Exit)
+		}
+		clear(children)
+		for _, c := range b.dom.children {
+			for i, w := range df.row(c) {
+				row[i] |= w
+			}
+			setBit(children, c)
+		}
+		for i, w := range children {
+			row[i] &^= w
+		}
+	}
+	visit(fn.Blocks[0])
+}
+
+func buildDomFrontierBits(fn *Function) domFrontierBits {
+	w := (len(fn.Blocks) + 63) / 64
+	df := domFrontierBits{w: w, bits: make([]uint64, w*len(fn.Blocks))}
+	df.build(fn)
+	return df
+}
+
+// frontier converts df to a domFrontier. The sets are sorted by block
+// index and share a single backing array.
+func (df domFrontierBits) frontier(fn *Function) domFrontier {
+	n := 0
+	for _, w := range df.bits {
+		n += bits.OnesCount64(w)
+	}
+	all := make([]*BasicBlock, 0, n)
+	out := make(domFrontier, len(fn.Blocks))
+	for _, b := range fn.Blocks {
+		start := len(all)
+		for i, w := range df.row(b) {
+			for ; w != 0; w &= w - 1 {
+				all = append(all, fn.Blocks[i*64+bits.TrailingZeros64(w)])
+			}
+		}
+		if len(all) > start {
+			out[b.Index] = all[start:len(all):len(all)]
+		}
+	}
+	return out
+}
+
+// DominanceFrontier returns the dominance frontier of each of fn's
+// blocks, indexed by BasicBlock.Index. The dominance frontier of a
+// block b is the set of blocks that b doesn't strictly dominate, but
+// that have a predecessor that b dominates. These are the blocks
+// where φ-nodes for definitions in b are placed.
+//
+// The frontier is computed on first use and cached. It is recomputed
+// if the dominator tree has been rebuilt since. The returned map and
+// its slices must not be modified.
+func (fn *Function) DominanceFrontier() BlockMap[[]*BasicBlock] {
+	fn.domFrontierOnce.Do(func() {
+		fn.domFrontier = dedupFrontier(BlockMap[[]*BasicBlock](buildDomFrontier(fn)))
+	})
+	return fn.domFrontier
+}
+
+// PostDominanceFrontier is like DominanceFrontier, but returns the
+// frontiers of the post-dominator tree. These are the blocks where
+// σ-nodes are placed.
+func (fn *Function) PostDominanceFrontier() BlockMap[[]*BasicBlock] {
+	fn.postDomFrontierOnce.Do(func() {
+		fn.postDomFrontier = dedupFrontier(BlockMap[[]*BasicBlock](buildPostDomFrontier(fn)))
+	})
+	return fn.postDomFrontier
+}
+
+// dedupFrontier removes duplicate blocks from each of df's sets and
+// sorts them by index.
+func dedupFrontier(df BlockMap[[]*BasicBlock]) BlockMap[[]*BasicBlock] {
+	for i, blocks := range df {
+		slices.SortFunc(blocks, func(a, b *BasicBlock) int { return a.Index - b.Index })
+		df[i] = slices.Compact(blocks)
+	}
+	return df
+}
+
 type postDomFrontier BlockMap[[]*BasicBlock]
 
 func (rdf postDomFrontier) add(u, v *BasicBlock) {
@@ -162,6 +285,61 @@ func numberNodesPerBlock(f *Function) {
 	}
 }
 
+// Lift replaces Allocs in fn that are only accessed by loads and
+// stores with registers, inserting φ- and σ-nodes where necessary, and
+// reports whether it changed fn. Functions are lifted when they are
+// built; Lift is for tools that transform functions afterwards, for
+// example by introducing new Allocs, and need to restore pruned SSI
+// form.
+//
+// Lift doesn't verify its preconditions; it is the caller's
+// responsibility to ensure that:
+//   - fn has no dead blocks and no nil entries in Blocks (see
+//     RemoveNilBlocks);
+//   - def/use information (Operands and Referrers) is up to date;
+//   - the control flow graph hasn't changed since fn was built, so
+//     that the dominator and post-dominator trees are up to date.
+//
+// Lift must not be called concurrently with other uses of fn.
+func Lift(fn *Function) (changed bool) {
+	if fn.Blocks == nil {
+		return false
+	}
+
+	// The builder state has been discarded after building fn.
+	// Reconstruct the parts that lifting depends on.
+	fn.functionBody = new(functionBody)
+	defer func() { fn.functionBody = nil }()
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *Defer:
+				fn.hasDefer = true
+			case *Return:
+				// In functions that defer, results live in Allocs
+				// that are loaded right before returning.
+				for _, v := range instr.Results {
+					if load, ok := v.(*Load); ok {
+						if alloc, ok := load.X.(*Alloc); ok {
+							fn.results = append(fn.results, alloc)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for lift(fn) {
+		changed = true
+	}
+	if changed {
+		// Lifting may have created new constants
+		fn.emitConsts()
+		numberNodes(fn)
+	}
+	return changed
+}
+
 // lift replaces local and new Allocs accessed only with
 // load/store by IR registers, inserting φ- and σ-nodes where necessary.
 // The result is a program in pruned SSI form.
@@ -175,18 +353,7 @@ func lift(fn *Function) bool {
 	// worthwhile here, especially if they cause us to avoid
 	// buildDomFrontier.  For example:
 	//
-	// - Alloc never loaded?  Eliminate.
 	// - Alloc never stored?  Replace all loads with a zero constant.
-	// - Alloc stored once?  Replace loads with dominating store;
-	//   don't forget that an Alloc is itself an effective store
-	//   of zero.
-	// - Alloc used only within a single block?
-	//   Use degenerate algorithm avoiding φ-nodes.
-	// - Consider synergy with scalar replacement of aggregates (SRA).
-	//   e.g. *(&x.f) where x is an Alloc.
-	//   Perhaps we'd get better results if we generated this as x.f
-	//   i.e. Field(x, .f) instead of Load(FieldIndex(x, .f)).
-	//   Unclear.
 	//
 	// But we will start with the simplest correct code.
 	var df domFrontier
@@ -213,6 +380,22 @@ func lift(fn *Function) bool {
 	// The renaming phase uses this numbering for compact maps.
 	numAllocs := 0
 
+	// trivial records, for each lifted alloc, whether it can be lifted
+	// without φ- and σ-nodes. This is the case for allocs that are
+	// only used in the block that defines them, because every load is
+	// dominated by a store (or the Alloc itself) in the same block,
+	// and for allocs that are never loaded, because their stores can
+	// simply be deleted. Renaming alone suffices to lift them. If all
+	// liftable allocs are trivial, we don't need the dominance
+	// frontiers at all.
+	var trivial []bool
+
+	// storedOnce records, for each lifted alloc, whether it is stored
+	// to exactly once, by a store that dominates all loads that can
+	// observe it, and doesn't need any σ-nodes. Such allocs don't need
+	// φ-nodes, either.
+	var storedOnce []bool
+
 	instructions := make(BlockMap[liftInstructions], len(fn.Blocks))
 	for i := range instructions {
 		instructions[i].insertInstructions = map[Instruction][]Instruction{}
@@ -234,13 +417,21 @@ func lift(fn *Function) bool {
 				}
 
 				if numAllocs == 0 {
-					df = buildDomFrontier(fn)
+					newPhis = make(BlockMap[[]newPhi], len(fn.Blocks))
+					newSigmas = make(BlockMap[[]newSigma], len(fn.Blocks))
+				}
+				isTrivial := isBlockLocal(instr) || isNeverLoaded(instr)
+				if !isTrivial && rdf == nil {
 					rdf = buildPostDomFrontier(fn)
 					if len(fn.Blocks) > 2 {
 						closure = transitiveClosure(fn)
 					}
-					newPhis = make(BlockMap[[]newPhi], len(fn.Blocks))
-					newSigmas = make(BlockMap[[]newSigma], len(fn.Blocks))
+				}
+				isStoredOnce := !isTrivial && hasDominatingStore(instr) && !needsSigmas(closure, rdf, instr)
+				trivial = append(trivial, isTrivial)
+				storedOnce = append(storedOnce, isStoredOnce)
+				if !isTrivial && !isStoredOnce && df == nil {
+					df = buildDomFrontier(fn)
 
 					if debugLifting {
 						title := false
@@ -302,8 +493,12 @@ func lift(fn *Function) bool {
 
 		for _, b := range fn.Blocks {
 			for _, instr := range b.Instrs {
-				if instr, ok := instr.(*Alloc); ok && instr.index >= 0 {
-					liftAlloc(closure, df, rdf, instr, newPhis, newSigmas)
+				if instr, ok := instr.(*Alloc); ok && instr.index >= 0 && !trivial[instr.index] {
+					if storedOnce[instr.index] {
+						liftAlloc(closure, nil, rdf, instr, newPhis, newSigmas)
+					} else {
+						liftAlloc(closure, df, rdf, instr, newPhis, newSigmas)
+					}
 				}
 			}
 		}
@@ -313,7 +508,7 @@ func lift(fn *Function) bool {
 		// zero constant of the appropriate type; we construct the
 		// Const lazily at most once on each path through the domtree.
 		// TODO(adonovan): opt: cache per-function not per subtree.
-		renaming := make([]Value, numAllocs)
+		renaming := &renameStack{values: make([]Value, numAllocs)}
 
 		// Renaming.
 		rename(fn.Blocks[0], renaming, newPhis, newSigmas)
@@ -535,7 +730,25 @@ func markLiveSigma(sigma *Sigma) {
 // and replaces trivial phis with non-phi alternatives. Phi
 // nodes where all edges are identical, or consist of only the phi
 // itself and one other value, may be replaced with the value.
+//
+// Nodes are visited in a fixed order: by block index, then by the
+// index of the lifted Alloc they replace, and for sigmas, by the index
+// of the successor. Of a set of duplicates, the first node in this
+// order is kept and the others are replaced with it. This keeps the
+// resulting IR independent of the order in which the nodes were
+// created.
 func simplifyPhisAndSigmas(newPhis BlockMap[[]newPhi], newSigmas BlockMap[[]newSigma]) {
+	for _, npList := range newPhis {
+		slices.SortStableFunc(npList, func(a, b newPhi) int {
+			return cmp.Compare(a.alloc.index, b.alloc.index)
+		})
+	}
+	for _, nsList := range newSigmas {
+		slices.SortStableFunc(nsList, func(a, b newSigma) int {
+			return cmp.Compare(a.alloc.index, b.alloc.index)
+		})
+	}
+
 	// temporary numbering of values used in phis so that we can build map keys
 	var id ID
 	for _, npList := range newPhis {
@@ -668,6 +881,35 @@ func (s *BlockSet) Set(s2 *BlockSet) {
 	}
 }
 
+// Union adds all blocks in s2 to s.
+func (s *BlockSet) Union(s2 *BlockSet) {
+	for i, v := range s2.values {
+		if v && !s.values[i] {
+			s.values[i] = true
+			s.count++
+		}
+	}
+}
+
+// Intersect removes all blocks from s that aren't in s2.
+func (s *BlockSet) Intersect(s2 *BlockSet) {
+	for i, v := range s.values {
+		if v && (i >= len(s2.values) || !s2.values[i]) {
+			s.values[i] = false
+			s.count--
+		}
+	}
+}
+
+// Clone returns a copy of s.
+func (s *BlockSet) Clone() *BlockSet {
+	return &BlockSet{
+		idx:    s.idx,
+		values: slices.Clone(s.values),
+		count:  s.count,
+	}
+}
+
 func (s *BlockSet) Num() int {
 	return s.count
 }
@@ -696,6 +938,7 @@ func (s *BlockSet) Clear() {
 		s.values[j] = false
 	}
 	s.count = 0
+	s.idx = 0
 }
 
 // take removes an arbitrary element from a set s and
@@ -827,6 +1070,30 @@ func transitiveClosure(fn *Function) *closure {
 	return c
 }
 
+// Reachability answers whether one block of a function can reach
+// another.
+type Reachability struct {
+	c *closure
+}
+
+// Reachability returns the reachability relation of fn's blocks.
+//
+// It is computed on first use and cached on fn, and recomputed if fn's
+// blocks, and thus its dominator tree, have changed since.
+func (fn *Function) Reachability() *Reachability {
+	fn.reachabilityOnce.Do(func() {
+		fn.reachability = &Reachability{transitiveClosure(fn)}
+	})
+	return fn.reachability
+}
+
+// Reaches reports whether control can flow from block a to block b.
+// Every block reaches itself, and the exit block is considered to be
+// reachable from every block, including blocks in infinite loops.
+func (r *Reachability) Reaches(a, b *BasicBlock) bool {
+	return r.c.has(a, b)
+}
+
 // newPhi is a pair of a newly introduced φ-node and the lifted Alloc
 // it replaces.
 type newPhi struct {
@@ -848,6 +1115,135 @@ type liftInstructions struct {
 	}
 }
 
+// isBlockLocal reports whether alloc is only loaded from, stored to
+// and referred to by debug references in the block that defines it.
+func isBlockLocal(alloc *Alloc) bool {
+	for _, ref := range *alloc.Referrers() {
+		if ref.Block() != alloc.block {
+			return false
+		}
+		switch ref := ref.(type) {
+		case *Load, *DebugRef:
+		case *Store:
+			if ref.Addr != alloc || ref.Val == alloc {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isNeverLoaded reports whether alloc is only stored to and referred
+// to by debug references. Renaming deletes the stores of such allocs.
+// Debug references that denote the alloc's address are replaced with
+// the cell's current value during renaming, which we can only compute
+// without φ-nodes for references in the alloc's own block.
+func isNeverLoaded(alloc *Alloc) bool {
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *Store:
+			if ref.Addr != alloc || ref.Val == alloc {
+				return false
+			}
+		case *DebugRef:
+			if ref.IsAddr && ref.Block() != alloc.block {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasDominatingStore reports whether alloc is stored to exactly once,
+// and whether every load of alloc either is dominated by that store or
+// precedes it in alloc's block. The former observe the stored value,
+// the latter the zero value. In either case, no φ-nodes are needed.
+//
+// The store dominating a load implies that the load can't observe a
+// later execution of the Alloc, as alloc's block dominates the store.
+func hasDominatingStore(alloc *Alloc) bool {
+	var store *Store
+	for _, ref := range *alloc.Referrers() {
+		if ref, ok := ref.(*Store); ok {
+			if store != nil || ref.Addr != alloc || ref.Val == alloc {
+				return false
+			}
+			store = ref
+		}
+	}
+	if store == nil {
+		return false
+	}
+
+	observesStore := func(use Instruction) bool {
+		if use.Block() == store.Block() {
+			return use.ID() > store.ID()
+		}
+		return store.Block().Dominates(use.Block())
+	}
+	observesZero := func(use Instruction) bool {
+		if use.Block() != alloc.block {
+			return false
+		}
+		return store.Block() != alloc.block || use.ID() < store.ID()
+	}
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *Store:
+		case *Load:
+			if !observesStore(ref) && !observesZero(ref) {
+				return false
+			}
+		case *DebugRef:
+			if ref.IsAddr && !observesStore(ref) && !observesZero(ref) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// needsSigmas reports whether lifting alloc requires any σ-nodes. In
+// SSI form, σ-nodes are definitions that have to be merged by φ-nodes,
+// so allocs that need them can't be lifted without φ-nodes, even if
+// hasDominatingStore returned true.
+func needsSigmas(closure *closure, rdf postDomFrontier, alloc *Alloc) bool {
+	fn := alloc.Parent()
+	useblocks := fn.blockset(1)
+	for _, instr := range *alloc.Referrers() {
+		if instr, ok := instr.(*Load); ok {
+			useblocks.Add(instr.Block())
+			for _, ref := range *instr.Referrers() {
+				useblocks.Add(ref.Block())
+			}
+		}
+	}
+
+	// This is the first iteration of the σ-insertion in liftAlloc.
+	// Without φ-nodes, only a σ-node can cause further iterations.
+	for i := useblocks.Take(); i != -1; i = useblocks.Take() {
+		for _, y := range rdf[i] {
+			if closure == nil {
+				return true
+			}
+			for _, succ := range y.Succs {
+				for _, ref := range *alloc.Referrers() {
+					if closure.has(succ, ref.Block()) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 // liftable determines if alloc can be lifted, and records instructions to split partially liftable allocs.
 //
 // In the trivial case, all uses of the alloc can be lifted. This is the case when it is only used for storing into and
@@ -1173,6 +1569,7 @@ func liftable(alloc *Alloc, instructions BlockMap[liftInstructions]) bool {
 }
 
 // liftAlloc lifts alloc into registers and populates newPhis and newSigmas with all the φ- and σ-nodes it may require.
+// If df is nil, no φ-nodes are inserted; the caller must ensure that none are needed.
 func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *Alloc, newPhis BlockMap[[]newPhi], newSigmas BlockMap[[]newSigma]) {
 	fn := alloc.Parent()
 
@@ -1181,6 +1578,7 @@ func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *All
 	Aphi := fn.blockset(2)
 	Asigma := fn.blockset(3)
 	W := fn.blockset(4)
+	livein := fn.blockset(5)
 
 	// Compute defblocks, the set of blocks containing a
 	// definition of the alloc cell.
@@ -1198,6 +1596,10 @@ func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *All
 	// The Alloc itself counts as a (zero) definition of the cell.
 	defblocks.Add(alloc.Block())
 
+	if df != nil {
+		computeLiveIn(alloc, defblocks, livein, W)
+	}
+
 	if debugLifting {
 		fmt.Fprintln(os.Stderr, "\tlifting ", alloc, alloc.Name())
 	}
@@ -1213,7 +1615,7 @@ func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *All
 
 	for change := true; change; {
 		change = false
-		{
+		if df != nil {
 			// Traverse iterated dominance frontier, inserting φ-nodes.
 			W.Set(defblocks)
 
@@ -1224,20 +1626,14 @@ func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *All
 						if len(*alloc.Referrers()) == 0 {
 							continue
 						}
-						live := false
-						if closure == nil {
-							live = true
-						} else {
-							for _, ref := range *alloc.Referrers() {
-								if _, ok := ref.(*Load); ok {
-									if closure.has(y, ref.Block()) {
-										live = true
-										break
-									}
-								}
+						if !livein.Has(y) {
+							// A φ-node in y would be dead. Blocks in
+							// y's dominance frontier might still need
+							// φ-nodes, however, so we have to keep
+							// iterating the frontier.
+							if defblocks.Add(y) {
+								W.Add(y)
 							}
-						}
-						if !live {
 							continue
 						}
 
@@ -1332,10 +1728,83 @@ func liftAlloc(closure *closure, df domFrontier, rdf postDomFrontier, alloc *All
 	}
 }
 
+// computeLiveIn computes the set of blocks on entry to which the
+// value of alloc is live, that is, blocks from which a use of alloc
+// can be reached without passing through a definition. Only these
+// blocks need φ-nodes for alloc. Loads and DebugRefs of alloc's
+// address are uses; defblocks contains the blocks that store to alloc
+// or contain alloc itself. The result is stored in livein, and W is
+// used as scratch space.
+func computeLiveIn(alloc *Alloc, defblocks, livein, W *BlockSet) {
+	fn := alloc.Parent()
+	W.Clear()
+	for _, instr := range *alloc.Referrers() {
+		if !isAllocUse(alloc, instr) {
+			continue
+		}
+		b := instr.Block()
+		if livein.Has(b) {
+			continue
+		}
+		if defblocks.Has(b) && !usedBeforeDef(alloc, b) {
+			// The use observes the block's own definition.
+			continue
+		}
+		livein.Add(b)
+		W.Add(b)
+	}
+	for i := W.Take(); i != -1; i = W.Take() {
+		for _, pred := range fn.Blocks[i].Preds {
+			// A definition in pred kills the value that reaches its
+			// end, so alloc is only live on entry to pred if pred has
+			// a use of its own, in which case it is already in livein.
+			if !defblocks.Has(pred) && livein.Add(pred) {
+				W.Add(pred)
+			}
+		}
+	}
+}
+
+// isAllocUse reports whether instr uses the value stored in alloc.
+func isAllocUse(alloc *Alloc, instr Instruction) bool {
+	switch instr := instr.(type) {
+	case *Load:
+		return instr.X == alloc
+	case *DebugRef:
+		return instr.X == alloc && instr.IsAddr
+	default:
+		return false
+	}
+}
+
+// usedBeforeDef reports whether b uses the value of alloc before
+// defining it.
+func usedBeforeDef(alloc *Alloc, b *BasicBlock) bool {
+	for _, instr := range b.Instrs {
+		switch instr := instr.(type) {
+		case *Alloc:
+			if instr == alloc {
+				return false
+			}
+		case *Store:
+			if instr.Addr == alloc {
+				return false
+			}
+		default:
+			if isAllocUse(alloc, instr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // replaceAll replaces all intraprocedural uses of x with y,
-// updating x.Referrers and y.Referrers.
+// updating x.Referrers and y.Referrers. y inherits x's comment if it
+// has none of its own.
 // Precondition: x.Referrers() != nil, i.e. x must be local to some function.
 func replaceAll(x, y Value) {
+	inheritComment(x, y)
 	var rands []*Value
 	pxrefs := x.Referrers()
 	pyrefs := y.Referrers()
@@ -1367,6 +1836,18 @@ func replaceAll(x, y Value) {
 	*pxrefs = nil // x is now unreferenced
 }
 
+// inheritComment copies x's comment to y, which replaces x, if y
+// doesn't have a comment of its own. This keeps the names of lifted
+// variables visible when their φ-nodes and loads are replaced.
+func inheritComment(x, y Value) {
+	if y.Comment() != "" {
+		return
+	}
+	if instr, ok := y.(interface{ setComment(string) }); ok {
+		instr.setComment(x.Comment())
+	}
+}
+
 func replace(instr Instruction, x, y Value) {
 	args := instr.Operands(nil)
 	matched := false
@@ -1377,6 +1858,7 @@ func replace(instr Instruction, x, y Value) {
 		}
 	}
 	if matched {
+		inheritComment(x, y)
 		yrefs := y.Referrers()
 		if yrefs != nil {
 			*yrefs = append(*yrefs, instr)
@@ -1391,15 +1873,54 @@ func replace(instr Instruction, x, y Value) {
 
 // renamed returns the value to which alloc is being renamed,
 // constructing it lazily if it's the implicit zero initialization.
-func renamed(fn *Function, renaming []Value, alloc *Alloc) Value {
-	v := renaming[alloc.index]
+func renamed(fn *Function, renaming *renameStack, alloc *Alloc) Value {
+	v := renaming.Get(alloc.index)
 	if v == nil {
 		v = emitConst(fn, zeroConst(deref(alloc.Type()), alloc.source))
-		renaming[alloc.index] = v
+		renaming.Set(alloc.index, v)
 	}
 	return v
 }
 
+// renameStack maps allocs, keyed by their index, to their current
+// values during renaming. Push enters a scope and Pop leaves it,
+// reverting all changes made since the matching Push. Unlike
+// StackMap, it is backed by a single slice and a log of the values
+// that Set overwrote, so entering a scope doesn't copy anything and
+// lookups don't depend on the depth of the dominator tree.
+type renameStack struct {
+	values []Value
+	undo   []renameUndo
+	marks  []int
+}
+
+type renameUndo struct {
+	index int
+	old   Value
+}
+
+func (s *renameStack) Push() {
+	s.marks = append(s.marks, len(s.undo))
+}
+
+func (s *renameStack) Pop() {
+	mark := s.marks[len(s.marks)-1]
+	s.marks = s.marks[:len(s.marks)-1]
+	for i := len(s.undo) - 1; i >= mark; i-- {
+		s.values[s.undo[i].index] = s.undo[i].old
+	}
+	s.undo = s.undo[:mark]
+}
+
+func (s *renameStack) Get(index int) Value {
+	return s.values[index]
+}
+
+func (s *renameStack) Set(index int, v Value) {
+	s.undo = append(s.undo, renameUndo{index, s.values[index]})
+	s.values[index] = v
+}
+
 func copyValue(v Value, why Instruction, info CopyInfo) *Copy {
 	c := &Copy{
 		X:    v,
@@ -1472,7 +1993,6 @@ func splitOnNewInformation(u *BasicBlock, renaming *StackMap) {
 				continue
 			}
 			if r, ok := replacement(*arg); ok {
-				*arg = r
 				replace(instr, *arg, r)
 			}
 		}
@@ -1594,6 +2114,91 @@ func splitOnNewInformation(u *BasicBlock, renaming *StackMap) {
 	}
 }
 
+// foldCopies removes copies that don't tell us anything that the
+// copies they were made from don't already tell us.
+// splitOnNewInformation copies a value again each time it is used in
+// a way that may tell us something new, for example when it is used
+// as an index, even if the value is already a copy. A copy y of copy x
+// is redundant if y's information is a subset of x's and y was caused
+// by the same kind of instruction performing the same operation on
+// the same values, as its Why then doesn't carry additional
+// information, such as bounds, either.
+func foldCopies(fn *Function) {
+	for _, b := range fn.DomPreorder() {
+		j := 0
+		for _, instr := range b.Instrs {
+			if c, ok := instr.(*Copy); ok {
+				if x, ok := c.X.(*Copy); ok && c.Info&^x.Info == 0 && sameFacts(c.Why, x.Why) {
+					replaceAll(c, x)
+					killInstruction(c)
+					continue
+				}
+			}
+			b.Instrs[j] = instr
+			j++
+		}
+		clearInstrs(b.Instrs[j:])
+		b.Instrs = b.Instrs[:j]
+	}
+}
+
+// sameFacts reports whether the instructions x and y, which caused
+// values to be copied, tell us the same things about their operands.
+// This is the case if they're the same kind of instruction performing
+// the same operation on the same values, looking through copies, and
+// producing values of the same type. The latter matters for
+// instructions such as SliceToArrayPointer, where the length of the
+// resulting array tells us the minimum length of the slice.
+func sameFacts(x, y Instruction) bool {
+	if x == y {
+		return true
+	}
+	if reflect.TypeOf(x) != reflect.TypeOf(y) {
+		return false
+	}
+	if xv, ok := x.(Value); ok && !types.Identical(xv.Type(), y.(Value).Type()) {
+		return false
+	}
+	switch x := x.(type) {
+	case *UnOp:
+		if x.Op != y.(*UnOp).Op {
+			return false
+		}
+	case *BinOp:
+		if x.Op != y.(*BinOp).Op {
+			return false
+		}
+	case *TypeAssert:
+		y := y.(*TypeAssert)
+		if x.CommaOk != y.CommaOk || !types.Identical(x.AssertedType, y.AssertedType) {
+			return false
+		}
+	}
+	xops := x.Operands(nil)
+	yops := y.Operands(nil)
+	if len(xops) != len(yops) {
+		return false
+	}
+	for i := range xops {
+		if copiedValue(*xops[i]) != copiedValue(*yops[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// copiedValue returns the value that v is a, possibly indirect, copy
+// of, or v itself if it isn't a copy.
+func copiedValue(v Value) Value {
+	for {
+		c, ok := v.(*Copy)
+		if !ok {
+			return v
+		}
+		v = c.X
+	}
+}
+
 // rename implements the Cytron et al-based SSI renaming algorithm, a
 // preorder traversal of the dominator tree replacing all loads of
 // Alloc cells with the value stored to that cell by the dominating
@@ -1601,13 +2206,14 @@ func splitOnNewInformation(u *BasicBlock, renaming *StackMap) {
 //
 // renaming is a map from *Alloc (keyed by index number) to its
 // dominating stored value; newPhis[x] is the set of new φ-nodes to be
-// prepended to block x.
-func rename(u *BasicBlock, renaming []Value, newPhis BlockMap[[]newPhi], newSigmas BlockMap[[]newSigma]) {
+// prepended to block x. Changes that rename makes to renaming are only
+// reverted by the caller's Pop.
+func rename(u *BasicBlock, renaming *renameStack, newPhis BlockMap[[]newPhi], newSigmas BlockMap[[]newSigma]) {
 	// Each φ-node becomes the new name for its associated Alloc.
 	for _, np := range newPhis[u.Index] {
 		phi := np.phi
 		alloc := np.alloc
-		renaming[alloc.index] = phi
+		renaming.Set(alloc.index, phi)
 	}
 
 	// Rename loads and stores of allocs.
@@ -1616,7 +2222,7 @@ func rename(u *BasicBlock, renaming []Value, newPhis BlockMap[[]newPhi], newSigm
 		case *Alloc:
 			if instr.index >= 0 { // store of zero to Alloc cell
 				// Replace dominated loads by the zero value.
-				renaming[instr.index] = nil
+				renaming.Set(instr.index, nil)
 				if debugLifting {
 					fmt.Fprintf(os.Stderr, "\tkill alloc %s\n", instr)
 				}
@@ -1628,7 +2234,7 @@ func rename(u *BasicBlock, renaming []Value, newPhis BlockMap[[]newPhi], newSigm
 		case *Store:
 			if alloc, ok := instr.Addr.(*Alloc); ok && alloc.index >= 0 { // store to Alloc cell
 				// Replace dominated loads by the stored value.
-				renaming[alloc.index] = instr.Val
+				renaming.Set(alloc.index, instr.Val)
 				if debugLifting {
 					fmt.Fprintf(os.Stderr, "\tkill store %s; new value: %s\n",
 						instr, instr.Val.Name())
@@ -1734,23 +2340,23 @@ func rename(u *BasicBlock, renaming []Value, newPhis BlockMap[[]newPhi], newSigm
 		}
 	}
 
-	// Continue depth-first recursion over domtree, pushing a
-	// fresh copy of the renaming map for each subtree.
-	r := make([]Value, len(renaming))
+	// Continue depth-first recursion over domtree, entering a new
+	// scope of the renaming map for each subtree.
 	for _, v := range u.dom.children {
-		copy(r, renaming)
+		renaming.Push()
 
 		// on entry to a block, the incoming sigma nodes become the new values for their alloc
 		if idx := u.succIndex(v); idx != -1 {
 			for _, sigma := range newSigmas[u.Index] {
 				if sigma.sigmas[idx] != nil {
-					r[sigma.alloc.index] = sigma.sigmas[idx]
+					renaming.Set(sigma.alloc.index, sigma.sigmas[idx])
 				}
 			}
 		}
-		rename(v, r, newPhis, newSigmas)
-	}
+		rename(v, renaming, newPhis, newSigmas)
 
+		renaming.Pop()
+	}
 }
 
 func simplifyConstantCompositeValues(fn *Function) bool {
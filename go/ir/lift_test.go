@@ -0,0 +1,653 @@
+package ir_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+)
+
+func TestLiftBlockLocal(t *testing.T) {
+	const input = `
+package p
+
+func f(a, b int) int {
+	x := a
+	y := x + b
+	x = y * 2
+	x, y = y, x
+	var z int
+	z += x
+	return z - y
+}
+`
+	fn := buildFunction(t, input, "f")
+	if len(fn.Locals) != 0 {
+		t.Errorf("expected all locals to be lifted, got %v", fn.Locals)
+	}
+	var ret *ir.Return
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *ir.Alloc, *ir.Load, *ir.Store:
+				t.Errorf("unexpected %T after lifting: %s", instr, instr)
+			case *ir.Return:
+				ret = instr
+			}
+		}
+	}
+	if ret == nil {
+		t.Fatal("couldn't find return")
+	}
+
+	// After the swap, x = a + b and y = (a + b) * 2, so we expect
+	// (0 + (a + b)) - ((a + b) * 2).
+	binop := func(v ir.Value, op token.Token) *ir.BinOp {
+		t.Helper()
+		b, ok := v.(*ir.BinOp)
+		if !ok || b.Op != op {
+			t.Fatalf("expected %s, got %s", op, v)
+		}
+		return b
+	}
+	sub := binop(ret.Results[0], token.SUB)
+	z := binop(sub.X, token.ADD)
+	y := binop(sub.Y, token.MUL)
+	sum := binop(y.X, token.ADD)
+	if c, ok := z.X.(*ir.Const); !ok || c.Int64() != 0 {
+		t.Errorf("expected zero value of z, got %s", z.X)
+	}
+	if z.Y != sum {
+		t.Errorf("expected %s and %s to be the same value", z.Y, sum)
+	}
+	if sum.X != fn.Params[0] || sum.Y != fn.Params[1] {
+		t.Errorf("expected a + b, got %s", sum)
+	}
+}
+
+func TestLiftNeverLoaded(t *testing.T) {
+	const input = `
+package p
+
+func g() int
+
+func f(c bool, x int) {
+	if c {
+		x = g()
+	} else {
+		x = 3
+	}
+	for i := 0; i < 10; i++ {
+		x = i
+	}
+}
+`
+	fn := buildFunction(t, input, "f")
+	if len(fn.Locals) != 0 {
+		t.Errorf("expected all locals to be lifted, got %v", fn.Locals)
+	}
+	phis, sigmas := 0, 0
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *ir.Alloc, *ir.Load, *ir.Store:
+				t.Errorf("unexpected %T after lifting: %s", instr, instr)
+			case *ir.Phi:
+				phis++
+				if instr.Comment() != "i" {
+					t.Errorf("unexpected φ-node %s for %s", instr, instr.Comment())
+				}
+			case *ir.Sigma:
+				sigmas++
+				if instr.Comment() != "i" {
+					t.Errorf("unexpected σ-node %s for %s", instr, instr.Comment())
+				}
+			}
+		}
+	}
+	// The loop variable needs one of each; x, which is never read,
+	// needs none.
+	if phis != 1 || sigmas != 1 {
+		t.Errorf("got %d φ-nodes and %d σ-nodes, want 1 and 1", phis, sigmas)
+	}
+	if refs := *fn.Params[1].Referrers(); len(refs) != 0 {
+		t.Errorf("expected parameter x to be unused, got %v", refs)
+	}
+}
+
+func TestLiftStoredOnce(t *testing.T) {
+	const input = `
+package p
+
+func g() int
+func h()
+func sink(int)
+
+func all(c bool) {
+	x := g()
+	if c {
+		h()
+	}
+	sink(x)
+}
+
+func allLoop(c bool) {
+	x := g()
+	for c {
+		sink(x)
+	}
+	sink(x)
+}
+
+func none(c bool) {
+	var x int
+	sink(x)
+	if c {
+		x = g()
+	}
+}
+
+func partial(c bool) {
+	var x int
+	if c {
+		x = g()
+	}
+	sink(x)
+}
+`
+	pkg := buildPackage(t, input)
+	// sinks returns the arguments of the calls to sink in fn.
+	sinks := func(fn *ir.Function) []ir.Value {
+		var out []ir.Value
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ir.Alloc, *ir.Load, *ir.Store:
+					t.Errorf("unexpected %T in %s after lifting: %s", instr, fn, instr)
+				case *ir.Call:
+					if callee := instr.Call.StaticCallee(); callee != nil && callee.Name() == "sink" {
+						out = append(out, instr.Call.Args[0])
+					}
+				}
+			}
+		}
+		return out
+	}
+	isCallOfG := func(v ir.Value) bool {
+		call, ok := irutil.Flatten(v).(*ir.Call)
+		return ok && call.Call.StaticCallee() != nil && call.Call.StaticCallee().Name() == "g"
+	}
+	isZero := func(v ir.Value) bool {
+		c, ok := irutil.Flatten(v).(*ir.Const)
+		return ok && c.Int64() == 0
+	}
+
+	for _, name := range []string{"all", "allLoop"} {
+		for _, v := range sinks(pkg.Func(name)) {
+			if !isCallOfG(v) {
+				t.Errorf("%s: expected sink to be called with result of g, got %s", name, v)
+			}
+		}
+	}
+
+	if args := sinks(pkg.Func("none")); len(args) != 1 || !isZero(args[0]) {
+		t.Errorf("none: expected sink to be called with zero, got %v", args)
+	}
+
+	args := sinks(pkg.Func("partial"))
+	if len(args) != 1 {
+		t.Fatalf("partial: expected one call of sink, got %d", len(args))
+	}
+	phi, ok := args[0].(*ir.Phi)
+	if !ok || len(phi.Edges) != 2 {
+		t.Fatalf("partial: expected sink to be called with a φ-node, got %s", args[0])
+	}
+	if !(isCallOfG(phi.Edges[0]) && isZero(phi.Edges[1])) && !(isZero(phi.Edges[0]) && isCallOfG(phi.Edges[1])) {
+		t.Errorf("partial: expected φ-node of g() and zero, got %s", phi)
+	}
+}
+
+// BenchmarkLiftBlockLocal builds a function with many blocks and 200
+// allocs, each of which is only used in a single block.
+func BenchmarkLiftBlockLocal(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("package p\n\nfunc sink(int)\n\nfunc f(a, b int) {\n\tswitch a {\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, "\tcase %d:\n\t\tv := b\n\t\tv += %d\n\t\tv *= v\n\t\tsink(v)\n", i, i)
+	}
+	sb.WriteString("\t}\n}\n")
+	benchmarkBuild(b, sb.String())
+}
+
+// BenchmarkLiftDeadPhis builds a function with a loop and 100 allocs,
+// each of which is stored to in two branches and only loaded right
+// after each store. The allocs aren't live in the loop header and the
+// blocks joining the branches, which therefore don't need φ-nodes.
+func BenchmarkLiftDeadPhis(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("package p\n\nfunc sink(int)\n\nfunc f(a []int) {\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "\tvar v%d int\n", i)
+	}
+	sb.WriteString("\tfor _, x := range a {\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "\t\tif x > %[1]d {\n\t\t\tv%[1]d = x\n\t\t\tsink(v%[1]d)\n\t\t} else {\n\t\t\tv%[1]d = %[1]d\n\t\t\tsink(v%[1]d)\n\t\t}\n", i)
+	}
+	sb.WriteString("\t}\n}\n")
+	benchmarkBuild(b, sb.String())
+}
+
+// BenchmarkLiftWideDomTree builds a function with 200 allocs whose
+// entry block ends in a switch with 200 cases, so that the entry block
+// has more than 200 children in the dominator tree.
+func BenchmarkLiftWideDomTree(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("package p\n\nfunc sink(int)\n\nfunc f(a, b int) {\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, "\tv%d := b + %d\n", i, i)
+	}
+	sb.WriteString("\tswitch a {\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, "\tcase %d:\n\t\tsink(v%d)\n", i, i)
+	}
+	sb.WriteString("\t}\n}\n")
+	benchmarkBuild(b, sb.String())
+}
+
+// benchmarkBuild type-checks the package in src and benchmarks
+// building it.
+func benchmarkBuild(b *testing.B, src string) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prog := ir.NewProgram(fset, 0)
+		prog.CreatePackage(pkg, []*ast.File{f}, info, true).Build()
+	}
+}
+
+func TestBlockSetOperations(t *testing.T) {
+	blocks := make([]*ir.BasicBlock, 6)
+	for i := range blocks {
+		blocks[i] = &ir.BasicBlock{Index: i}
+	}
+	set := func(idxs ...int) *ir.BlockSet {
+		s := ir.NewBlockSet(len(blocks))
+		for _, idx := range idxs {
+			s.Add(blocks[idx])
+		}
+		return s
+	}
+	check := func(name string, s *ir.BlockSet, want ...int) {
+		t.Helper()
+		var got []int
+		for _, b := range blocks {
+			if s.Has(b) {
+				got = append(got, b.Index)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) || s.Num() != len(want) {
+			t.Errorf("%s: got %v with %d elements, want %v", name, got, s.Num(), want)
+		}
+	}
+
+	a := set(0, 1, 3)
+	b := set(1, 2, 3, 5)
+
+	u := a.Clone()
+	u.Union(b)
+	check("union", u, 0, 1, 2, 3, 5)
+	check("original after union", a, 0, 1, 3)
+
+	i := a.Clone()
+	i.Intersect(b)
+	check("intersection", i, 1, 3)
+
+	i.Intersect(set())
+	check("intersection with empty set", i)
+
+	c := b.Clone()
+	for c.Take() != -1 {
+	}
+	check("taken clone", c)
+	check("original after take", b, 1, 2, 3, 5)
+}
+
+func TestSplitAggregates(t *testing.T) {
+	const input = `
+package p
+
+type T struct {
+	a, b  int
+	inner struct{ c, d int }
+	arr   [2]int
+}
+
+func sink(*int)
+
+func f(c bool) int {
+	var x T
+	x.a = 1
+	if c {
+		x.inner.c = 2
+	}
+	return x.a + x.inner.c
+}
+
+func g() int {
+	var y T
+	y.a = 1
+	sink(&y.b)
+	return y.a
+}
+
+func h() struct{ c, d int } {
+	var z T
+	z.a = 1
+	z.inner.d = z.a
+	return z.inner
+}
+`
+	pkg := buildPackage(t, input)
+	count := func(fn *ir.Function) map[string]int {
+		out := map[string]int{}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr.(type) {
+				case *ir.Alloc, *ir.FieldAddr, *ir.Load, *ir.Store, *ir.Phi:
+					out[fmt.Sprintf("%T", instr)]++
+				}
+			}
+		}
+		return out
+	}
+	locals := func(fn *ir.Function) string {
+		var names []string
+		for _, l := range fn.Locals {
+			names = append(names, l.Comment())
+		}
+		return strings.Join(names, " ")
+	}
+
+	// All of x's fields, including the nested one, are lifted.
+	f := pkg.Func("f")
+	if got := count(f); len(got) != 1 || got["*ir.Phi"] != 1 {
+		t.Errorf("f: got instructions %v, want a single φ-node", got)
+	}
+	if got := locals(f); got != "" {
+		t.Errorf("f: got locals %q, want none", got)
+	}
+	for _, b := range f.Blocks {
+		for _, instr := range b.Instrs {
+			if phi, ok := instr.(*ir.Phi); ok && phi.Comment() != "x.inner.c" {
+				t.Errorf("f: got φ-node for %q, want x.inner.c", phi.Comment())
+			}
+		}
+	}
+
+	// The address of one of y's fields escapes, so y isn't split.
+	g := pkg.Func("g")
+	if got := count(g); got["*ir.Alloc"] != 1 || got["*ir.FieldAddr"] != 3 {
+		t.Errorf("g: got instructions %v, want y and its fields to remain", got)
+	}
+
+	// z.inner is loaded as a whole, so it can't be split or lifted,
+	// but z.a can.
+	h := pkg.Func("h")
+	if got := locals(h); got != "z.inner" {
+		t.Errorf("h: got locals %q, want z.inner", got)
+	}
+	if got := count(h); got["*ir.Alloc"] != 1 || got["*ir.FieldAddr"] != 1 || got["*ir.Load"] != 1 || got["*ir.Store"] != 1 {
+		t.Errorf("h: got instructions %v, want an alloc of z.inner with one field store and one load", got)
+	}
+
+	// With debug info, x is referred to by debug references, so it
+	// isn't split.
+	f = buildPackageWithMode(t, input, ir.GlobalDebug).Func("f")
+	if got := locals(f); got != "x" {
+		t.Errorf("f in debug mode: got locals %q, want x", got)
+	}
+}
+
+func TestLiftDeterministic(t *testing.T) {
+	// a and b are both replaced by x, resulting in duplicate σ- and
+	// φ-nodes that have to be deduplicated.
+	const input = `
+package p
+
+func sink(int)
+
+func f(x int, c bool) int {
+	a := x
+	b := x
+	if a > 0 {
+		sink(a)
+	} else {
+		sink(b)
+	}
+	for i := 0; i < 10; i++ {
+		if c {
+			a = b
+		} else {
+			b = a
+		}
+	}
+	return a + b
+}
+`
+	var want string
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		ir.WriteFunction(&buf, buildFunction(t, input, "f"))
+		if i == 0 {
+			want = buf.String()
+		} else if got := buf.String(); got != want {
+			t.Fatalf("lifting isn't deterministic, got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestLiftComments(t *testing.T) {
+	// All paths return 0, so the φ-node for n in the exit block is
+	// replaced by the constant, which should inherit its comment.
+	const input = `
+package p
+
+func g(string) bool
+
+func f() (n int) {
+	switch {
+	case g("a"):
+		return 0
+	case g("b"):
+		return 0
+	}
+	return 0
+}
+`
+	fn := buildFunction(t, input, "f")
+	ret := fn.Exit.Control().(*ir.Return)
+	v := ret.Results[0]
+	if _, ok := v.(*ir.Const); !ok {
+		t.Fatalf("got %s, want a constant", v)
+	}
+	if got := v.Comment(); got != "n" {
+		t.Errorf("got comment %q, want %q", got, "n")
+	}
+}
+
+func TestFoldCopies(t *testing.T) {
+	// The second s[i] copies s and i again, but doesn't tell us
+	// anything new. Indexing t, however, tells us something new about
+	// i, so the copy of the copy of i has to stay.
+	const input = `
+package p
+
+func f(s, t []int, i int) int {
+	a := s[i]
+	b := s[i]
+	c := t[i]
+	return a + b + c
+}
+`
+	fn := buildPackageWithMode(t, input, ir.SplitAfterNewInformation).Func("f")
+	var copies, nested []*ir.Copy
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if c, ok := instr.(*ir.Copy); ok {
+				copies = append(copies, c)
+				if _, ok := c.X.(*ir.Copy); ok {
+					nested = append(nested, c)
+				}
+			}
+			for _, op := range instr.Operands(nil) {
+				if *op == nil || (*op).Referrers() == nil {
+					continue
+				}
+				if !slices.Contains(*(*op).Referrers(), instr) {
+					t.Errorf("%s is missing referrer %s", (*op).Name(), instr)
+				}
+			}
+		}
+	}
+	if len(copies) != 4 {
+		t.Errorf("got %d copies, want 4", len(copies))
+	}
+	if len(nested) != 1 {
+		t.Fatalf("got %d copies of copies, want 1", len(nested))
+	}
+	why, ok := nested[0].Why.(*ir.IndexAddr)
+	if !ok || why.X != fn.Params[1] {
+		t.Errorf("got copy of copy caused by %s, want the indexing of t", nested[0].Why)
+	}
+
+	// Converting s to a longer array tells us more about its length,
+	// even though the conversions operate on the same value.
+	const input2 = `
+package p
+
+func g(s []int) int {
+	a := (*[2]int)(s)
+	b := (*[4]int)(s)
+	return a[0] + b[0]
+}
+`
+	fn = buildPackageWithMode(t, input2, ir.SplitAfterNewInformation).Func("g")
+	var whys []string
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if c, ok := instr.(*ir.Copy); ok {
+				if _, ok := c.X.(*ir.Copy); ok {
+					whys = append(whys, c.Why.(ir.Value).Type().String())
+				}
+			}
+		}
+	}
+	if !slices.Contains(whys, "*[4]int") {
+		t.Errorf("missing copy of copy caused by the conversion to *[4]int, got copies caused by %v", whys)
+	}
+}
+
+func TestLift(t *testing.T) {
+	// x can't be lifted when the function is built, because its
+	// address escapes. After removing the call, it can be.
+	const input = `
+package p
+
+func f(c bool) int {
+	x := 1
+	escape(&x)
+	if c {
+		x = 2
+	}
+	return x
+}
+
+func escape(*int) {}
+
+func g() (x int) {
+	defer func() { recover() }()
+	x = 1
+	panic(0)
+}
+`
+	pkg := buildPackage(t, input)
+	// Results of functions that defer must stay in memory.
+	if ir.Lift(pkg.Func("g")) {
+		t.Error("Lift lifted the result of a function that defers")
+	}
+
+	fn := pkg.Func("f")
+	if ir.Lift(fn) {
+		t.Fatal("lifting a freshly built function changed it")
+	}
+
+	var removed bool
+	for _, b := range fn.Blocks {
+		for i, instr := range b.Instrs {
+			call, ok := instr.(*ir.Call)
+			if !ok || call.Call.StaticCallee() == nil || call.Call.StaticCallee().Name() != "escape" {
+				continue
+			}
+			for _, op := range call.Operands(nil) {
+				if *op == nil || (*op).Referrers() == nil {
+					continue
+				}
+				refs := (*op).Referrers()
+				*refs = slices.DeleteFunc(*refs, func(ref ir.Instruction) bool { return ref == call })
+			}
+			b.Instrs = slices.Delete(b.Instrs, i, i+1)
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		t.Fatal("couldn't find call of escape")
+	}
+
+	if !ir.Lift(fn) {
+		t.Fatal("Lift didn't change the function")
+	}
+	var phis int
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr.(type) {
+			case *ir.Alloc, *ir.Load, *ir.Store:
+				t.Errorf("unexpected %s after lifting", instr)
+			case *ir.Phi:
+				phis++
+			}
+		}
+	}
+	if phis != 1 {
+		t.Errorf("got %d φ-nodes, want 1", phis)
+	}
+	if ir.Lift(fn) {
+		t.Error("lifting a lifted function changed it")
+	}
+}
@@ -28,6 +28,7 @@ const (
 	NaiveForm                                        // Build naïve IR form: don't replace local loads/stores with registers
 	GlobalDebug                                      // Enable debug info for all packages
 	SplitAfterNewInformation                         // Split live range after we learn something new about a value
+	AtomicOperations                                 // Emit Atomic instructions for calls to sync/atomic
 )
 
 const BuilderModeDoc = `Options controlling the IR builder.
@@ -40,6 +41,7 @@ A	print [A]ST nodes responsible for IR instructions
 S	log [S]ource locations as IR builder progresses.
 N	build [N]aive IR form: don't replace local loads/stores with registers.
 I	Split live range after a value is used as slice or array index
+O	emit Atomic instructions for calls of sync/atomic [O]perations.
 `
 
 func (m BuilderMode) String() string {
@@ -68,6 +70,9 @@ func (m BuilderMode) String() string {
 	if m&SplitAfterNewInformation != 0 {
 		buf.WriteByte('I')
 	}
+	if m&AtomicOperations != 0 {
+		buf.WriteByte('O')
+	}
 	return buf.String()
 }
 
@@ -92,6 +97,8 @@ func (m *BuilderMode) Set(s string) error {
 			mode |= NaiveForm
 		case 'I':
 			mode |= SplitAfterNewInformation
+		case 'O':
+			mode |= AtomicOperations
 		default:
 			return fmt.Errorf("unknown BuilderMode option: %q", c)
 		}
@@ -0,0 +1,1135 @@
+package ir
+
+// This file implements parsing of the textual representation of
+// functions produced by WriteFunction.
+
+import (
+	"bufio"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// ParseFunction parses the textual representation of a function, as
+// produced by WriteFunction, and reconstructs its blocks, its
+// instructions and the values they refer to.
+//
+// The textual representation doesn't contain complete type
+// information. Predeclared types, pointers, tuples and the function's
+// type parameters are reconstructed, but all other types are
+// represented by opaque types that only know their names and the
+// struct fields accessed by the function. Functions and globals
+// referred to by the function are represented by stubs that only know
+// their names; a name is assumed to refer to a global if it is used as
+// an address, and to a function otherwise. Source positions, DebugRef
+// instructions and the type arguments of calls are not part of the
+// result, and long string constants remain abbreviated.
+//
+// Printing the returned function with WriteFunction produces the same
+// blocks and instructions as the input, except for DebugRefs.
+func ParseFunction(r io.Reader) (fn *Function, err error) {
+	p := &funcParser{
+		types:  map[string]types.Type{},
+		values: map[string]Value{},
+		stubs:  map[string]Value{},
+		blocks: map[int]*BasicBlock{},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			fn, err = nil, perr
+		}
+	}()
+	return p.parse(r), nil
+}
+
+type parseError struct {
+	line int
+	msg  string
+}
+
+func (err parseError) Error() string {
+	if err.line == 0 {
+		return err.msg
+	}
+	return fmt.Sprintf("line %d: %s", err.line, err.msg)
+}
+
+// parsedType is the type of values in parsed functions whose type
+// can't be reconstructed from its name. If any of the fields of the
+// type have been accessed, its underlying type is a struct containing
+// these fields.
+type parsedType struct {
+	name   string
+	fields []string
+}
+
+func (t *parsedType) String() string { return t.name }
+
+func (t *parsedType) Underlying() types.Type {
+	if t.fields == nil {
+		return t
+	}
+	vars := make([]*types.Var, len(t.fields))
+	for i, name := range t.fields {
+		if name == "" {
+			name = "_"
+		}
+		vars[i] = types.NewField(token.NoPos, nil, name, types.Typ[types.Invalid], false)
+	}
+	return types.NewStruct(vars, nil)
+}
+
+func (t *parsedType) setField(i int, name string) {
+	for len(t.fields) <= i {
+		t.fields = append(t.fields, "")
+	}
+	t.fields[i] = name
+}
+
+// stubPackage is the package of the globals that parsed functions
+// refer to.
+var stubPackage = &Package{}
+
+var basicTypes = map[string]types.Type{}
+
+func init() {
+	for _, t := range types.Typ {
+		basicTypes[t.String()] = t
+	}
+	basicTypes["byte"] = types.Universe.Lookup("byte").Type()
+	basicTypes["rune"] = types.Universe.Lookup("rune").Type()
+}
+
+var operatorTokens = map[string]token.Token{}
+
+func init() {
+	for tok := token.ILLEGAL; tok <= token.TILDE; tok++ {
+		if tok.IsOperator() {
+			operatorTokens[tok.String()] = tok
+		}
+	}
+}
+
+var atomicOps = map[string]AtomicOp{}
+
+func init() {
+	for op := AtomicLoad; op <= AtomicOr; op++ {
+		atomicOps[op.String()] = op
+	}
+}
+
+type funcParser struct {
+	fn      *Function
+	line    int
+	section string // the current list in the header
+
+	types  map[string]types.Type
+	values map[string]Value // values defined by the function
+	stubs  map[string]Value // functions, globals and builtins used by the function
+	blocks map[int]*BasicBlock
+
+	// Operands are resolved once all values have been defined, as
+	// φ-nodes can refer to values that are defined later.
+	operands []operandRef
+	fields   []fieldRef
+	locals   []string
+}
+
+type operandRef struct {
+	ptr  *Value
+	name string
+	addr bool // whether the operand is used as an address
+}
+
+// fieldRef records the name of the field accessed by a Field or
+// FieldAddr instruction.
+type fieldRef struct {
+	x     *Value
+	deref bool
+	index int
+	name  string
+}
+
+func (p *funcParser) errorf(format string, args ...interface{}) {
+	panic(parseError{p.line, fmt.Sprintf(format, args...)})
+}
+
+func (p *funcParser) parse(r io.Reader) *Function {
+	p.fn = &Function{
+		Prog:      NewProgram(token.NewFileSet(), 0),
+		Signature: new(types.Signature),
+	}
+
+	var b *BasicBlock
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	for sc.Scan() {
+		p.line++
+		line := sc.Text()
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# "):
+			p.parseHeader(line[len("# "):])
+		case strings.HasPrefix(line, "func "):
+			p.parseSignature(strings.TrimSuffix(line, ":"))
+		case line == "\t(external)":
+		case strings.HasPrefix(line, "\t\t> "):
+			// Source code printed in PrintSource mode.
+		case strings.HasPrefix(line, "\t"):
+			if b == nil {
+				p.errorf("instruction outside of block")
+			}
+			p.parseInstruction(b, line[len("\t"):])
+		case strings.HasPrefix(line, "b"):
+			b = p.parseBlock(line)
+		default:
+			p.errorf("unexpected line %q", line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		p.errorf("%s", err)
+	}
+	p.finish()
+	return p.fn
+}
+
+func (p *funcParser) parseHeader(line string) {
+	fn := p.fn
+	key, value, _ := strings.Cut(line, ": ")
+	switch key {
+	case "Name", "Location":
+		// The name is derived from the signature and the package, and
+		// we don't have a file set for positions.
+	case "Package":
+		fn.Pkg = &Package{Pkg: types.NewPackage(value, path.Base(value))}
+	case "Synthetic":
+		for syn := SyntheticLoadedFromExportData; syn <= SyntheticRangeOverFuncYield; syn++ {
+			if syn.String() == value {
+				fn.Synthetic = syn
+			}
+		}
+		if fn.Synthetic == 0 {
+			p.errorf("unknown kind of synthetic function %q", value)
+		}
+	case "Parent":
+		fn.parent = &Function{name: value, Signature: new(types.Signature), Pkg: fn.Pkg}
+	case "Free variables:", "Locals:":
+		p.section = key
+	default:
+		// An element of the list of free variables or locals.
+		_, entry, ok := strings.Cut(line, ":\t")
+		if !ok {
+			p.errorf("unexpected header %q", line)
+		}
+		name, typ, ok := strings.Cut(entry, " ")
+		if !ok {
+			p.errorf("malformed variable %q", entry)
+		}
+		switch p.section {
+		case "Free variables:":
+			fv := &FreeVar{name: name, typ: p.parseType(typ), parent: fn}
+			fn.FreeVars = append(fn.FreeVars, fv)
+			p.values[name] = fv
+		case "Locals:":
+			p.locals = append(p.locals, name)
+		default:
+			p.errorf("unexpected header %q", line)
+		}
+	}
+}
+
+// parseSignature parses a function's signature in declaration syntax,
+// as written by writeSignature.
+func (p *funcParser) parseSignature(s string) {
+	s = strings.TrimPrefix(s, "func ")
+	var recv *types.Var
+	if strings.HasPrefix(s, "(") {
+		end := p.closing(s, 0)
+		recv = p.parseVar(s[1:end])
+		s = strings.TrimSpace(s[end+1:])
+	}
+	open := strings.IndexAny(s, "[(")
+	if open == -1 {
+		p.errorf("malformed signature %q", s)
+	}
+	name := s[:open]
+	var tparams []*types.TypeParam
+	if s[open] == '[' {
+		end := p.closing(s, open)
+		tparams = p.parseTypeParams(s[open+1 : end])
+		s = s[end+1:]
+		open = 0
+	}
+	if !strings.HasPrefix(s[open:], "(") {
+		p.errorf("malformed signature %q", s)
+	}
+	end := p.closing(s, open)
+
+	var params []*types.Var
+	variadic := false
+	for _, param := range splitList(s[open+1 : end]) {
+		v := p.parseVar(param)
+		if t, ok := v.Type().(*parsedType); ok && strings.HasPrefix(t.name, "...") {
+			v = types.NewVar(token.NoPos, nil, v.Name(), types.NewSlice(p.parseType(t.name[len("..."):])))
+			variadic = true
+		}
+		params = append(params, v)
+	}
+
+	var results []*types.Var
+	if res := strings.TrimSpace(s[end+1:]); strings.HasPrefix(res, "(") {
+		for _, r := range splitList(res[1:p.closing(res, 0)]) {
+			results = append(results, p.parseVar(r))
+		}
+	} else if res != "" {
+		results = append(results, types.NewVar(token.NoPos, nil, "", p.parseType(res)))
+	}
+
+	p.fn.name = name
+	p.fn.Signature = types.NewSignatureType(recv, nil, tparams, types.NewTuple(params...), types.NewTuple(results...), variadic)
+}
+
+// parseTypeParams parses a list of type parameters, such as
+// "K comparable, V1, V2 any".
+func (p *funcParser) parseTypeParams(s string) []*types.TypeParam {
+	var tparams []*types.TypeParam
+	var pending []*types.TypeName
+	for _, el := range splitList(s) {
+		name, constraint, ok := strings.Cut(el, " ")
+		obj := types.NewTypeName(token.NoPos, nil, name, nil)
+		pending = append(pending, obj)
+		if ok {
+			bound, ok := p.types[constraint]
+			if !ok {
+				// go/types wraps constraints that aren't interfaces in a
+				// new implicit interface per type parameter, which would
+				// stop us from printing "U, V any" the way it was
+				// written.
+				obj := types.NewTypeName(token.NoPos, nil, constraint, nil)
+				bound = types.NewNamed(obj, types.NewInterfaceType(nil, nil).Complete(), nil)
+				p.types[constraint] = bound
+			}
+			for _, obj := range pending {
+				tparam := types.NewTypeParam(obj, bound)
+				p.types[obj.Name()] = tparam
+				tparams = append(tparams, tparam)
+			}
+			pending = nil
+		}
+	}
+	if pending != nil {
+		p.errorf("type parameters without constraint in %q", s)
+	}
+	return tparams
+}
+
+func (p *funcParser) parseBlock(line string) *BasicBlock {
+	line, comment, _ := strings.Cut(line, " # ")
+	head, preds, _ := strings.Cut(line, " ←")
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(head, "b"), ":"))
+	if err != nil || !strings.HasSuffix(head, ":") {
+		p.errorf("malformed block header %q", line)
+	}
+	b := p.block(n)
+	if b.parent != nil {
+		p.errorf("block b%d defined twice", n)
+	}
+	b.parent = p.fn
+	b.Comment = comment
+	for _, pred := range strings.Fields(preds) {
+		b.Preds = append(b.Preds, p.block(p.blockIndex(pred)))
+	}
+	return b
+}
+
+// block returns the block with index n, which may not have been
+// defined yet.
+func (p *funcParser) block(n int) *BasicBlock {
+	b, ok := p.blocks[n]
+	if !ok {
+		b = &BasicBlock{Index: n}
+		p.blocks[n] = b
+	}
+	return b
+}
+
+func (p *funcParser) blockIndex(s string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "b"))
+	if err != nil || !strings.HasPrefix(s, "b") {
+		p.errorf("malformed block reference %q", s)
+	}
+	return n
+}
+
+// parseType parses a type as printed by types.TypeString.
+func (p *funcParser) parseType(s string) types.Type {
+	if t, ok := p.types[s]; ok {
+		return t
+	}
+	var t types.Type
+	if basic, ok := basicTypes[s]; ok {
+		t = basic
+	} else if strings.HasPrefix(s, "*") {
+		t = types.NewPointer(p.parseType(s[1:]))
+	} else if strings.HasPrefix(s, "(") && p.closing(s, 0) == len(s)-1 {
+		var vars []*types.Var
+		for _, el := range splitList(s[1 : len(s)-1]) {
+			vars = append(vars, p.parseVar(el))
+		}
+		t = types.NewTuple(vars...)
+	} else {
+		t = &parsedType{name: s}
+	}
+	p.types[s] = t
+	return t
+}
+
+// parseVar parses an element of a tuple, which may be named.
+func (p *funcParser) parseVar(s string) *types.Var {
+	name, typ, ok := strings.Cut(s, " ")
+	if !ok || !token.IsIdentifier(name) || name == "untyped" {
+		name, typ = "", s
+	}
+	return types.NewVar(token.NoPos, nil, name, p.parseType(typ))
+}
+
+// closing returns the index of the bracket that closes the one at
+// s[open]. Angle brackets only nest with each other, and the arrows of
+// channel types aren't brackets.
+func (p *funcParser) closing(s string, open int) int {
+	angle := s[open] == '<'
+	depth := 0
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			i = skipQuoted(s, i)
+		case angle && c == '<' && !strings.HasPrefix(s[i:], "<-"):
+			depth++
+		case angle && c == '>':
+			depth--
+		case !angle && (c == '(' || c == '[' || c == '{'):
+			depth++
+		case !angle && (c == ')' || c == ']' || c == '}'):
+			depth--
+		}
+		if depth == 0 {
+			return i
+		}
+	}
+	p.errorf("unbalanced %q in %q", s[open], s)
+	panic("unreachable")
+}
+
+// skipQuoted returns the index of the quote that ends the quoted
+// string starting at s[i].
+func skipQuoted(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return i
+}
+
+// splitList splits a comma-separated list, ignoring commas inside
+// brackets and quotes.
+func splitList(s string) []string {
+	var out []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			i = skipQuoted(s, i)
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
+
+// instrScanner splits the text of an instruction into its parts.
+type instrScanner struct {
+	p *funcParser
+	s string
+}
+
+func (sc *instrScanner) skipSpace() {
+	sc.s = strings.TrimLeft(sc.s, " ")
+}
+
+func (sc *instrScanner) done() bool {
+	sc.skipSpace()
+	return sc.s == ""
+}
+
+// word returns the next space-separated word.
+func (sc *instrScanner) word() string {
+	sc.skipSpace()
+	i := strings.IndexByte(sc.s, ' ')
+	if i == -1 {
+		i = len(sc.s)
+	}
+	w := sc.s[:i]
+	sc.s = sc.s[i:]
+	return w
+}
+
+// delimited returns the text between the opening delimiter open,
+// which must come next, and its closing delimiter.
+func (sc *instrScanner) delimited(open byte) string {
+	sc.skipSpace()
+	if sc.s == "" || sc.s[0] != open {
+		sc.p.errorf("expected %q, found %q", open, sc.s)
+	}
+	end := sc.p.closing(sc.s, 0)
+	inner := sc.s[1:end]
+	sc.s = sc.s[end+1:]
+	return inner
+}
+
+func (sc *instrScanner) typ() types.Type {
+	return sc.p.parseType(sc.delimited('<'))
+}
+
+func (sc *instrScanner) int() int {
+	n, err := strconv.Atoi(sc.delimited('['))
+	if err != nil {
+		sc.p.errorf("%s", err)
+	}
+	return n
+}
+
+// operands returns the names of the remaining operands, up to the
+// list of successors, if any.
+func (sc *instrScanner) operands() []string {
+	var out []string
+	for !sc.done() && !strings.HasPrefix(sc.s, "→") {
+		out = append(out, sc.word())
+	}
+	return out
+}
+
+// succs parses the list of successors.
+func (sc *instrScanner) succs() []*BasicBlock {
+	sc.skipSpace()
+	if !strings.HasPrefix(sc.s, "→") {
+		sc.p.errorf("expected successors, found %q", sc.s)
+	}
+	sc.s = sc.s[len("→"):]
+	var out []*BasicBlock
+	for !sc.done() {
+		out = append(out, sc.p.block(sc.p.blockIndex(sc.word())))
+	}
+	return out
+}
+
+// operand arranges for *ptr to be set to the value called name.
+func (p *funcParser) operand(ptr *Value, name string) {
+	if name == "" {
+		p.errorf("missing operand")
+	}
+	if name != "<nil>" {
+		p.operands = append(p.operands, operandRef{ptr: ptr, name: name})
+	}
+}
+
+// address is like operand, but for values used as addresses.
+func (p *funcParser) address(ptr *Value, name string) {
+	if name == "" {
+		p.errorf("missing operand")
+	}
+	p.operands = append(p.operands, operandRef{ptr: ptr, name: name, addr: true})
+}
+
+func (p *funcParser) operandList(names []string) []Value {
+	vals := make([]Value, len(names))
+	for i, name := range names {
+		p.operand(&vals[i], name)
+	}
+	return vals
+}
+
+func (p *funcParser) parseCall(sc *instrScanner, call *CallCommon, invoke bool) {
+	ops := sc.operands()
+	if len(ops) == 0 {
+		p.errorf("call without callee")
+	}
+	if invoke {
+		i := strings.LastIndexByte(ops[0], '.')
+		if i == -1 {
+			p.errorf("malformed method call %q", ops[0])
+		}
+		p.operand(&call.Value, ops[0][:i])
+		call.Method = types.NewFunc(token.NoPos, nil, ops[0][i+1:], new(types.Signature))
+	} else {
+		p.operand(&call.Value, ops[0])
+	}
+	call.Args = p.operandList(ops[1:])
+}
+
+func (p *funcParser) parseConst(s string) constant.Value {
+	switch {
+	case s == "nil":
+		return nil
+	case s == "true" || s == "false":
+		return constant.MakeBool(s == "true")
+	case strings.HasPrefix(s, `"`):
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			p.errorf("malformed string constant %s: %s", s, err)
+		}
+		return constant.MakeString(str)
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, "i)"):
+		// A complex number, printed as (re + imi).
+		re, im, ok := strings.Cut(s[1:len(s)-len("i)")], " + ")
+		if !ok {
+			p.errorf("malformed complex constant %s", s)
+		}
+		return constant.BinaryOp(p.parseConst(re), token.ADD, constant.MakeImag(p.parseConst(im)))
+	}
+	lit := strings.TrimPrefix(s, "-")
+	kind := token.INT
+	if strings.ContainsAny(lit, ".eE") {
+		kind = token.FLOAT
+	}
+	v := constant.MakeFromLiteral(lit, kind, 0)
+	if v.Kind() == constant.Unknown {
+		p.errorf("malformed constant %s", s)
+	}
+	if lit != s {
+		v = constant.UnaryOp(token.SUB, v, 0)
+	}
+	return v
+}
+
+func (p *funcParser) parseInstruction(b *BasicBlock, line string) {
+	if line == "<deleted>" {
+		p.errorf("deleted instruction")
+	}
+	if strings.HasPrefix(line, "; ") {
+		// DebugRefs refer to the source code, which we don't have.
+		return
+	}
+
+	// Split off the comment, which isn't necessarily the last one in
+	// the line, as Jump prints its own comment as well.
+	var comment string
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			i = skipQuoted(line, i)
+		} else if strings.HasPrefix(line[i:], " # ") {
+			line, comment = line[:i], line[i+len(" # "):]
+			comment, _, _ = strings.Cut(comment, " # ")
+			break
+		}
+	}
+
+	var name string
+	if i := strings.Index(line, " = "); i != -1 && strings.HasPrefix(line, "t") && !strings.Contains(line[:i], " ") {
+		name, line = line[:i], line[i+len(" = "):]
+	}
+
+	sc := &instrScanner{p: p, s: line}
+	var instr Instruction
+	var succs []*BasicBlock
+	switch op := sc.word(); op {
+	case "Const":
+		typ := sc.typ()
+		instr = NewConst(p.parseConst(sc.delimited('{')), typ, nil)
+	case "AggregateConst":
+		v := &AggregateConst{register: register{typ: sc.typ()}}
+		names := splitList(sc.delimited('('))
+		v.Values = make([]Value, len(names))
+		for i, name := range names {
+			if name != "nil" {
+				p.operand(&v.Values[i], name)
+			}
+		}
+		instr = v
+	case "ArrayConst":
+		instr = &ArrayConst{register: register{typ: sc.typ()}}
+	case "GenericConst":
+		instr = &GenericConst{register: register{typ: sc.typ()}}
+	case "Parameter":
+		v := &Parameter{register: register{typ: sc.typ()}}
+		v.name = sc.delimited('{')
+		p.fn.Params = append(p.fn.Params, v)
+		instr = v
+	case "StackAlloc", "HeapAlloc":
+		instr = &Alloc{register: register{typ: sc.typ()}, Heap: op == "HeapAlloc"}
+	case "Sigma":
+		v := &Sigma{register: register{typ: sc.typ()}}
+		v.From = p.block(p.blockIndex(sc.delimited('[')))
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Phi":
+		v := &Phi{register: register{typ: sc.typ()}}
+		edges := sc.operands()
+		v.Edges = make([]Value, len(edges))
+		for i, edge := range edges {
+			_, edge, ok := strings.Cut(edge, ":")
+			if !ok {
+				p.errorf("malformed φ-edge %q", edge)
+			}
+			p.operand(&v.Edges[i], edge)
+		}
+		instr = v
+	case "Call", "CallInvoke":
+		v := &Call{register: register{typ: sc.typ()}}
+		p.parseCall(sc, &v.Call, op == "CallInvoke")
+		instr = v
+	case "Go", "GoInvoke":
+		v := &Go{}
+		p.parseCall(sc, &v.Call, op == "GoInvoke")
+		instr = v
+	case "Defer":
+		// Defer is printed as "Defer [stack]  fn args" or as
+		// "Defer [stack] Invoke recv.method args".
+		v := &Defer{}
+		if strings.HasPrefix(strings.TrimLeft(sc.s, " "), "[") {
+			p.operand(&v._DeferStack, sc.delimited('['))
+		}
+		invoke := strings.HasPrefix(sc.s, " Invoke ")
+		if invoke {
+			sc.word()
+		}
+		p.parseCall(sc, &v.Call, invoke)
+		instr = v
+	case "BinOp":
+		v := &BinOp{register: register{typ: sc.typ()}}
+		v.Op = p.token(sc.delimited('{'))
+		p.operand(&v.X, sc.word())
+		p.operand(&v.Y, sc.word())
+		instr = v
+	case "UnOp":
+		v := &UnOp{register: register{typ: sc.typ()}}
+		v.Op = p.token(sc.delimited('{'))
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Load":
+		v := &Load{register: register{typ: sc.typ()}}
+		p.address(&v.X, sc.word())
+		instr = v
+	case "Copy":
+		v := &Copy{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "ChangeType":
+		v := &ChangeType{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Convert":
+		v := &Convert{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "ChangeInterface":
+		v := &ChangeInterface{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "SliceToArrayPointer":
+		v := &SliceToArrayPointer{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "SliceToArray":
+		v := &SliceToArray{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "MakeInterface":
+		v := &MakeInterface{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "MultiConvert":
+		v := &MultiConvert{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		v.from, v.to = p.parseConversions(sc.delimited('['))
+		instr = v
+	case "MakeClosure":
+		v := &MakeClosure{register: register{typ: sc.typ()}}
+		ops := sc.operands()
+		if len(ops) == 0 {
+			p.errorf("closure without function")
+		}
+		p.operand(&v.Fn, ops[0])
+		if len(ops) > 1 {
+			v.Bindings = p.operandList(ops[1:])
+		}
+		instr = v
+	case "MakeSlice":
+		v := &MakeSlice{register: register{typ: sc.typ()}}
+		p.operand(&v.Len, sc.word())
+		p.operand(&v.Cap, sc.word())
+		instr = v
+	case "Slice":
+		v := &Slice{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		p.operand(&v.Low, sc.word())
+		p.operand(&v.High, sc.word())
+		p.operand(&v.Max, sc.word())
+		instr = v
+	case "MakeMap":
+		v := &MakeMap{register: register{typ: sc.typ()}}
+		if !sc.done() {
+			p.operand(&v.Reserve, sc.word())
+		}
+		instr = v
+	case "MakeChan":
+		v := &MakeChan{register: register{typ: sc.typ()}}
+		p.operand(&v.Size, sc.word())
+		instr = v
+	case "FieldAddr":
+		v := &FieldAddr{register: register{typ: sc.typ()}}
+		v.Field = sc.int()
+		field := sc.delimited('(')
+		p.address(&v.X, sc.word())
+		p.fields = append(p.fields, fieldRef{x: &v.X, deref: true, index: v.Field, name: field})
+		instr = v
+	case "Field":
+		v := &Field{register: register{typ: sc.typ()}}
+		v.Field = sc.int()
+		field := sc.delimited('(')
+		p.operand(&v.X, sc.word())
+		p.fields = append(p.fields, fieldRef{x: &v.X, index: v.Field, name: field})
+		instr = v
+	case "IndexAddr":
+		v := &IndexAddr{register: register{typ: sc.typ()}}
+		p.address(&v.X, sc.word())
+		p.operand(&v.Index, sc.word())
+		instr = v
+	case "Index":
+		v := &Index{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		p.operand(&v.Index, sc.word())
+		instr = v
+	case "MapLookup":
+		v := &MapLookup{register: register{typ: sc.typ()}}
+		_, v.CommaOk = v.typ.(*types.Tuple)
+		p.operand(&v.X, sc.word())
+		p.operand(&v.Index, sc.word())
+		instr = v
+	case "StringLookup":
+		v := &StringLookup{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		p.operand(&v.Index, sc.word())
+		instr = v
+	case "Range":
+		v := &Range{register: register{typ: sc.typ()}}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Next":
+		v := &Next{register: register{typ: sc.typ()}}
+		p.operand(&v.Iter, sc.word())
+		instr = v
+	case "TypeAssert":
+		v := &TypeAssert{register: register{typ: sc.typ()}}
+		v.AssertedType = v.typ
+		if tuple, ok := v.typ.(*types.Tuple); ok && tuple.Len() == 2 {
+			v.AssertedType = tuple.At(0).Type()
+			v.CommaOk = true
+		}
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Extract":
+		v := &Extract{register: register{typ: sc.typ()}}
+		v.Index = sc.int()
+		sc.delimited('(')
+		p.operand(&v.Tuple, sc.word())
+		instr = v
+	case "CompositeValue":
+		v := &CompositeValue{register: register{typ: sc.typ()}}
+		bits := sc.delimited('[')
+		v.Values = p.operandList(sc.operands())
+		switch bits {
+		case "all":
+			v.NumSet = len(v.Values)
+			for i := range v.Values {
+				v.Bitmap.SetBit(&v.Bitmap, i, 1)
+			}
+		case "none":
+		default:
+			for i, c := range bits {
+				if c == '1' {
+					v.Bitmap.SetBit(&v.Bitmap, i, 1)
+					v.NumSet++
+				}
+			}
+		}
+		instr = v
+	case "TypeSwitch":
+		v := &TypeSwitch{register: register{typ: sc.typ()}}
+		p.operand(&v.Tag, sc.word())
+		for !sc.done() {
+			cond, err := strconv.Unquote(sc.word())
+			if err != nil {
+				p.errorf("malformed type switch case: %s", err)
+			}
+			v.Conds = append(v.Conds, p.parseType(cond))
+		}
+		instr = v
+	case "SelectBlocking", "SelectNonBlocking":
+		v := &Select{register: register{typ: sc.typ()}, Blocking: op == "SelectBlocking"}
+		for _, state := range splitList(sc.delimited('[')) {
+			st := &SelectState{}
+			if recv, ok := strings.CutPrefix(state, "<-"); ok {
+				st.Dir = types.RecvOnly
+				p.operand(&st.Chan, recv)
+			} else if ch, x, ok := strings.Cut(state, "<-"); ok {
+				st.Dir = types.SendOnly
+				p.operand(&st.Chan, ch)
+				p.operand(&st.Send, x)
+			} else {
+				p.errorf("malformed select state %q", state)
+			}
+			v.States = append(v.States, st)
+		}
+		instr = v
+	case "Recv":
+		v := &Recv{register: register{typ: sc.typ()}}
+		_, v.CommaOk = v.typ.(*types.Tuple)
+		p.operand(&v.Chan, sc.word())
+		instr = v
+	case "Jump":
+		instr = &Jump{}
+		succs = sc.succs()
+	case "Unreachable":
+		instr = &Unreachable{}
+		succs = sc.succs()
+	case "If":
+		v := &If{}
+		p.operand(&v.Cond, sc.word())
+		instr = v
+		succs = sc.succs()
+	case "ConstantSwitch":
+		v := &ConstantSwitch{}
+		ops := sc.operands()
+		if len(ops) == 0 {
+			p.errorf("switch without tag")
+		}
+		p.operand(&v.Tag, ops[0])
+		v.Conds = p.operandList(ops[1:])
+		instr = v
+		succs = sc.succs()
+	case "Panic":
+		v := &Panic{}
+		p.operand(&v.X, sc.word())
+		instr = v
+		succs = sc.succs()
+	case "Return":
+		v := &Return{}
+		v.Results = p.operandList(sc.operands())
+		instr = v
+	case "RunDefers":
+		instr = &RunDefers{}
+	case "Send":
+		v := &Send{}
+		p.operand(&v.Chan, sc.word())
+		p.operand(&v.X, sc.word())
+		instr = v
+	case "Store":
+		v := &Store{}
+		// The type of the stored value is printed in braces.
+		sc.delimited('{')
+		p.address(&v.Addr, sc.word())
+		p.operand(&v.Val, sc.word())
+		instr = v
+	case "Atomic":
+		v := &Atomic{register: register{typ: sc.typ()}}
+		v.Op = p.atomicOp(sc.delimited('{'))
+		p.address(&v.Addr, sc.word())
+		v.Args = p.operandList(sc.operands())
+		instr = v
+	case "BlankStore":
+		v := &BlankStore{}
+		p.operand(&v.Val, sc.word())
+		instr = v
+	case "MapUpdate":
+		v := &MapUpdate{}
+		p.operand(&v.Map, sc.word())
+		p.operand(&v.Key, sc.word())
+		p.operand(&v.Value, sc.word())
+		instr = v
+	default:
+		p.errorf("unknown instruction %q", op)
+	}
+	if !sc.done() {
+		p.errorf("unexpected %q at end of instruction", sc.s)
+	}
+
+	if v, ok := instr.(Value); ok {
+		id, err := strconv.Atoi(strings.TrimPrefix(name, "t"))
+		if err != nil || name == "" {
+			p.errorf("value without name")
+		}
+		if _, ok := p.values[name]; ok {
+			p.errorf("%s defined twice", name)
+		}
+		p.values[name] = v
+		instr.setID(ID(id))
+	} else if name != "" {
+		p.errorf("instruction %s doesn't define a value", line)
+	}
+	instr.setBlock(b)
+	if comment != "" {
+		instr.(interface{ setComment(string) }).setComment(comment)
+	}
+	b.Instrs = append(b.Instrs, instr)
+	if succs != nil {
+		if b.Succs != nil {
+			p.errorf("b%d has multiple control instructions", b.Index)
+		}
+		b.Succs = succs
+	}
+}
+
+func (p *funcParser) token(s string) token.Token {
+	tok, ok := operatorTokens[s]
+	if !ok {
+		p.errorf("unknown operator %q", s)
+	}
+	return tok
+}
+
+func (p *funcParser) atomicOp(s string) AtomicOp {
+	op, ok := atomicOps[s]
+	if !ok {
+		p.errorf("unknown atomic operation %q", s)
+	}
+	return op
+}
+
+// parseConversions parses the list of conversions of a MultiConvert,
+// which is the cross product of its type sets' terms.
+func (p *funcParser) parseConversions(s string) (from, to typeutil.TypeSet) {
+	term := func(ts *typeutil.TypeSet, seen map[string]bool, s string) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		t, tilde := strings.CutPrefix(s, "~")
+		ts.Terms = append(ts.Terms, types.NewTerm(tilde, p.parseType(t)))
+	}
+	seenFrom, seenTo := map[string]bool{}, map[string]bool{}
+	for _, conv := range strings.Split(s, " | ") {
+		src, dst, ok := strings.Cut(conv, " -> ")
+		if !ok {
+			p.errorf("malformed conversion %q", conv)
+		}
+		term(&from, seenFrom, src)
+		term(&to, seenTo, dst)
+	}
+	return from, to
+}
+
+// finish resolves operands and checks the function's structure.
+func (p *funcParser) finish() {
+	fn := p.fn
+	// Errors found from here on aren't specific to a line.
+	p.line = 0
+
+	for i := 0; i < len(p.blocks); i++ {
+		b, ok := p.blocks[i]
+		if !ok {
+			p.errorf("missing block b%d", i)
+		}
+		if b.parent == nil {
+			p.errorf("block b%d is referred to but not defined", i)
+		}
+		if b.Comment == "exit" {
+			fn.Exit = b
+		}
+		fn.Blocks = append(fn.Blocks, b)
+	}
+
+	for _, ref := range p.operands {
+		if v, ok := p.values[ref.name]; ok {
+			*ref.ptr = v
+		} else {
+			*ref.ptr = p.stub(ref.name, ref.addr)
+		}
+	}
+	for _, ref := range p.fields {
+		t := (*ref.x).Type()
+		if ptr, ok := t.(*types.Pointer); ok && ref.deref {
+			t = ptr.Elem()
+		}
+		if t, ok := t.(*parsedType); ok {
+			t.setField(ref.index, ref.name)
+		}
+	}
+	for _, name := range p.locals {
+		alloc, ok := p.values[name].(*Alloc)
+		if !ok {
+			p.errorf("local %s isn't an Alloc", name)
+		}
+		fn.Locals = append(fn.Locals, alloc)
+	}
+
+	if fn.parent != nil {
+		// Anonymous functions are printed with their index in their
+		// parent's AnonFuncs.
+		if i := strings.LastIndexByte(fn.name, '$'); i != -1 {
+			if n, err := strconv.Atoi(fn.name[i+1:]); err == nil && n > 0 {
+				fn.parent.AnonFuncs = make([]*Function, n)
+				fn.parent.AnonFuncs[n-1] = fn
+			}
+		}
+	}
+
+	var rands []*Value
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			rands = instr.Operands(rands[:0])
+			for _, rand := range rands {
+				if *rand == nil {
+					continue
+				}
+				if refs := (*rand).Referrers(); refs != nil {
+					*refs = append(*refs, instr)
+				}
+			}
+		}
+	}
+}
+
+// stub returns the stub for a function, global or builtin that the
+// function refers to.
+func (p *funcParser) stub(name string, addr bool) Value {
+	if v, ok := p.stubs[name]; ok {
+		return v
+	}
+	var v Value
+	if obj, ok := types.Universe.Lookup(name).(*types.Builtin); ok {
+		v = &Builtin{name: obj.Name(), sig: new(types.Signature)}
+	} else if addr {
+		v = &Global{name: name, typ: types.NewPointer(&parsedType{name: "?"}), Pkg: stubPackage}
+	} else {
+		v = &Function{name: name, Signature: new(types.Signature), Prog: p.fn.Prog}
+	}
+	p.stubs[name] = v
+	return v
+}
@@ -0,0 +1,256 @@
+package ir_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+)
+
+// withoutSourceInfo removes the lines of a function's textual
+// representation that ParseFunction doesn't reconstruct.
+func withoutSourceInfo(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# Name: ") || strings.HasPrefix(line, "# Location: ") || strings.HasPrefix(line, "\t; ") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestParseFunction(t *testing.T) {
+	const input = `
+package p
+
+type T struct {
+	a, b int
+	s    []string
+}
+
+type I interface{ M(int) error }
+
+type E struct{}
+
+func (E) Error() string { return "" }
+
+var global T
+
+func sink(...interface{})
+
+func f(t *T, i I, m map[string]int, ch chan int, x interface{}, s string) (ret int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret = -1
+		}
+	}()
+	global.a++
+	t.s = append(t.s, "a very long string that is abbreviated")
+	for k, v := range m {
+		if k == "" {
+			continue
+		}
+		ret += v * t.a
+	}
+	for i, r := range s {
+		ret += i + int(r) + int(s[i])
+	}
+	select {
+	case v := <-ch:
+		ret += v
+	case ch <- 1:
+	default:
+	}
+	switch x := x.(type) {
+	case int:
+		ret += x
+	case string, []byte:
+		sink(x)
+	}
+	if v, ok := m["k"]; ok {
+		m["k"] = v + 1
+	}
+	go sink(1.5, 2i, true, [2]int{1, 2}, T{b: 1}, (*[1]byte)([]byte(s)))
+	arr := [3]int{1, 2, 3}
+	sl := arr[1:2:3]
+	sink(sl[0], arr[2], t.s[1:], *t, (*T)(nil).b, E{})
+	if err := i.M(len(sl)); err != nil {
+		panic(err)
+	}
+	return ret, E{}
+}
+
+func g[T ~int | ~int8, U, V any](x T, u U, v ...V) int64 {
+	return int64(x)
+}
+`
+	pkg := buildPackage(t, input)
+	for _, name := range []string{"f", "g"} {
+		fn := pkg.Func(name)
+		fns := append([]*ir.Function{fn}, fn.AnonFuncs...)
+		for _, fn := range fns {
+			var buf bytes.Buffer
+			ir.WriteFunction(&buf, fn)
+			want := buf.String()
+
+			parsed, err := ir.ParseFunction(strings.NewReader(want))
+			if err != nil {
+				t.Fatalf("couldn't parse %s: %s\n%s", fn, err, want)
+			}
+
+			buf.Reset()
+			ir.WriteFunction(&buf, parsed)
+			if got, want := withoutSourceInfo(buf.String()), withoutSourceInfo(want); got != want {
+				t.Errorf("round trip of %s failed, got:\n%s\nwant:\n%s", fn, got, want)
+			}
+
+			checkParsedStructure(t, fn, parsed)
+		}
+	}
+}
+
+func TestParseAtomic(t *testing.T) {
+	fn := buildPackageWithMode(t, atomicInput, ir.AtomicOperations).Func("f")
+	var buf bytes.Buffer
+	ir.WriteFunction(&buf, fn)
+	want := buf.String()
+	if !strings.Contains(want, "Atomic <") {
+		t.Fatalf("%s contains no Atomic instructions:\n%s", fn, want)
+	}
+
+	parsed, err := ir.ParseFunction(strings.NewReader(want))
+	if err != nil {
+		t.Fatalf("couldn't parse %s: %s\n%s", fn, err, want)
+	}
+	buf.Reset()
+	ir.WriteFunction(&buf, parsed)
+	if got, want := withoutSourceInfo(buf.String()), withoutSourceInfo(want); got != want {
+		t.Errorf("round trip of %s failed, got:\n%s\nwant:\n%s", fn, got, want)
+	}
+
+	// checkParsedStructure can't be used here, as the stub of the
+	// method called by f doesn't know its unqualified name.
+	atomics := func(fn *ir.Function) []*ir.Atomic {
+		var out []*ir.Atomic
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if a, ok := instr.(*ir.Atomic); ok {
+					out = append(out, a)
+				}
+			}
+		}
+		return out
+	}
+	orig, got := atomics(fn), atomics(parsed)
+	if len(got) != len(orig) {
+		t.Fatalf("got %d Atomic instructions, want %d", len(got), len(orig))
+	}
+	for i, a := range got {
+		if a.Op != orig[i].Op || a.Name() != orig[i].Name() || len(a.Args) != len(orig[i].Args) {
+			t.Errorf("got %s = %s, want %s = %s", a.Name(), a, orig[i].Name(), orig[i])
+		}
+		if a.Addr.Name() != orig[i].Addr.Name() || !containsInstr(*a.Addr.Referrers(), a) {
+			t.Errorf("%s: address %s isn't wired up correctly", a, a.Addr.Name())
+		}
+	}
+}
+
+// checkParsedStructure checks that parsed has the same blocks and
+// instructions as fn, and that its values are wired up correctly.
+func checkParsedStructure(t *testing.T, fn, parsed *ir.Function) {
+	t.Helper()
+	if len(parsed.Blocks) != len(fn.Blocks) {
+		t.Fatalf("%s: got %d blocks, want %d", fn, len(parsed.Blocks), len(fn.Blocks))
+	}
+	if parsed.Exit == nil || parsed.Exit.Index != fn.Exit.Index {
+		t.Errorf("%s: got exit block %s, want %s", fn, parsed.Exit, fn.Exit)
+	}
+	if len(parsed.Params) != len(fn.Params) {
+		t.Errorf("%s: got %d parameters, want %d", fn, len(parsed.Params), len(fn.Params))
+	}
+	for i, b := range fn.Blocks {
+		pb := parsed.Blocks[i]
+		if pb.Index != i || pb.Parent() != parsed {
+			t.Errorf("%s: block %d has index %d and parent %s", fn, i, pb.Index, pb.Parent())
+		}
+		if len(pb.Succs) != len(b.Succs) {
+			t.Errorf("%s: %s has successors %s, want %s", fn, pb, pb.Succs, b.Succs)
+		}
+
+		var instrs []ir.Instruction
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*ir.DebugRef); !ok {
+				instrs = append(instrs, instr)
+			}
+		}
+		if len(pb.Instrs) != len(instrs) {
+			t.Errorf("%s: %s has %d instructions, want %d", fn, pb, len(pb.Instrs), len(instrs))
+			continue
+		}
+		for j, instr := range instrs {
+			pinstr := pb.Instrs[j]
+			if reflect.TypeOf(pinstr) != reflect.TypeOf(instr) {
+				t.Errorf("%s: got %T, want %T", fn, pinstr, instr)
+				continue
+			}
+			if pinstr.Block() != pb {
+				t.Errorf("%s: %s is in %s, want %s", fn, pinstr, pinstr.Block(), pb)
+			}
+			if v, ok := instr.(ir.Value); ok && v.Name() != pinstr.(ir.Value).Name() {
+				t.Errorf("%s: got value %s, want %s", fn, pinstr.(ir.Value).Name(), v.Name())
+			}
+			rands := instr.Operands(nil)
+			prands := pinstr.Operands(nil)
+			if len(prands) != len(rands) {
+				t.Errorf("%s: %s has %d operands, want %d", fn, pinstr, len(prands), len(rands))
+				continue
+			}
+			for k, prand := range prands {
+				if (*prand == nil) != (*rands[k] == nil) {
+					t.Errorf("%s: operand %d of %s is %v, want %v", fn, k, pinstr, *prand, *rands[k])
+					continue
+				}
+				if *prand == nil {
+					continue
+				}
+				if (*prand).Name() != (*rands[k]).Name() {
+					t.Errorf("%s: operand %d of %s is %s, want %s", fn, k, pinstr, (*prand).Name(), (*rands[k]).Name())
+				}
+				if refs := (*prand).Referrers(); refs != nil && !containsInstr(*refs, pinstr) {
+					t.Errorf("%s: %s isn't a referrer of %s", fn, pinstr, (*prand).Name())
+				}
+			}
+		}
+	}
+}
+
+func containsInstr(instrs []ir.Instruction, instr ir.Instruction) bool {
+	for _, other := range instrs {
+		if other == instr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseFunctionErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"func f():\nb0: # entry\n\tt1 = Frobnicate <int>\n", "line 3: unknown instruction \"Frobnicate\""},
+		{"func f():\nb0: # entry\n\tJump → b1\n", "block b1 is referred to but not defined"},
+		{"func f():\nb0: # entry\n\tt1 = BinOp <int> {+} t2\n", "line 3: missing operand"},
+		{"func f():\n\tReturn\n", "line 2: instruction outside of block"},
+	}
+	for _, tt := range tests {
+		_, err := ir.ParseFunction(strings.NewReader(tt.in))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("parsing %q: got error %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
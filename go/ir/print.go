@@ -443,6 +443,16 @@ func (s *Store) String() string {
 		s.Val.Type(), relName(s.Addr, s), relName(s.Val, s))
 }
 
+func (v *Atomic) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Atomic <%s> {%s} %s", relType(v.Type(), v.Parent().pkg()), v.Op, relName(v.Addr, v))
+	for _, arg := range v.Args {
+		b.WriteString(" ")
+		b.WriteString(relName(arg, v))
+	}
+	return b.String()
+}
+
 func (s *BlankStore) String() string {
 	return fmt.Sprintf("BlankStore %s", relName(s.Val, s))
 }
@@ -139,6 +139,7 @@ func (s *sanity) checkInstr(idx int, instr Instruction) {
 			}
 		}
 
+	case *Atomic:
 	case *BinOp:
 	case *Call:
 	case *ChangeInterface:
@@ -0,0 +1,149 @@
+package ir
+
+// This file implements scalar replacement of aggregates (SRA), which
+// splits struct-typed Allocs whose fields are only ever accessed
+// individually into one Alloc per field. Lifting can then replace
+// each field with registers on its own, whereas it can't lift an
+// Alloc whose address flows into FieldAddr instructions.
+
+import (
+	"go/types"
+)
+
+// splitAggregates splits local struct-typed Allocs that are only used
+// via FieldAddr instructions, which in turn are only loaded from and
+// stored to, into one Alloc per accessed field. The Allocs of fields
+// that are structs themselves are split recursively. Allocs that
+// escape, whose address is used for anything but accessing a field,
+// or that are loaded or stored as a whole, are left alone.
+//
+// Allocs and fields with debug references aren't split, either. The
+// references need the address of the variable or field, which
+// wouldn't exist anymore.
+//
+// Preconditions:
+// - Def/use info (Operands and Referrers) is up-to-date.
+func splitAggregates(fn *Function) {
+	var work []*Alloc
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if alloc, ok := instr.(*Alloc); ok && splittable(alloc) {
+				work = append(work, alloc)
+			}
+		}
+	}
+	if len(work) == 0 {
+		return
+	}
+
+	// replacements maps split Allocs to the Allocs of their fields.
+	replacements := map[Instruction][]Instruction{}
+	removed := map[Instruction]struct{}{}
+	for len(work) > 0 {
+		alloc := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		fields := splitAlloc(alloc, removed)
+		for _, field := range fields {
+			replacements[alloc] = append(replacements[alloc], field)
+			if splittable(field) {
+				work = append(work, field)
+			}
+		}
+	}
+
+	var expand func(dst []Instruction, instr Instruction) []Instruction
+	expand = func(dst []Instruction, instr Instruction) []Instruction {
+		if _, ok := removed[instr]; ok {
+			return dst
+		}
+		if fields, ok := replacements[instr]; ok {
+			for _, field := range fields {
+				dst = expand(dst, field)
+			}
+			return dst
+		}
+		return append(dst, instr)
+	}
+	for _, b := range fn.Blocks {
+		instrs := make([]Instruction, 0, len(b.Instrs))
+		for _, instr := range b.Instrs {
+			instrs = expand(instrs, instr)
+		}
+		b.Instrs = instrs
+	}
+
+	var locals []Instruction
+	for _, l := range fn.Locals {
+		locals = expand(locals, l)
+	}
+	fn.Locals = fn.Locals[:0]
+	for _, l := range locals {
+		fn.Locals = append(fn.Locals, l.(*Alloc))
+	}
+}
+
+// splittable reports whether alloc is a local struct whose address is
+// only used by FieldAddr instructions, and whose fields' addresses are
+// only loaded from, stored to, or used to access nested fields.
+func splittable(alloc *Alloc) bool {
+	if alloc.Heap {
+		return false
+	}
+	if _, ok := deref(alloc.Type()).Underlying().(*types.Struct); !ok {
+		return false
+	}
+	for _, ref := range alloc.referrers {
+		switch ref := ref.(type) {
+		case *FieldAddr:
+			for _, fref := range ref.referrers {
+				switch fref := fref.(type) {
+				case *Load:
+				case *Store:
+					if fref.Addr != ref || fref.Val == ref {
+						return false
+					}
+				case *FieldAddr:
+					// The nested struct is split recursively, if
+					// possible.
+				default:
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitAlloc replaces the FieldAddr instructions using alloc with new
+// Allocs, one per accessed field, and returns them in field order.
+// The replaced FieldAddrs are added to removed.
+func splitAlloc(alloc *Alloc, removed map[Instruction]struct{}) []*Alloc {
+	st := deref(alloc.Type()).Underlying().(*types.Struct)
+	fields := make([]*Alloc, st.NumFields())
+	for _, ref := range alloc.referrers {
+		ref := ref.(*FieldAddr)
+		field := fields[ref.Field]
+		if field == nil {
+			field = &Alloc{}
+			field.comment = alloc.comment + "." + st.Field(ref.Field).Name()
+			field.setType(ref.Type())
+			field.setBlock(alloc.block)
+			field.setSource(alloc.source)
+			fields[ref.Field] = field
+		}
+		replaceAll(ref, field)
+		removed[ref] = struct{}{}
+	}
+	alloc.referrers = nil
+
+	out := fields[:0]
+	for _, field := range fields {
+		if field != nil {
+			out = append(out, field)
+		}
+	}
+	return out
+}
@@ -60,6 +60,10 @@ type Package struct {
 	debug     bool                   // include full debug info in this package
 	printFunc string                 // which function to print in HTML form
 
+	callersOnce   sync.Once                       // ensures the callers index is computed once
+	staticCallers map[*Function][]CallInstruction // static call sites, keyed by callee
+	invokeSites   map[string][]CallInstruction    // invoke-mode call sites, keyed by method name
+
 	// The following fields are set transiently, then cleared
 	// after building.
 	buildOnce   sync.Once           // ensures package building occurs once
@@ -142,13 +146,19 @@ type Value interface {
 	// types of their operands.
 	Type() types.Type
 
+	// Comment returns an optional description of the value, such as
+	// the name of the variable a φ-node was created for. It is empty
+	// for values that aren't Instructions.
+	Comment() string
+
 	// Parent returns the function to which this Value belongs.
 	// It returns nil for named Functions, Builtin and Global.
 	Parent() *Function
 
 	// Referrers returns the list of instructions that have this
 	// value as one of their operands; it may contain duplicates
-	// if an instruction has a repeated operand.
+	// if an instruction has a repeated operand, unless they have
+	// been removed by DedupeReferrers.
 	//
 	// Referrers actually returns a pointer through which the
 	// caller may perform mutations to the object's state.
@@ -388,6 +398,29 @@ type Function struct {
 	referrers []Instruction // referring instructions (iff Parent() != nil)
 	NoReturn  NoReturn      // Calling this function will always terminate control flow.
 
+	hasLoops  bool // whether the CFG contains back edges; set by finishBody
+	recursive bool // whether the function can statically reach itself; set by Package.build
+
+	// fakeExits are blocks that are treated as predecessors of Exit
+	// for the purpose of computing post-dominance, such as infinite
+	// loops. They are kept after building for computing frontiers.
+	fakeExits BlockSet
+
+	// blocksetPool holds the sets returned by ReleaseBlockSet.
+	blocksetPool []*BlockSet
+
+	// lazily computed dominance frontiers, reset whenever the
+	// (post-)dominator tree is rebuilt
+	domFrontierOnce     sync.Once
+	domFrontier         BlockMap[[]*BasicBlock]
+	postDomFrontierOnce sync.Once
+	postDomFrontier     BlockMap[[]*BasicBlock]
+
+	// lazily computed reachability, reset whenever the dominator tree
+	// is rebuilt
+	reachabilityOnce sync.Once
+	reachability     *Reachability
+
 	goversion string // Go version of syntax (NB: init is special)
 
 	// uniq is not stored in functionBody because we need it after function building finishes
@@ -505,8 +538,7 @@ type functionBody struct {
 	aggregateConsts typeutil.Map[[]*AggregateConst]
 
 	wr        *HTMLWriter
-	fakeExits BlockSet
-	blocksets [5]BlockSet
+	blocksets [6]BlockSet
 	hasDefer  bool
 
 	// a contiguous block of instructions that will be used by blocks,
@@ -1573,6 +1605,66 @@ type Store struct {
 	Val  Value
 }
 
+// The Atomic instruction performs an atomic memory operation on the
+// value at address Addr, as done by the package-level functions of
+// sync/atomic. Atomic instructions are only emitted in the
+// AtomicOperations builder mode; otherwise, calls to sync/atomic
+// are ordinary Calls. Calls of methods, such as (*atomic.Int64).Add,
+// are always ordinary Calls.
+//
+// Args holds the remaining arguments of the operation: the new
+// value for AtomicStore and AtomicSwap, the delta for AtomicAdd, the
+// mask for AtomicAnd and AtomicOr, and the old and new values for
+// AtomicCompareAndSwap. The result is that of the corresponding
+// sync/atomic function.
+//
+// Pos() returns the ast.CallExpr.Lparen, if explicit in the source.
+//
+// Example printed form:
+//
+//	t3 = Atomic <int64> {Add} t1 t2
+//	t4 = Atomic <()> {Store} t1 t2
+type Atomic struct {
+	register
+	Op   AtomicOp
+	Addr Value
+	Args []Value
+}
+
+// AtomicOp is the operation performed by an Atomic instruction.
+type AtomicOp uint8
+
+const (
+	AtomicLoad AtomicOp = iota + 1
+	AtomicStore
+	AtomicAdd
+	AtomicSwap
+	AtomicCompareAndSwap
+	AtomicAnd
+	AtomicOr
+)
+
+func (op AtomicOp) String() string {
+	switch op {
+	case AtomicLoad:
+		return "Load"
+	case AtomicStore:
+		return "Store"
+	case AtomicAdd:
+		return "Add"
+	case AtomicSwap:
+		return "Swap"
+	case AtomicCompareAndSwap:
+		return "CompareAndSwap"
+	case AtomicAnd:
+		return "And"
+	case AtomicOr:
+		return "Or"
+	default:
+		return fmt.Sprintf("AtomicOp(%d)", op)
+	}
+}
+
 // The BlankStore instruction is emitted for assignments to the blank
 // identifier.
 //
@@ -1685,6 +1777,10 @@ func (instr anInstruction) Comment() string {
 	return instr.comment
 }
 
+func (instr *anInstruction) setComment(comment string) {
+	instr.comment = comment
+}
+
 // CallCommon is contained by Go, Defer and Call to hold the
 // common parts of a function or method call.
 //
@@ -2058,6 +2154,14 @@ func (v *MakeSlice) Operands(rands []*Value) []*Value {
 	return append(rands, &v.Len, &v.Cap)
 }
 
+func (v *Atomic) Operands(rands []*Value) []*Value {
+	rands = append(rands, &v.Addr)
+	for i := range v.Args {
+		rands = append(rands, &v.Args[i])
+	}
+	return rands
+}
+
 func (v *MapUpdate) Operands(rands []*Value) []*Value {
 	return append(rands, &v.Map, &v.Key, &v.Value)
 }
@@ -2150,6 +2254,11 @@ func (v *CompositeValue) Operands(rands []*Value) []*Value {
 }
 
 // Non-Instruction Values:
+func (v *Builtin) Comment() string  { return "" }
+func (v *FreeVar) Comment() string  { return "" }
+func (v *Function) Comment() string { return "" }
+func (v *Global) Comment() string   { return "" }
+
 func (v *Builtin) Operands(rands []*Value) []*Value      { return rands }
 func (v *FreeVar) Operands(rands []*Value) []*Value      { return rands }
 func (v *Const) Operands(rands []*Value) []*Value        { return rands }
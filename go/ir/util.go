@@ -150,6 +150,25 @@ func assert(x bool) {
 // BlockMap is a mapping from basic blocks (identified by their indices) to values.
 type BlockMap[T any] []T
 
+// Get returns the value for block b. It returns the zero value if b's
+// index is out of range, such as for blocks that were added after the
+// map was created.
+func (m BlockMap[T]) Get(b *BasicBlock) T {
+	if b.Index < 0 || b.Index >= len(m) {
+		var zero T
+		return zero
+	}
+	return m[b.Index]
+}
+
+// ForEach calls fn for each block index and its value, in order of
+// increasing index.
+func (m BlockMap[T]) ForEach(fn func(idx int, v T)) {
+	for i, v := range m {
+		fn(i, v)
+	}
+}
+
 // isBasic reports whether t is a basic type.
 func isBasic(t types.Type) bool {
 	_, ok := t.(*types.Basic)
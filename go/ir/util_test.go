@@ -0,0 +1,37 @@
+package ir_test
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/go/ir"
+)
+
+func TestBlockMap(t *testing.T) {
+	m := ir.BlockMap[string]{"a", "b", "c"}
+
+	var got []string
+	m.ForEach(func(idx int, v string) {
+		if m[idx] != v {
+			t.Errorf("ForEach passed %q for index %d, want %q", v, idx, m[idx])
+		}
+		got = append(got, v)
+	})
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("ForEach visited %q, want [a b c]", got)
+	}
+
+	for _, tt := range []struct {
+		index int
+		want  string
+	}{
+		{0, "a"},
+		{2, "c"},
+		{3, ""},
+		{-1, ""},
+	} {
+		b := &ir.BasicBlock{Index: tt.index}
+		if v := m.Get(b); v != tt.want {
+			t.Errorf("Get(block %d) = %q, want %q", tt.index, v, tt.want)
+		}
+	}
+}
@@ -36,6 +36,10 @@ var Analyzer = &analysis.Analyzer{
 
 // IR provides intermediate representation for all the
 // source functions in the current package.
+//
+// The IR of a package is built once and shared by all analyzers that
+// require Analyzer. It must be treated as read-only; analyzers that
+// want to transform the IR have to build their own copy.
 type IR struct {
 	Pkg      *ir.Package
 	SrcFuncs []*ir.Function
@@ -58,7 +58,8 @@ type Command struct {
 		listChecks   bool
 		merge        bool
 
-		matrix bool
+		matrix          bool
+		continueOnPanic bool
 
 		debugCpuprofile       string
 		debugMemprofile       string
@@ -148,6 +149,7 @@ func (cmd *Command) initFlagSet(name string) {
 	flags.BoolVar(&cmd.flags.listChecks, "list-checks", false, "List all available checks")
 	flags.BoolVar(&cmd.flags.merge, "merge", false, "Merge results of multiple Staticcheck runs")
 	flags.BoolVar(&cmd.flags.matrix, "matrix", false, "Read a build config matrix from stdin")
+	flags.BoolVar(&cmd.flags.continueOnPanic, "continue-on-panic", false, "Keep running when a check panics, instead of stopping at the first panic")
 
 	flags.StringVar(&cmd.flags.debugCpuprofile, "debug.cpuprofile", "", "Write CPU profile to `file`")
 	flags.StringVar(&cmd.flags.debugMemprofile, "debug.memprofile", "", "Write memory profile to `file`")
@@ -481,10 +483,11 @@ func (cmd *Command) lint() int {
 	var runs []run
 	cs := cmd.analyzersAsSlice()
 	opts := options{
-		analyzers: cs,
-		patterns:  cmd.flags.fs.Args(),
-		lintTests: cmd.flags.tests,
-		goVersion: string(cmd.flags.goVersion),
+		analyzers:       cs,
+		patterns:        cmd.flags.fs.Args(),
+		lintTests:       cmd.flags.tests,
+		goVersion:       string(cmd.flags.goVersion),
+		continueOnPanic: cmd.flags.continueOnPanic,
 		config: config.Config{
 			Checks: cmd.flags.checks,
 		},
@@ -686,6 +689,7 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 	shouldExit := filterAnalyzerNames(analyzerNames, fail)
 	shouldExit["staticcheck"] = true
 	shouldExit["compile"] = true
+	shouldExit["crash"] = true
 
 	var (
 		numErrors   int
@@ -83,6 +83,7 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 		jp := struct {
 			Code     string    `json:"code"`
 			Severity string    `json:"severity,omitempty"`
+			Level    string    `json:"level,omitempty"`
 			Location location  `json:"location"`
 			End      location  `json:"end"`
 			Message  string    `json:"message"`
@@ -90,6 +91,7 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 		}{
 			Code:     p.Category,
 			Severity: p.Severity.String(),
+			Level:    p.Diagnostic.Severity.String(),
 			Location: location{
 				File:   p.Position.Filename,
 				Line:   p.Position.Line,
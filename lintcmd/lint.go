@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -94,6 +95,7 @@ type options struct {
 	patterns                 []string
 	lintTests                bool
 	goVersion                string
+	continueOnPanic          bool
 	printAnalyzerMeasurement func(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration)
 }
 
@@ -110,7 +112,9 @@ func (l *linter) run(bconf buildConfig) (lintResult, error) {
 	if err != nil {
 		return lintResult{}, err
 	}
+	defer r.Close()
 	r.GoVersion = l.opts.goVersion
+	r.ContinueOnPanic = l.opts.continueOnPanic
 	r.Stats.PrintAnalyzerMeasurement = l.opts.printAnalyzerMeasurement
 
 	printStats := func() {
@@ -184,6 +188,9 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 		if len(res.Errors) > 0 && !res.Failed {
 			panic("package has errors but isn't marked as failed")
 		}
+		for _, p := range res.Panics {
+			out.Diagnostics = append(out.Diagnostics, crashed(p))
+		}
 		if res.Failed {
 			out.Diagnostics = append(out.Diagnostics, failed(res)...)
 		} else {
@@ -208,13 +215,8 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 				return out, err
 			}
 			// OPT move this code into the 'success' function.
-			for i, diag := range filtered {
-				a := l.analyzers[diag.Category]
-				// Some diag.Category don't map to analyzers, such as "staticcheck"
-				if a != nil {
-					filtered[i].MergeIf = a.Doc.MergeIf
-				}
-			}
+			applyAnalyzerDocs(filtered, l.analyzers)
+			filtered = filterYielded(filtered, l.analyzers)
 			out.Diagnostics = append(out.Diagnostics, filtered...)
 
 			for _, obj := range resd.Unused.Used {
@@ -319,6 +321,62 @@ func filterIgnored(diagnostics []diagnostic, res runner.ResultData, allowedAnaly
 	return append(diagnostics, moreDiagnostics...), nil
 }
 
+// filterYielded drops diagnostics of analyzers that yield to other
+// analyzers if one of those analyzers reported a diagnostic with an
+// overlapping position range. Ignored diagnostics don't cause other
+// diagnostics to be dropped.
+func filterYielded(diagnostics []diagnostic, analyzers map[string]*lint.Analyzer) []diagnostic {
+	byCategory := map[string][]diagnostic{}
+	for _, diag := range diagnostics {
+		if diag.Severity != severityIgnored {
+			byCategory[diag.Category] = append(byCategory[diag.Category], diag)
+		}
+	}
+
+	yields := func(diag diagnostic) bool {
+		a := analyzers[diag.Category]
+		if a == nil {
+			return false
+		}
+		for _, other := range a.Doc.YieldsTo {
+			for _, odiag := range byCategory[other] {
+				if overlaps(diag, odiag) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	out := diagnostics[:0]
+	for _, diag := range diagnostics {
+		if !yields(diag) {
+			out = append(out, diag)
+		}
+	}
+	return out
+}
+
+// overlaps reports whether the position ranges of two diagnostics
+// overlap. Diagnostics without an end are treated as covering a single
+// position.
+func overlaps(a, b diagnostic) bool {
+	if a.Position.Filename != b.Position.Filename {
+		return false
+	}
+	span := func(diag diagnostic) (int, int) {
+		start := diag.Position.Offset
+		end := diag.End.Offset
+		if !diag.End.IsValid() || end < start {
+			end = start
+		}
+		return start, end
+	}
+	as, ae := span(a)
+	bs, be := span(b)
+	return as <= be && bs <= ae
+}
+
 type ignore interface {
 	match(diag diagnostic) bool
 }
@@ -401,21 +459,49 @@ type diagnostic struct {
 	BuildName string
 }
 
+// applyAnalyzerDocs copies properties from the documentation of the
+// analyzers that reported diagnostics to the diagnostics.
+func applyAnalyzerDocs(diags []diagnostic, analyzers map[string]*lint.Analyzer) {
+	for i, diag := range diags {
+		a := analyzers[diag.Category]
+		// Some diag.Category don't map to analyzers, such as "staticcheck"
+		if a == nil {
+			continue
+		}
+		diags[i].MergeIf = a.Doc.MergeIf
+		if a.Doc.SafeFixes && len(diag.SuggestedFixes) > 0 {
+			// Don't modify the fixes in place, they may be shared
+			// with cached results.
+			fixes := slices.Clone(diag.SuggestedFixes)
+			for j := range fixes {
+				fixes[j].Safe = true
+			}
+			diags[i].SuggestedFixes = fixes
+		}
+	}
+}
+
 func (p diagnostic) equal(o diagnostic) bool {
 	return p.Position == o.Position &&
 		p.End == o.End &&
 		p.Message == o.Message &&
 		p.Category == o.Category &&
 		p.Severity == o.Severity &&
+		p.Diagnostic.Severity == o.Diagnostic.Severity &&
 		p.MergeIf == o.MergeIf &&
 		p.BuildName == o.BuildName
 }
 
 func (p *diagnostic) String() string {
+	cat := p.Category
+	if p.Diagnostic.Severity != lint.SeverityNone {
+		// The diagnostic overrides the severity of its analyzer.
+		cat += ", " + p.Diagnostic.Severity.String()
+	}
 	if p.BuildName != "" {
-		return fmt.Sprintf("%s [%s] (%s)", p.Message, p.BuildName, p.Category)
+		return fmt.Sprintf("%s [%s] (%s)", p.Message, p.BuildName, cat)
 	} else {
-		return fmt.Sprintf("%s (%s)", p.Message, p.Category)
+		return fmt.Sprintf("%s (%s)", p.Message, cat)
 	}
 }
 
@@ -424,6 +510,16 @@ func failed(res runner.Result) []diagnostic {
 
 	for _, e := range res.Errors {
 		switch e := e.(type) {
+		case *runner.AnalyzerPanic:
+			diagnostics = append(diagnostics, crashed(e))
+		case *runner.AbortedError:
+			diagnostics = append(diagnostics, diagnostic{
+				Diagnostic: runner.Diagnostic{
+					Message:  e.Error(),
+					Category: "crash",
+				},
+				Severity: severityError,
+			})
 		case packages.Error:
 			msg := e.Msg
 			if len(msg) != 0 && msg[0] == '\n' {
@@ -484,6 +580,20 @@ func failed(res runner.Result) []diagnostic {
 	return diagnostics
 }
 
+// crashed returns a diagnostic for an analyzer that panicked, and
+// prints the panic's stack trace to stderr.
+func crashed(p *runner.AnalyzerPanic) diagnostic {
+	fmt.Fprintf(os.Stderr, "%s\n\n%s\n", p.Error(), p.Stack)
+	return diagnostic{
+		Diagnostic: runner.Diagnostic{
+			Position: p.Position,
+			Message:  p.Error(),
+			Category: "crash",
+		},
+		Severity: severityError,
+	}
+}
+
 type unusedKey struct {
 	pkgPath string
 	base    string
@@ -0,0 +1,152 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/staticcheck/sa1012"
+	"honnef.co/go/tools/stylecheck/st1013"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestFilterYielded(t *testing.T) {
+	analyzers := map[string]*lint.Analyzer{
+		"S1000": {
+			Analyzer: &analysis.Analyzer{Name: "S1000"},
+			Doc:      &lint.RawDocumentation{YieldsTo: []string{"SA1000"}},
+		},
+		"SA1000": {
+			Analyzer: &analysis.Analyzer{Name: "SA1000"},
+			Doc:      &lint.RawDocumentation{},
+		},
+	}
+
+	diag := func(cat string, start, end int) diagnostic {
+		return diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: "file.go", Offset: start, Line: 1, Column: start + 1},
+				End:      token.Position{Filename: "file.go", Offset: end, Line: 1, Column: end + 1},
+				Category: cat,
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		in   []diagnostic
+		want []string
+	}{
+		{
+			"same node",
+			[]diagnostic{diag("S1000", 10, 20), diag("SA1000", 10, 20)},
+			[]string{"SA1000"},
+		},
+		{
+			"overlapping",
+			[]diagnostic{diag("SA1000", 15, 30), diag("S1000", 10, 20)},
+			[]string{"SA1000"},
+		},
+		{
+			"disjoint",
+			[]diagnostic{diag("S1000", 10, 20), diag("SA1000", 30, 40)},
+			[]string{"S1000", "SA1000"},
+		},
+		{
+			"analyzer without YieldsTo",
+			[]diagnostic{diag("SA1000", 10, 20), diag("SA1000", 10, 20)},
+			[]string{"SA1000", "SA1000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filterYielded(tt.in, analyzers)
+			var got []string
+			for _, d := range out {
+				got = append(got, d.Category)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	ignored := diag("SA1000", 10, 20)
+	ignored.Severity = severityIgnored
+	if out := filterYielded([]diagnostic{diag("S1000", 10, 20), ignored}, analyzers); len(out) != 2 {
+		t.Errorf("ignored diagnostic caused another diagnostic to be dropped")
+	}
+}
+
+func TestApplyAnalyzerDocs(t *testing.T) {
+	analyzers := map[string]*lint.Analyzer{
+		sa1012.SCAnalyzer.Analyzer.Name: sa1012.SCAnalyzer,
+		st1013.SCAnalyzer.Analyzer.Name: st1013.SCAnalyzer,
+	}
+
+	fixes := []runner.SuggestedFix{{Message: "fix"}}
+	diags := []diagnostic{
+		{Diagnostic: runner.Diagnostic{Category: "SA1012", SuggestedFixes: fixes}},
+		{Diagnostic: runner.Diagnostic{Category: "ST1013", SuggestedFixes: fixes}},
+		{Diagnostic: runner.Diagnostic{Category: "compile", SuggestedFixes: fixes}},
+	}
+	applyAnalyzerDocs(diags, analyzers)
+
+	for i, want := range []bool{false, true, false} {
+		if got := diags[i].SuggestedFixes[0].Safe; got != want {
+			t.Errorf("%s: got Safe = %t, want %t", diags[i].Category, got, want)
+		}
+	}
+	if fixes[0].Safe {
+		t.Errorf("original fixes were modified")
+	}
+	if diags[1].MergeIf != st1013.SCAnalyzer.Doc.MergeIf {
+		t.Errorf("MergeIf wasn't set")
+	}
+}
+
+func TestFormatSeverityOverride(t *testing.T) {
+	diags := []diagnostic{
+		{Diagnostic: runner.Diagnostic{Category: "SA1012", Message: "default"}},
+		{Diagnostic: runner.Diagnostic{Category: "SA1012", Message: "overridden", Severity: lint.SeverityError}},
+	}
+
+	var text bytes.Buffer
+	textFormatter{W: &text}.Format(nil, diags)
+	want := "-: default (SA1012)\n-: overridden (SA1012, error)\n"
+	if text.String() != want {
+		t.Errorf("got text output %q, want %q", text.String(), want)
+	}
+
+	var out bytes.Buffer
+	jsonFormatter{W: &out}.Format(nil, diags)
+	dec := json.NewDecoder(&out)
+	for _, want := range []string{"", "error"} {
+		var v struct {
+			Level *string `json:"level"`
+		}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if v.Level != nil {
+			got = *v.Level
+			if got == "" {
+				t.Error("got empty level, want no level")
+			}
+		}
+		if got != want {
+			t.Errorf("got level %q, want %q", got, want)
+		}
+	}
+}
@@ -121,8 +121,10 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -147,6 +149,9 @@ type Diagnostic struct {
 	End      token.Position
 	Category string
 	Message  string
+	// Severity overrides the severity of the analyzer that reported
+	// the diagnostic, unless it is lint.SeverityNone.
+	Severity lint.Severity
 
 	SuggestedFixes []SuggestedFix
 	Related        []RelatedInformation
@@ -162,6 +167,10 @@ type RelatedInformation struct {
 type SuggestedFix struct {
 	Message   string
 	TextEdits []TextEdit
+	// Safe reports whether the fix can be applied without review. It
+	// is set from the documentation of the analyzer that suggested
+	// the fix.
+	Safe bool
 }
 
 type TextEdit struct {
@@ -170,6 +179,41 @@ type TextEdit struct {
 	NewText  []byte
 }
 
+// An AnalyzerPanic describes a panic that occurred while running an
+// analyzer on a package.
+type AnalyzerPanic struct {
+	Analyzer string
+	Package  string
+	// The position in the analyzed package that the analyzer was
+	// processing, as far as it is known: that of the last diagnostic
+	// the analyzer reported before panicking, or else the start of
+	// the package's first file.
+	Position token.Position
+	// The location in the analyzer's code that panicked, as
+	// determined from the innermost stack frame outside the runtime.
+	Frame token.Position
+	Value string
+	Stack string
+}
+
+func (p *AnalyzerPanic) Error() string {
+	if p.Position.IsValid() {
+		return fmt.Sprintf("analyzer %s panicked while analyzing %s at %s: %s", p.Analyzer, p.Package, p.Position, p.Value)
+	}
+	return fmt.Sprintf("analyzer %s panicked while analyzing %s: %s", p.Analyzer, p.Package, p.Value)
+}
+
+// An AbortedError is the error of packages that weren't analyzed, or
+// only partially, because an analyzer panicked on another package
+// and Runner.ContinueOnPanic isn't set.
+type AbortedError struct {
+	Panic *AnalyzerPanic
+}
+
+func (err *AbortedError) Error() string {
+	return fmt.Sprintf("aborted after analyzer %s panicked while analyzing %s", err.Panic.Analyzer, err.Panic.Package)
+}
+
 // A Result describes the result of analyzing a single package.
 //
 // It holds references to cached diagnostics and directives. They can
@@ -182,6 +226,11 @@ type Result struct {
 
 	Failed bool
 	Errors []error
+	// Panics in analyzers that were recovered from, only populated
+	// if Runner.ContinueOnPanic is set. Otherwise, panics are
+	// reported as errors and cause the package to fail. The results
+	// of packages with panics are incomplete and aren't cached.
+	Panics []*AnalyzerPanic
 	// Action results, path to file
 	results string
 	// Results relevant to testing, only set when test mode is enabled, path to file
@@ -312,6 +361,7 @@ type packageAction struct {
 	results  string
 	testData string
 	skipped  bool
+	panics   []*AnalyzerPanic
 }
 
 func (act *packageAction) String() string {
@@ -369,6 +419,10 @@ type analyzerAction struct {
 	ObjectFacts  map[objectFactKey]objectFact
 	PackageFacts map[packageFactKey]analysis.Fact
 	Pass         *analysis.Pass
+	Panic        *AnalyzerPanic
+	// position of the last diagnostic the analyzer reported, used to
+	// locate panics
+	lastPos token.Pos
 }
 
 func (act *analyzerAction) String() string {
@@ -383,10 +437,30 @@ type Runner struct {
 	// If set to true, Runner will populate results with data relevant to testing analyzers
 	TestMode bool
 
+	// If set to true, a panicking analyzer doesn't cause the whole
+	// package to fail. Instead, the panic is recorded in
+	// Result.Panics and the remaining analyzers, except for those
+	// depending on the panicking one, continue to run.
+	//
+	// Otherwise, the runner fails fast: the package with the
+	// panicking analyzer fails with an *AnalyzerPanic error, no
+	// further analyzers are started, and all packages that haven't
+	// been fully analyzed yet fail with an *AbortedError.
+	ContinueOnPanic bool
+
 	// Config that gets merged with per-package configs
 	cfg       config.Config
 	cache     *cache.Cache
 	semaphore tsync.Semaphore
+
+	// the first panic, if ContinueOnPanic isn't set
+	abortMu   sync.Mutex
+	abortedBy *AnalyzerPanic
+
+	// directory holding the results of packages that mustn't be
+	// cached, created on demand
+	tmpMu  sync.Mutex
+	tmpDir string
 }
 
 type subrunner struct {
@@ -518,6 +592,10 @@ func (r *subrunner) do(act action) error {
 		}
 	}()
 
+	if err := r.aborted(); err != nil {
+		return err
+	}
+
 	// compute hash of action
 	a.cfg = a.Package.Config.Merge(r.cfg)
 	h := r.cache.NewHash("staticcheck " + a.Package.PkgPath)
@@ -534,11 +612,12 @@ func (r *subrunner) do(act action) error {
 	// checks.
 
 	// Config used for constructing the hash; this config doesn't have
-	// Checks populated, because we always run all checks.
+	// Checks or ChecksByPath populated, because we always run all checks.
 	//
 	// This even works for users who add custom checks, because we include the binary's hash.
 	hashCfg := a.cfg
 	hashCfg.Checks = nil
+	hashCfg.ChecksByPath = nil
 	// note that we don't hash staticcheck's version; it is set as the
 	// salt by a package main.
 	fmt.Fprintf(h, "cfg %#v\n", hashCfg)
@@ -579,6 +658,7 @@ func (r *subrunner) do(act action) error {
 		}
 
 		a.skipped = result.skipped
+		a.panics = result.panics
 
 		// OPT(dh) instead of collecting all object facts and encoding
 		// them after analysis finishes, we could encode them as we
@@ -646,6 +726,11 @@ func (r *Runner) TotalWorkers() int {
 }
 
 func (r *Runner) writeCacheReader(a *packageAction, kind string, rs io.ReadSeeker) (string, error) {
+	if len(a.panics) > 0 {
+		// The results of a package with crashed analyzers are
+		// incomplete and must not be reused by future runs.
+		return r.writeTemp(kind, rs)
+	}
 	h := cache.Subkey(a.hash, kind)
 	out, _, err := r.cache.Put(h, rs)
 	if err != nil {
@@ -670,6 +755,66 @@ func (r *Runner) writeCacheGob(a *packageAction, kind string, data interface{})
 	return r.writeCacheReader(a, kind, f)
 }
 
+// writeTemp writes the data read from rs to a file outside the cache,
+// which is deleted by Runner.Close.
+func (r *Runner) writeTemp(kind string, rs io.Reader) (string, error) {
+	r.tmpMu.Lock()
+	if r.tmpDir == "" {
+		dir, err := os.MkdirTemp("", "staticcheck")
+		if err != nil {
+			r.tmpMu.Unlock()
+			return "", err
+		}
+		r.tmpDir = dir
+	}
+	dir := r.tmpDir
+	r.tmpMu.Unlock()
+
+	f, err := os.CreateTemp(dir, kind)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, rs); err != nil {
+		return "", fmt.Errorf("failed writing data: %w", err)
+	}
+	return f.Name(), f.Close()
+}
+
+// Close deletes the results of packages that weren't cached. It must
+// not be called before all results returned by Run have been loaded.
+func (r *Runner) Close() error {
+	r.tmpMu.Lock()
+	defer r.tmpMu.Unlock()
+	if r.tmpDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(r.tmpDir)
+	r.tmpDir = ""
+	return err
+}
+
+// abort stops the runner from starting any further work, because of
+// the panic p.
+func (r *Runner) abort(p *AnalyzerPanic) {
+	r.abortMu.Lock()
+	defer r.abortMu.Unlock()
+	if r.abortedBy == nil {
+		r.abortedBy = p
+	}
+}
+
+// aborted returns an *AbortedError if the runner has been aborted,
+// and nil otherwise.
+func (r *Runner) aborted() error {
+	r.abortMu.Lock()
+	defer r.abortMu.Unlock()
+	if r.abortedBy == nil {
+		return nil
+	}
+	return &AbortedError{Panic: r.abortedBy}
+}
+
 type packageActionResult struct {
 	facts   []gobFact
 	diags   []Diagnostic
@@ -677,6 +822,7 @@ type packageActionResult struct {
 	dirs    []lint.Directive
 	lpkg    *loader.Package
 	skipped bool
+	panics  []*AnalyzerPanic
 
 	// Only set when using test mode
 	testFacts []TestFact
@@ -722,6 +868,7 @@ func (r *subrunner) doUncached(a *packageAction) (packageActionResult, error) {
 		unused:    res.unused,
 		dirs:      dirs,
 		lpkg:      pkg,
+		panics:    res.panics,
 	}, err
 }
 
@@ -836,11 +983,15 @@ type analyzerRunner struct {
 	depPkgFacts map[packageFactKey]analysis.Fact
 	factsOnly   bool
 
-	stats *Stats
+	runner *Runner
+	stats  *Stats
 }
 
 func (ar *analyzerRunner) do(act action) error {
 	a := act.(*analyzerAction)
+	if err := ar.runner.aborted(); err != nil {
+		return err
+	}
 	results := map[*analysis.Analyzer]interface{}{}
 	// TODO(dh): does this have to be recursive?
 	for _, dep := range a.deps {
@@ -860,6 +1011,42 @@ func (ar *analyzerRunner) do(act action) error {
 		_, ok := factTypes[typ]
 		return ok
 	}
+	reportDiag := func(diag analysis.Diagnostic, severity lint.Severity) {
+		a.lastPos = diag.Pos
+		if !ar.factsOnly {
+			if diag.Category == "" {
+				diag.Category = a.Analyzer.Name
+			}
+			d := Diagnostic{
+				Position: report.DisplayPosition(ar.pkg.Fset, diag.Pos),
+				End:      report.DisplayPosition(ar.pkg.Fset, diag.End),
+				Category: diag.Category,
+				Message:  diag.Message,
+				Severity: severity,
+			}
+			for _, sugg := range diag.SuggestedFixes {
+				s := SuggestedFix{
+					Message: sugg.Message,
+				}
+				for _, edit := range sugg.TextEdits {
+					s.TextEdits = append(s.TextEdits, TextEdit{
+						Position: report.DisplayPosition(ar.pkg.Fset, edit.Pos),
+						End:      report.DisplayPosition(ar.pkg.Fset, edit.End),
+						NewText:  edit.NewText,
+					})
+				}
+				d.SuggestedFixes = append(d.SuggestedFixes, s)
+			}
+			for _, rel := range diag.Related {
+				d.Related = append(d.Related, RelatedInformation{
+					Position: report.DisplayPosition(ar.pkg.Fset, rel.Pos),
+					End:      report.DisplayPosition(ar.pkg.Fset, rel.End),
+					Message:  rel.Message,
+				})
+			}
+			a.Diagnostics = append(a.Diagnostics, d)
+		}
+	}
 	a.Pass = &analysis.Pass{
 		Analyzer:   a.Analyzer,
 		Fset:       ar.pkg.Fset,
@@ -869,38 +1056,7 @@ func (ar *analyzerRunner) do(act action) error {
 		TypesInfo:  ar.pkg.TypesInfo,
 		TypesSizes: ar.pkg.TypesSizes,
 		Report: func(diag analysis.Diagnostic) {
-			if !ar.factsOnly {
-				if diag.Category == "" {
-					diag.Category = a.Analyzer.Name
-				}
-				d := Diagnostic{
-					Position: report.DisplayPosition(ar.pkg.Fset, diag.Pos),
-					End:      report.DisplayPosition(ar.pkg.Fset, diag.End),
-					Category: diag.Category,
-					Message:  diag.Message,
-				}
-				for _, sugg := range diag.SuggestedFixes {
-					s := SuggestedFix{
-						Message: sugg.Message,
-					}
-					for _, edit := range sugg.TextEdits {
-						s.TextEdits = append(s.TextEdits, TextEdit{
-							Position: report.DisplayPosition(ar.pkg.Fset, edit.Pos),
-							End:      report.DisplayPosition(ar.pkg.Fset, edit.End),
-							NewText:  edit.NewText,
-						})
-					}
-					d.SuggestedFixes = append(d.SuggestedFixes, s)
-				}
-				for _, rel := range diag.Related {
-					d.Related = append(d.Related, RelatedInformation{
-						Position: report.DisplayPosition(ar.pkg.Fset, rel.Pos),
-						End:      report.DisplayPosition(ar.pkg.Fset, rel.End),
-						Message:  rel.Message,
-					})
-				}
-				a.Diagnostics = append(a.Diagnostics, d)
-			}
+			reportDiag(diag, lint.SeverityNone)
 		},
 		ResultOf: results,
 		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
@@ -984,20 +1140,72 @@ func (ar *analyzerRunner) do(act action) error {
 		},
 	}
 
+	defer report.HandleSeverities(a.Pass, reportDiag)()
+
 	t := time.Now()
-	res, err := a.Analyzer.Run(a.Pass)
+	res, err := ar.run(a)
 	ar.stats.measureAnalyzer(a.Analyzer, ar.pkg.PackageSpec, time.Since(t))
 	if err != nil {
+		// Panics are returned as errors even if ContinueOnPanic is
+		// set, so that analyzers depending on the panicking one get
+		// skipped. runAnalyzers decides whether they fail the
+		// package.
 		return err
 	}
 	a.Result = res
 	return nil
 }
 
+// run runs the analyzer of a, recovering from any panic and recording
+// it in a.Panic. Unless ContinueOnPanic is set, a panic also aborts
+// the runner.
+func (ar *analyzerRunner) run(a *analyzerAction) (res interface{}, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			pos := a.lastPos
+			if !pos.IsValid() && len(ar.pkg.Syntax) > 0 {
+				pos = ar.pkg.Syntax[0].Pos()
+			}
+			a.Panic = &AnalyzerPanic{
+				Analyzer: a.Analyzer.Name,
+				Package:  ar.pkg.PackageSpec.String(),
+				Position: report.DisplayPosition(ar.pkg.Fset, pos),
+				Frame:    panicFrame(),
+				Value:    fmt.Sprint(v),
+				Stack:    string(debug.Stack()),
+			}
+			if !ar.runner.ContinueOnPanic {
+				ar.runner.abort(a.Panic)
+			}
+			err = a.Panic
+		}
+	}()
+	return a.Analyzer.Run(a.Pass)
+}
+
+// panicFrame returns the position of the innermost stack frame that
+// isn't part of the runtime or of the runner's recovery code. It must
+// be called from a deferred function.
+func panicFrame() token.Position {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return token.Position{Filename: frame.File, Line: frame.Line}
+		}
+		if !more {
+			return token.Position{}
+		}
+	}
+}
+
 type analysisResult struct {
 	facts       []gobFact
 	diagnostics []Diagnostic
 	unused      unused.Result
+	panics      []*AnalyzerPanic
 
 	// Only set when using test mode
 	testFacts []TestFact
@@ -1036,6 +1244,7 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 		factsOnly:   pkgAct.factsOnly,
 		depObjFacts: depObjFacts,
 		depPkgFacts: depPkgFacts,
+		runner:      r.Runner,
 		stats:       &r.Stats,
 	}
 	queue := make(chan action, len(all))
@@ -1063,6 +1272,22 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 	}
 
 	var unusedResult unused.Result
+	var panics []*AnalyzerPanic
+	for _, a := range all {
+		if a.Panic != nil {
+			if !r.ContinueOnPanic {
+				// Don't bother with partial results, the package has
+				// failed.
+				return analysisResult{}, a.Panic
+			}
+			panics = append(panics, a.Panic)
+		}
+	}
+	if err := r.aborted(); err != nil {
+		// An analyzer panicked on another package while we were
+		// running, and some of our analyzers may have been skipped.
+		return analysisResult{}, err
+	}
 	for _, a := range all {
 		if a != root && a.Analyzer.Name == "U1000" && !a.failed {
 			// TODO(dh): figure out a clean abstraction, instead of
@@ -1141,6 +1366,7 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 		testFacts:   testFacts,
 		diagnostics: diags,
 		unused:      unusedResult,
+		panics:      panics,
 	}, nil
 }
 
@@ -1241,6 +1467,7 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 			Skipped:  item.skipped,
 			Failed:   item.failed,
 			Errors:   item.errors,
+			Panics:   item.panics,
 			results:  item.results,
 			testData: item.testData,
 		})
@@ -0,0 +1,330 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"honnef.co/go/tools/analysis/facts/tokenfile"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/internal/passes/buildir"
+	tsync "honnef.co/go/tools/internal/sync"
+	"honnef.co/go/tools/lintcmd/cache"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+var panicky = &analysis.Analyzer{
+	Name: "panicky",
+	Doc:  "panics",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		panic("oh no")
+	},
+}
+
+var reporter = &analysis.Analyzer{
+	Name: "reporter",
+	Doc:  "reports every file",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		for _, f := range pass.Files {
+			pass.Reportf(f.Package, "file")
+		}
+		return nil, nil
+	},
+}
+
+var dependent = &analysis.Analyzer{
+	Name:     "dependent",
+	Doc:      "depends on panicky",
+	Requires: []*analysis.Analyzer{panicky},
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		pass.Reportf(pass.Files[0].Package, "should not run")
+		return nil, nil
+	},
+}
+
+const trivialPkg = "package pkg\n\nfunc fn() {}\n"
+
+// newRunner returns a runner with a fresh cache.
+func newRunner(t *testing.T, continueOnPanic bool) *Runner {
+	c, err := cache.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetSalt([]byte("runner test"))
+	r, err := New(config.Config{}, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.ContinueOnPanic = continueOnPanic
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// run writes files to dir, runs analyzers on all packages in dir and
+// returns their results.
+func run(t *testing.T, r *Runner, dir string, files map[string]string, analyzers ...*analysis.Analyzer) []Result {
+	files["go.mod"] = "module example.com\n\ngo 1.20\n"
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &packages.Config{
+		Dir: dir,
+		Env: append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod"),
+	}
+	res, err := r.Run(cfg, analyzers, []string{"./..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// runAnalyzers runs analyzers on a single, trivial package and returns
+// its result.
+func runAnalyzers(t *testing.T, continueOnPanic bool, analyzers ...*analysis.Analyzer) Result {
+	res := run(t, newRunner(t, continueOnPanic), t.TempDir(), map[string]string{"pkg.go": trivialPkg}, analyzers...)
+	if len(res) != 1 {
+		t.Fatalf("got %d results, want 1", len(res))
+	}
+	return res[0]
+}
+
+func TestPanicContinue(t *testing.T) {
+	res := runAnalyzers(t, true, panicky, reporter, dependent)
+	if res.Failed {
+		t.Fatalf("package failed: %v", res.Errors)
+	}
+	if len(res.Panics) != 1 {
+		t.Fatalf("got %d panics, want 1", len(res.Panics))
+	}
+	p := res.Panics[0]
+	if p.Analyzer != "panicky" || p.Value != "oh no" {
+		t.Errorf("got panic %q in %s, want %q in panicky", p.Value, p.Analyzer, "oh no")
+	}
+	if filepath.Base(p.Position.Filename) != "pkg.go" {
+		t.Errorf("got panic position %s, want position in pkg.go", p.Position)
+	}
+	if filepath.Base(p.Frame.Filename) != "runner_test.go" {
+		t.Errorf("got panic frame %s, want frame in runner_test.go", p.Frame)
+	}
+	if p.Stack == "" {
+		t.Error("panic has no stack trace")
+	}
+
+	data, err := res.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cats []string
+	for _, diag := range data.Diagnostics {
+		cats = append(cats, diag.Category)
+	}
+	if len(cats) != 1 || cats[0] != "reporter" {
+		t.Errorf("got diagnostics from %v, want diagnostics only from reporter", cats)
+	}
+}
+
+func TestPanicFail(t *testing.T) {
+	res := runAnalyzers(t, false, panicky, reporter, dependent)
+	if !res.Failed {
+		t.Fatal("package didn't fail")
+	}
+	if len(res.Panics) != 0 {
+		t.Errorf("got %d recorded panics, want 0", len(res.Panics))
+	}
+	var p *AnalyzerPanic
+	for _, err := range res.Errors {
+		if errors.As(err, &p) {
+			break
+		}
+	}
+	if p == nil {
+		t.Fatalf("got errors %v, want an *AnalyzerPanic", res.Errors)
+	}
+	if p.Analyzer != "panicky" {
+		t.Errorf("got panic in %s, want panic in panicky", p.Analyzer)
+	}
+}
+
+func TestPanicNotCached(t *testing.T) {
+	var calls int32
+	counting := &analysis.Analyzer{
+		Name: "counting",
+		Doc:  "panics and counts how often it ran",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			panic("oh no")
+		},
+	}
+
+	r := newRunner(t, true)
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		res := run(t, r, dir, map[string]string{"pkg.go": trivialPkg}, counting, reporter)
+		if len(res) != 1 {
+			t.Fatalf("run %d: got %d results, want 1", i, len(res))
+		}
+		if len(res[0].Panics) != 1 {
+			t.Fatalf("run %d: got %d panics, want 1", i, len(res[0].Panics))
+		}
+		if _, err := res[0].Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("analyzer ran %d times, want 2", calls)
+	}
+	// The results of panicked packages are only deleted once the
+	// runner is closed.
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPanicPosition(t *testing.T) {
+	reportThenPanic := &analysis.Analyzer{
+		Name: "reportThenPanic",
+		Doc:  "reports the last declaration, then panics",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			decls := pass.Files[0].Decls
+			pass.Reportf(decls[len(decls)-1].Pos(), "last declaration")
+			panic("oh no")
+		},
+	}
+	res := runAnalyzers(t, true, reportThenPanic)
+	if len(res.Panics) != 1 {
+		t.Fatalf("got %d panics, want 1", len(res.Panics))
+	}
+	// The panic is located at the last diagnostic the analyzer
+	// reported.
+	if p := res.Panics[0].Position; filepath.Base(p.Filename) != "pkg.go" || p.Line != 3 {
+		t.Errorf("got panic position %s, want pkg.go:3", p)
+	}
+}
+
+func TestPanicFailFast(t *testing.T) {
+	var calls int32
+	counting := &analysis.Analyzer{
+		Name: "counting",
+		Doc:  "panics and counts how often it ran",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			panic("oh no")
+		},
+	}
+	files := map[string]string{
+		"a/a.go": "package a\n",
+		"b/b.go": "package b\n",
+	}
+
+	for _, continueOnPanic := range []bool{false, true} {
+		calls = 0
+		r := newRunner(t, continueOnPanic)
+		// Analyze one package at a time, so that the second package
+		// only starts after the first one panicked.
+		r.semaphore = tsync.NewSemaphore(1)
+		res := run(t, r, t.TempDir(), files, counting, reporter)
+		if len(res) != 2 {
+			t.Fatalf("got %d results, want 2", len(res))
+		}
+
+		var failed, panicked, aborted int
+		for _, res := range res {
+			if res.Failed {
+				failed++
+				for _, err := range res.Errors {
+					if err, ok := err.(*AbortedError); ok {
+						aborted++
+						if err.Panic.Analyzer != "counting" {
+							t.Errorf("package aborted after panic in %s, want counting", err.Panic.Analyzer)
+						}
+					}
+				}
+			}
+			panicked += len(res.Panics)
+		}
+		if continueOnPanic {
+			if calls != 2 || failed != 0 || panicked != 2 {
+				t.Errorf("continuing: got %d calls, %d failed packages and %d panics, want 2, 0 and 2", calls, failed, panicked)
+			}
+		} else {
+			// The first package fails because of the panic, the
+			// second one is aborted without being analyzed.
+			if calls != 1 || failed != 2 || aborted != 1 {
+				t.Errorf("failing fast: got %d calls, %d failed packages, %d of them aborted, want 1, 2 and 1", calls, failed, aborted)
+			}
+		}
+	}
+}
+
+func TestSeverityOverride(t *testing.T) {
+	overriding := &analysis.Analyzer{
+		Name:     "overriding",
+		Doc:      "reports the package name twice, overriding the severity once",
+		Requires: []*analysis.Analyzer{tokenfile.Analyzer},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			report.Report(pass, pass.Files[0].Name, "default")
+			report.Report(pass, pass.Files[0].Name, "overridden", report.Severity(lint.SeverityHint))
+			return nil, nil
+		},
+	}
+	res := runAnalyzers(t, false, overriding)
+	if res.Failed {
+		t.Fatalf("package failed: %v", res.Errors)
+	}
+	data, err := res.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]lint.Severity{
+		"default":    lint.SeverityNone,
+		"overridden": lint.SeverityHint,
+	}
+	if len(data.Diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(data.Diagnostics), len(want))
+	}
+	for _, diag := range data.Diagnostics {
+		if diag.Category != "overriding" {
+			t.Errorf("diagnostic %q has category %q, want %q", diag.Message, diag.Category, "overriding")
+		}
+		if diag.Severity != want[diag.Message] {
+			t.Errorf("diagnostic %q has severity %q, want %q", diag.Message, diag.Severity, want[diag.Message])
+		}
+	}
+}
+
+func TestSharedIR(t *testing.T) {
+	var irs [2]*buildir.IR
+	mk := func(i int) *analysis.Analyzer {
+		return &analysis.Analyzer{
+			Name:     fmt.Sprintf("ir%d", i),
+			Doc:      "uses IR",
+			Requires: []*analysis.Analyzer{buildir.Analyzer},
+			Run: func(pass *analysis.Pass) (interface{}, error) {
+				irs[i] = pass.ResultOf[buildir.Analyzer].(*buildir.IR)
+				return nil, nil
+			},
+		}
+	}
+
+	res := runAnalyzers(t, false, mk(0), mk(1))
+	if res.Failed {
+		t.Fatalf("package failed: %v", res.Errors)
+	}
+	if irs[0] == nil || irs[0] != irs[1] {
+		t.Errorf("analyzers didn't share the IR of the package: got %p and %p", irs[0], irs[1])
+	}
+}
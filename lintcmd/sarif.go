@@ -285,6 +285,10 @@ func (o *sarifFormatter) Format(checks []*lint.Analyzer, diagnostics []diagnosti
 				Text: p.Message,
 			},
 		}
+		if p.Diagnostic.Severity != lint.SeverityNone {
+			// The diagnostic overrides the rule's default level.
+			r.Level = sarifLevel(p.Diagnostic.Severity)
+		}
 		r.Locations = []sarif.Location{{
 			PhysicalLocation: sarif.PhysicalLocation{
 				ArtifactLocation: sarifArtifactLocation(p.Position.Filename),
@@ -302,6 +306,9 @@ func (o *sarifFormatter) Format(checks []*lint.Analyzer, diagnostics []diagnosti
 					Text: fix.Message,
 				},
 			}
+			if fix.Safe {
+				sfix.Properties = map[string]interface{}{"safe": true}
+			}
 			// file name -> replacements
 			changes := map[string][]sarif.Replacement{}
 			for _, edit := range fix.TextEdits {
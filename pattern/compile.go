@@ -0,0 +1,117 @@
+package pattern
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Compiled is a parsed pattern that has been prepared for being
+// matched against many nodes. It reuses the state of failed matches,
+// which are the vast majority, to avoid allocating on every match. A
+// Compiled is safe for concurrent use.
+type Compiled struct {
+	Pattern
+
+	matchers sync.Pool
+}
+
+// Compile parses a pattern, allowing nodes that rely on type
+// information, and prepares it for matching.
+func Compile(s string) (*Compiled, error) {
+	p := Parser{AllowTypeInfo: true}
+	pat, err := p.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Compiled{Pattern: pat}, nil
+}
+
+// MustCompile is like Compile but panics if the pattern can't be
+// parsed.
+func MustCompile(s string) *Compiled {
+	c, err := Compile(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Match matches node against the pattern, using the type information
+// in info and looking up the types named by Type nodes in pkg. If the
+// match succeeds, it returns a Matcher holding the bindings, which
+// then belongs to the caller. Otherwise, it returns nil.
+func (c *Compiled) Match(info *types.Info, pkg *types.Package, node ast.Node) (*Matcher, bool) {
+	m, _ := c.matchers.Get().(*Matcher)
+	if m == nil {
+		m = &Matcher{
+			State:       State{},
+			setBindings: make([]uint64, 0, 8),
+		}
+	}
+	if m.Pkg != pkg {
+		m.namedTypes = nil
+	}
+	m.TypesInfo = info
+	m.Pkg = pkg
+	clear(m.State)
+	if m.matchPattern(c.Pattern, node) {
+		return m, true
+	}
+	c.matchers.Put(m)
+	return nil, false
+}
+
+// Alternatives is a set of labeled patterns that are matched as one.
+// It is useful for checks that look for several related shapes of
+// code. An Alternatives is safe for concurrent use.
+type Alternatives struct {
+	labels   []string
+	patterns []*Compiled
+	// Relevant is the union of the Relevant fields of all patterns.
+	Relevant map[reflect.Type]struct{}
+}
+
+// NewAlternatives returns an Alternatives for the labeled patterns.
+// Patterns are tried in the lexical order of their labels.
+func NewAlternatives(patterns map[string]Pattern) *Alternatives {
+	alts := &Alternatives{
+		labels:   make([]string, 0, len(patterns)),
+		patterns: make([]*Compiled, 0, len(patterns)),
+		Relevant: map[reflect.Type]struct{}{},
+	}
+	for label := range patterns {
+		alts.labels = append(alts.labels, label)
+	}
+	slices.Sort(alts.labels)
+	for _, label := range alts.labels {
+		pat := patterns[label]
+		alts.patterns = append(alts.patterns, &Compiled{Pattern: pat})
+		for typ := range pat.Relevant {
+			alts.Relevant[typ] = struct{}{}
+		}
+	}
+	return alts
+}
+
+// Match matches node against the patterns, skipping those that can't
+// match a node of its type. It returns the label of the first pattern
+// that matched, and a Matcher holding its bindings. If no pattern
+// matched, it returns false.
+func (alts *Alternatives) Match(info *types.Info, pkg *types.Package, node ast.Node) (string, *Matcher, bool) {
+	typ := reflect.TypeOf(node)
+	if _, ok := alts.Relevant[typ]; !ok {
+		return "", nil, false
+	}
+	for i, c := range alts.patterns {
+		if _, ok := c.Relevant[typ]; !ok {
+			continue
+		}
+		if m, ok := c.Match(info, pkg, node); ok {
+			return alts.labels[i], m, true
+		}
+	}
+	return "", nil, false
+}
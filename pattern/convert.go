@@ -50,6 +50,7 @@ var astTypes = map[string]reflect.Type{
 	"TypeSpec":       reflect.TypeOf(ast.TypeSpec{}),
 	"InterfaceType":  reflect.TypeOf(ast.InterfaceType{}),
 	"BranchStmt":     reflect.TypeOf(ast.BranchStmt{}),
+	"LabeledStmt":    reflect.TypeOf(ast.LabeledStmt{}),
 	"IncDecStmt":     reflect.TypeOf(ast.IncDecStmt{}),
 	"BasicLit":       reflect.TypeOf(ast.BasicLit{}),
 }
@@ -98,6 +99,10 @@ func ASTToNode(node interface{}) Node {
 		objs := make([]Node, T.NumField())
 		for i := 0; i < T.NumField(); i++ {
 			f := v.FieldByName(T.Field(i).Name)
+			if f.Type() == rtTokPos {
+				objs[i] = ASTToNode(ellipsisToken(f.Interface().(token.Pos)))
+				continue
+			}
 			objs[i] = ASTToNode(f.Interface())
 		}
 
@@ -146,14 +151,20 @@ func NodeToAST(node Node, state State) interface{} {
 		default:
 			return v
 		}
-	case Builtin, Any, Object, Symbol, Not, Or:
+	case Builtin, Any, Object, Symbol, SplitName, Not, Or, Length, Type, Kind, WithType, IString, NoneMatch,
+		ValueLT, ValueLE, ValueGT, ValueGE, ValueRange:
 		panic("XXX")
 	case List:
 		if (node == List{}) {
 			return []ast.Node{}
 		}
 		x := []ast.Node{NodeToAST(node.Head, state).(ast.Node)}
-		x = append(x, NodeToAST(node.Tail, state).([]ast.Node)...)
+		// The tail may be a binding of the remainder of a matched
+		// list, such as a []ast.Expr or []ast.Stmt.
+		tail := reflect.ValueOf(NodeToAST(node.Tail, state))
+		for i := 0; i < tail.Len(); i++ {
+			x = append(x, tail.Index(i).Interface().(ast.Node))
+		}
 		return x
 	case Token:
 		return token.Token(node)
@@ -176,6 +187,25 @@ func NodeToAST(node Node, state State) interface{} {
 			continue
 		}
 		fAST := out.Elem().FieldByName(T.Field(i).Name)
+		if fAST.Type() == rtTokPos {
+			// A call's ellipsis; its exact position doesn't matter,
+			// only that it is valid.
+			var spread bool
+			switch n := fNode.Interface().(type) {
+			case nil, Any, Nil:
+			default:
+				switch r := NodeToAST(n.(Node), state).(type) {
+				case string:
+					spread = r == "..."
+				case token.Token:
+					spread = r == token.ELLIPSIS
+				}
+			}
+			if spread {
+				fAST.Set(reflect.ValueOf(token.Pos(1)))
+			}
+			continue
+		}
 		switch fAST.Type().Kind() {
 		case reflect.Slice:
 			c := reflect.ValueOf(NodeToAST(fNode.Interface().(Node), state))
@@ -186,36 +216,7 @@ func NodeToAST(node Node, state State) interface{} {
 				slice.Index(0).Set(c)
 				c = slice
 			}
-			switch fAST.Interface().(type) {
-			case []ast.Node:
-				switch cc := c.Interface().(type) {
-				case []ast.Node:
-					fAST.Set(c)
-				case []ast.Expr:
-					var slice []ast.Node
-					for _, el := range cc {
-						slice = append(slice, el)
-					}
-					fAST.Set(reflect.ValueOf(slice))
-				default:
-					panic("XXX")
-				}
-			case []ast.Expr:
-				switch cc := c.Interface().(type) {
-				case []ast.Node:
-					var slice []ast.Expr
-					for _, el := range cc {
-						slice = append(slice, el.(ast.Expr))
-					}
-					fAST.Set(reflect.ValueOf(slice))
-				case []ast.Expr:
-					fAST.Set(c)
-				default:
-					panic("XXX")
-				}
-			default:
-				panic("XXX")
-			}
+			fAST.Set(convertSlice(c, fAST.Type()))
 		case reflect.Int:
 			c := reflect.ValueOf(NodeToAST(fNode.Interface().(Node), state))
 			switch c.Kind() {
@@ -241,3 +242,24 @@ func NodeToAST(node Node, state State) interface{} {
 
 	return out.Interface().(ast.Node)
 }
+
+// convertSlice converts a slice of AST nodes, such as a []ast.Node or
+// a bound []ast.Expr, to the slice type T.
+func convertSlice(s reflect.Value, T reflect.Type) reflect.Value {
+	if s.Type() == T {
+		return s
+	}
+	out := reflect.MakeSlice(T, s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		el := s.Index(i).Interface()
+		if el == nil {
+			continue
+		}
+		v := reflect.ValueOf(el)
+		if !v.Type().AssignableTo(T.Elem()) {
+			panic(fmt.Sprintf("internal error: can't use %s as %s", v.Type(), T.Elem()))
+		}
+		out.Index(i).Set(v)
+	}
+	return out
+}
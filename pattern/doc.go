@@ -78,7 +78,7 @@ What follows is an exhaustive list of these nodes:
 	(BasicLit kind value)
 	(BinaryExpr x op y)
 	(BranchStmt tok label)
-	(CallExpr fun args)
+	(CallExpr fun args ellipsis)
 	(CaseClause list body)
 	(ChanType dir value)
 	(CommClause comm body)
@@ -100,6 +100,7 @@ What follows is an exhaustive list of these nodes:
 	(IndexExpr x index)
 	(InterfaceType methods)
 	(KeyValueExpr key value)
+	(LabeledStmt label stmt)
 	(MapType key value)
 	(RangeStmt key value tok x body)
 	(ReturnStmt results)
@@ -118,10 +119,15 @@ What follows is an exhaustive list of these nodes:
 
 Additionally, there are the String, Token and nil atoms.
 Strings are double-quoted string literals, as in (Ident "someName").
+(IString "someName") is like a String, but matches case-insensitively, so (Ident (IString "url")) matches identifiers named URL, Url and url.
 Tokens are also represented as double-quoted string literals, but are converted to token.Token values in contexts that require tokens,
 such as in (BinaryExpr x "<" y), where "<" is transparently converted to token.LSS during matching.
 The keyword 'nil' denotes the nil value, which represents the absence of any value.
 
+The ellipsis of a CallExpr is optional and defaults to _.
+It matches the token "..." if the call's final argument is spread, as in f(args...), and nil otherwise.
+For example, (CallExpr fun args nil) only matches calls without a spread.
+
 We also define the (List head tail) node, which is used to represent sequences of elements as a singly linked list.
 The head is a single element, and the tail is the remainder of the list.
 For example,
@@ -144,6 +150,11 @@ would match any list with at least two elements, where the first two elements ar
 
 	(List "foo" (List "bar" _))
 
+A binding in the tail position binds the remainder of the list, as a slice of the matched list's type.
+For example, matching (CallExpr fun _:rest) binds rest to an []ast.Expr holding all but the first argument,
+and matching (FuncLit _ _:rest) binds it to an []ast.Stmt. When converting a pattern back to an AST,
+such a binding is spliced into the surrounding list, as in (CallExpr fun (Ident "x"):rest).
+
 Note that it is not possible to match from the end of the list.
 That is, there is no way to express a query such as "a list of any length where the last element is foo".
 
@@ -189,6 +200,17 @@ types.Object it refers to.
 For example, "net/url.PathEscape" matches the PathEscape function in the net/url package,
 and "(net/url.EscapeError).Error" refers to the Error method on the net/url.EscapeError type,
 either on an instance of the type, or on the type itself.
+A method name of * matches all methods of a type, so "(*net/http.Client).*" matches all methods that have a *net/http.Client receiver.
+The receiver has to be spelled exactly as in the method's declaration; "(net/http.Client).*" matches none of them.
+
+Instead of a string, the name of a Symbol can be a (SplitName pkg recv name) node, which matches the package path,
+the receiver type and the name of the symbol separately. The receiver type is spelled as in the method's declaration,
+without the package path or type parameters, and is empty for symbols that aren't methods. The package path is empty
+for predeclared symbols. Binding all three parts, as in
+
+	(CallExpr (Symbol (SplitName pkg recv name)) _)
+
+binds pkg to "net/http", recv to "*Client" and name to "Do" for a call of (*net/http.Client).Do.
 
 For example, the following patterns match the following lines of code:
 
@@ -209,6 +231,11 @@ If a binding's node is not nil, the matcher will attempt to match a node accordi
 If a binding's node is nil, the binding will either recall an existing value, or match the Any node.
 It is an error to provide a non-nil node to a binding that has already been bound.
 
+A binding may also be constrained by a type, by passing a Type node between the name and the node.
+The binding only matches expressions whose type is assignable to the named type, as described below:
+
+	(Binding "r" (Type "io.Reader") _)
+
 Referring back to the earlier example, the following pattern will match self-assignment of idents:
 
 	(AssignStmt (Binding "lhs" (Ident _)) "=" (Binding "lhs" nil))
@@ -220,6 +247,16 @@ Together, this allows us to rewrite the earlier example as follows:
 
 	(AssignStmt lhs@(Ident _) "=" lhs)
 
+The node following the at-sign may also be the underscore, which binds
+whatever it matches. For example, the following pattern matches calls
+of methods named Close and binds the receiver, the X of the selector
+expression, to 'recv':
+
+	(CallExpr (SelectorExpr recv@_ (Ident "Close")) _)
+
+The Matcher's TypeOf method returns the type of a bound expression, such
+as the receiver's type.
+
 (Or nodes...) is a variadic node that tries matching each node until one succeeds. For example, the following pattern matches all idents of name "foo" or "bar":
 
 	(Ident (Or "foo" "bar"))
@@ -244,6 +281,72 @@ and 'name' will either be a String if the first option matched, or an Ident or S
 
 The Not node negates a match. For example, (Not (Ident _)) will match all nodes that aren't identifiers.
 
+(Type name) matches expressions whose type is assignable to the named type.
+Types are named by their package path and name, such as "io.Reader" or "net/url.URL",
+or by their name alone if they are predeclared, such as "error".
+A leading asterisk names a pointer type, as in "*net/url.URL".
+Expressions of interface type match if their type implements the named interface, but not based on the dynamic types of their values.
+For example, the following pattern matches calls of methods named Close on values that implement io.Closer:
+
+	(CallExpr (SelectorExpr recv@(Type "io.Closer") (Ident "Close")) [])
+
+Types other than the predeclared ones are looked up in the Matcher's Pkg and its transitive imports.
+
+(Kind kind) matches expressions whose type's underlying type is of the given kind,
+which is one of "array", "basic", "chan", "interface", "map", "pointer", "signature", "slice" and "struct".
+For example, the following pattern matches range loops over channels, regardless of the channels' element types:
+
+	(RangeStmt _ _ _ (Kind "chan") _)
+
+(WithType type node) matches expressions that match node and whose types, formatted as by types.Type.String, match type.
+Usually, type is a binding, which makes the name of the type available in the matcher's state.
+For example, the following pattern matches calls of len on values of any type, binding the name of the type to typ:
+
+	(CallExpr (Builtin "len") [(WithType typ _)])
+
+(ConstantExpression value) matches expressions with constant values, matching value against the expression's types.TypeAndValue.
+Unlike TrulyConstantExpression, which rejects expressions that contain identifiers, it also matches expressions involving named constants.
+For example, the following pattern matches shifts by constant amounts, binding the amount to n:
+
+	(BinaryExpr _ "<<" (ConstantExpression n))
+
+(ValueLT value), (ValueLE value), (ValueGT value), (ValueGE value) and (ValueRange min max)
+
+These nodes match numeric constants by comparing them with the integer, floating-point or rune literals they have been given as strings,
+which may be preceded by a minus sign. They match expressions with constant values, as well as the values of IntegerLiteral, TrulyConstantExpression and ConstantExpression.
+ValueRange matches values in the inclusive range from min to max. For example, the following pattern matches integer literals that are valid HTTP status codes:
+
+	(IntegerLiteral (ValueRange "100" "599"))
+
+Floating-point and rune constants can be compared, too, as in (ValueGE "0.5") and (ValueLT "'a'").
+
+(NoneMatch node)
+
+The NoneMatch node matches lists none of whose elements match the node. Where Not negates the match of a single node,
+NoneMatch negates the match of each element of a list.
+For example, the following pattern matches function literals whose bodies don't directly contain go statements:
+
+	(FuncLit _ (NoneMatch (GoStmt _)))
+
+(Length node)
+
+The Length node matches lists whose number of elements matches the node, which is compared against the decimal representation of the length.
+For example, the following pattern matches composite literals with exactly three elements, without having to spell out each element:
+
+	(CompositeLit _ (Length "3"))
+
+Lengths can also be bound, as in (CallExpr _ (Length n)), which binds n to the number of arguments, as a string.
+
+(AnyCompareOp) and (AnyArithOp)
+
+These are shorthands for matching any comparison operator and any arithmetic operator, respectively.
+They are expanded by the parser into Or nodes of tokens, which means that the following two patterns are identical:
+
+	(BinaryExpr _ (AnyCompareOp) _)
+	(BinaryExpr _ (Or "==" "!=" "<" "<=" ">" ">=") _)
+
+The arithmetic operators are +, -, *, /, %, &, |, ^, &^, << and >>.
+
 ChanDir(0)
 
 # Automatic unnesting of AST nodes
@@ -268,5 +371,11 @@ On the flip-side, there is no way to specifically match these wrapper nodes.
 For example, there is no way of searching for unnecessary parentheses, like in the following piece of Go code:
 
 	((x)) += 2
+
+The exception to this is LabeledStmt, which isn't unwrapped when it is matched against a (LabeledStmt label stmt) node,
+including LabeledStmt nodes nested in Or, Not and bindings.
+For example, the following pattern matches for loops labeled outer, binding their bodies:
+
+	(LabeledStmt (Ident "outer") (ForStmt _ _ _ body))
 */
 package pattern
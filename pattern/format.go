@@ -0,0 +1,102 @@
+package pattern
+
+import (
+	"reflect"
+	"strings"
+)
+
+// lineWidth is the maximum width of a node, excluding indentation,
+// that Format prints on a single line.
+const lineWidth = 60
+
+// Format returns the canonical, indented representation of a node.
+// Nodes whose single-line representation, as returned by String,
+// fits in 60 characters are printed on a single line. Larger nodes
+// are broken up, with each argument printed on its own line and
+// indented by one tab per level of nesting.
+//
+// Parsing the output of Format yields a pattern equivalent to the one
+// that was formatted. As with String, tokens are printed as strings,
+// which match the same nodes.
+func Format(node Node) string {
+	var sb strings.Builder
+	format(&sb, node, 0)
+	return sb.String()
+}
+
+func format(sb *strings.Builder, node Node, depth int) {
+	s := node.String()
+	if len(s) <= lineWidth {
+		sb.WriteString(s)
+		return
+	}
+
+	newline := func(depth int) {
+		sb.WriteByte('\n')
+		for i := 0; i < depth; i++ {
+			sb.WriteByte('\t')
+		}
+	}
+
+	switch node := node.(type) {
+	case Binding:
+		if node.Type != nil {
+			n := node.Node
+			if n == nil {
+				n = Nil{}
+			}
+			sb.WriteString("(Binding ")
+			sb.WriteString(String(node.Name).String())
+			newline(depth + 1)
+			format(sb, node.Type, depth+1)
+			newline(depth + 1)
+			format(sb, n, depth+1)
+			sb.WriteByte(')')
+			return
+		}
+		if node.Node == nil {
+			sb.WriteString(s)
+			return
+		}
+		sb.WriteString(node.Name)
+		sb.WriteByte('@')
+		format(sb, node.Node, depth)
+	case List:
+		if !isProperList(node) {
+			format(sb, node.Head, depth)
+			sb.WriteByte(':')
+			format(sb, node.Tail, depth)
+			return
+		}
+		sb.WriteByte('[')
+		for l := node; l.Head != nil; l = l.Tail.(List) {
+			newline(depth + 1)
+			format(sb, l.Head, depth+1)
+		}
+		sb.WriteByte(']')
+	case IString:
+		// An IString's value is a plain string, not a node, and
+		// can't be broken up.
+		sb.WriteString(s)
+	case Or:
+		sb.WriteString("(Or")
+		for _, n := range node.Nodes {
+			newline(depth + 1)
+			format(sb, n, depth+1)
+		}
+		sb.WriteByte(')')
+	default:
+		v := reflect.ValueOf(node)
+		if v.Kind() != reflect.Struct || v.NumField() == 0 {
+			sb.WriteString(s)
+			return
+		}
+		sb.WriteByte('(')
+		sb.WriteString(v.Type().Name())
+		for i := 0; i < numFields(node); i++ {
+			newline(depth + 1)
+			format(sb, v.Field(i).Interface().(Node), depth+1)
+		}
+		sb.WriteByte(')')
+	}
+}
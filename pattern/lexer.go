@@ -136,7 +136,8 @@ func (l *lexer) next() (r rune) {
 	r, l.width = utf8.DecodeRuneInString(l.input[l.pos:])
 
 	if r == '\n' {
-		l.f.AddLine(l.pos)
+		// Lines start after the newline.
+		l.f.AddLine(l.pos + l.width)
 	}
 
 	l.pos += l.width
@@ -153,7 +154,7 @@ func (l *lexer) backup() {
 }
 
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	// TODO(dh): emit position information in errors
+	// The parser adds position information to the error.
 	l.items <- item{
 		itemError,
 		fmt.Sprintf(format, args...),
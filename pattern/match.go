@@ -3,9 +3,12 @@ package pattern
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
 	"go/types"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
@@ -91,7 +94,15 @@ type State = map[string]any
 
 type Matcher struct {
 	TypesInfo *types.Info
-	State     State
+	// Pkg is the package being matched. Type nodes look up the types
+	// they name in Pkg and the packages it imports, directly or
+	// indirectly. If Pkg is nil, Type nodes can only name predeclared
+	// types.
+	Pkg   *types.Package
+	State State
+
+	// namedTypes caches the types looked up by Type nodes.
+	namedTypes map[string]types.Type
 
 	bindingsMapping []string
 
@@ -125,8 +136,14 @@ func (m *Matcher) merge() {
 }
 
 func (m *Matcher) Match(a Pattern, b ast.Node) bool {
-	m.bindingsMapping = a.Bindings
 	m.State = State{}
+	return m.matchPattern(a, b)
+}
+
+// matchPattern matches b against a, storing bindings in m.State,
+// which must be empty.
+func (m *Matcher) matchPattern(a Pattern, b ast.Node) bool {
+	m.bindingsMapping = a.Bindings
 	m.push()
 	_, ok := match(m, a.Root, b)
 	m.merge()
@@ -136,6 +153,57 @@ func (m *Matcher) Match(a Pattern, b ast.Node) bool {
 	return ok
 }
 
+// TypeOf returns the type of the expression bound to name, such as the
+// receiver bound by (CallExpr (SelectorExpr recv@_ _) _). It returns
+// nil if name isn't bound to an expression or if the matcher has no
+// type information.
+func (m *Matcher) TypeOf(name string) types.Type {
+	if m.TypesInfo == nil {
+		return nil
+	}
+	expr, ok := m.State[name].(ast.Expr)
+	if !ok {
+		return nil
+	}
+	return m.TypesInfo.TypeOf(expr)
+}
+
+// MatchAll walks the tree rooted at root in depth-first order and
+// calls yield for every node that matches a. Only nodes whose types
+// are in a.Relevant are tried; if a.Relevant is nil, every node is.
+// Each call to yield receives its own Matcher, whose State holds the
+// bindings of that match alone and stays valid after MatchAll
+// returns. MatchAll stops early if yield returns false. Afterwards,
+// m.State is unspecified.
+func (m *Matcher) MatchAll(a Pattern, root ast.Node, yield func(node ast.Node, m *Matcher) bool) {
+	done := false
+	ast.Inspect(root, func(node ast.Node) bool {
+		if done || node == nil {
+			return false
+		}
+		if a.Relevant != nil {
+			if _, ok := a.Relevant[reflect.TypeOf(node)]; !ok {
+				return true
+			}
+		}
+		if m.Match(a, node) {
+			// Match allocates a new State for every attempt, so the
+			// snapshot's bindings won't be clobbered by later matches.
+			snapshot := &Matcher{
+				TypesInfo:  m.TypesInfo,
+				Pkg:        m.Pkg,
+				State:      m.State,
+				namedTypes: m.namedTypes,
+			}
+			if !yield(node, snapshot) {
+				done = true
+				return false
+			}
+		}
+		return true
+	})
+}
+
 func Match(a Pattern, b ast.Node) (*Matcher, bool) {
 	m := &Matcher{}
 	ret := m.Match(a, b)
@@ -175,7 +243,9 @@ func match(m *Matcher, l, r interface{}) (interface{}, bool) {
 	case *ast.DeclStmt:
 		return match(m, l, r.Decl)
 	case *ast.LabeledStmt:
-		return match(m, l, r.Stmt)
+		if !matchesLabel(l) {
+			return match(m, l, r.Stmt)
+		}
 	case *ast.BlockStmt:
 		if r == nil {
 			return match(m, l, nil)
@@ -205,6 +275,13 @@ func match(m *Matcher, l, r interface{}) (interface{}, bool) {
 		return nil, l == r
 	}
 
+	if l, ok := l.(string); ok {
+		// Recalling a binding of a string, such as a name bound by
+		// (Symbol name) or a type bound by (WithType typ _)
+		r, ok := r.(string)
+		return r, ok && l == r
+	}
+
 	{
 		ln, ok1 := l.(ast.Node)
 		rn, ok2 := r.(ast.Node)
@@ -365,6 +442,16 @@ func matchNodeAST(m *Matcher, a Node, b interface{}) (interface{}, bool) {
 			}
 			ai := af.Interface()
 			bi := bf.Interface()
+			if bf.Type() == rtTokPos {
+				// The only position we expose is that of a call's
+				// ellipsis, which is matched as a token. Patterns
+				// built as Go values may leave it unset, which is
+				// the same as omitting it when parsing.
+				if ai == nil {
+					continue
+				}
+				bi = ellipsisToken(bi.(token.Pos))
+			}
 			if ai == nil {
 				return b, bi == nil
 			}
@@ -400,7 +487,14 @@ func matchAST(m *Matcher, a, b ast.Node) (interface{}, bool) {
 	for i := 0; i < ra.NumField(); i++ {
 		af := ra.Field(i)
 		bf := rb.Field(i)
-		if af.Type() == rtTokPos || af.Type() == rtObject || af.Type() == rtCommentGroup {
+		if af.Type() == rtTokPos {
+			if ra.Type() == rtCallExpr && ra.Type().Field(i).Name == "Ellipsis" &&
+				af.Interface().(token.Pos).IsValid() != bf.Interface().(token.Pos).IsValid() {
+				return nil, false
+			}
+			continue
+		}
+		if af.Type() == rtObject || af.Type() == rtCommentGroup {
 			continue
 		}
 
@@ -438,6 +532,11 @@ func matchAST(m *Matcher, a, b ast.Node) (interface{}, bool) {
 }
 
 func (b Binding) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	if b.Type != nil {
+		if _, ok := match(m, b.Type, node); !ok {
+			return nil, false
+		}
+	}
 	if isNil(b.Node) {
 		v, ok := m.State[b.Name]
 		if ok {
@@ -498,6 +597,22 @@ func (s String) Match(m *Matcher, node interface{}) (interface{}, bool) {
 	}
 }
 
+func (s IString) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	switch o := node.(type) {
+	case token.Token:
+		if tok, ok := tokensByString[strings.ToUpper(s.Value)]; ok {
+			return match(m, tok, node)
+		}
+		return nil, false
+	case string:
+		return o, strings.EqualFold(s.Value, o)
+	case types.TypeAndValue:
+		return o, o.Value != nil && strings.EqualFold(o.Value.String(), s.Value)
+	default:
+		return nil, false
+	}
+}
+
 func (tok Token) Match(m *Matcher, node interface{}) (interface{}, bool) {
 	o, ok := node.(token.Token)
 	if !ok {
@@ -579,10 +694,26 @@ func (fn Symbol) Match(m *Matcher, node interface{}) (interface{}, bool) {
 	default:
 		panic("unreachable")
 	}
+	var pkg, recv string
 	switch obj := obj.(type) {
 	case *types.Func:
 		// OPT(dh): optimize this similar to code.FuncName
 		name = obj.FullName()
+		if obj.Pkg() != nil {
+			pkg = obj.Pkg().Path()
+		}
+		if r := obj.Type().(*types.Signature).Recv(); r != nil {
+			recv = recvName(r.Type())
+		}
+		if s, ok := fn.Name.(String); ok && strings.HasSuffix(string(s), ").*") {
+			// Match any method of the receiver type. Method names
+			// don't contain dots, so the prefix includes the entire
+			// receiver.
+			if obj.Type().(*types.Signature).Recv() == nil {
+				return nil, false
+			}
+			return obj, strings.HasPrefix(name, string(s[:len(s)-1]))
+		}
 	case *types.Builtin:
 		name = obj.Name()
 	case *types.TypeName:
@@ -592,7 +723,11 @@ func (fn Symbol) Match(m *Matcher, node interface{}) (interface{}, bool) {
 				return nil, false
 			}
 			name = types.TypeString(obj.Type(), nil)
-			_, ok = match(m, fn.Name, name)
+			pkg = ""
+			if obj.Pkg() != nil {
+				pkg = obj.Pkg().Path()
+			}
+			ok = fn.matchName(m, name, pkg, "", obj.Name())
 			if ok || !obj.IsAlias() {
 				return origObj, ok
 			} else {
@@ -602,7 +737,7 @@ func (fn Symbol) Match(m *Matcher, node interface{}) (interface{}, bool) {
 				case interface{ Obj() *types.TypeName }:
 					obj = typ.Obj()
 				case *types.Basic:
-					return match(m, fn.Name, typ.Name())
+					return typ.Name(), fn.matchName(m, typ.Name(), "", "", typ.Name())
 				default:
 					return nil, false
 				}
@@ -616,12 +751,105 @@ func (fn Symbol) Match(m *Matcher, node interface{}) (interface{}, bool) {
 			return nil, false
 		}
 		name = fmt.Sprintf("%s.%s", obj.Pkg().Path(), obj.Name())
+		pkg = obj.Pkg().Path()
 	default:
 		return nil, false
 	}
 
-	_, ok = match(m, fn.Name, name)
-	return obj, ok
+	return obj, fn.matchName(m, name, pkg, recv, obj.Name())
+}
+
+// symbolName is the value that a SplitName is matched against.
+type symbolName struct {
+	pkg, recv, name string
+}
+
+// matchName matches fn.Name against the fully qualified name of a
+// symbol, or, if it uses SplitName, against the parts of the name.
+func (fn Symbol) matchName(m *Matcher, full, pkg, recv, name string) bool {
+	var v interface{} = full
+	if splitsName(fn.Name) {
+		v = symbolName{pkg, recv, name}
+	}
+	_, ok := match(m, fn.Name, v)
+	return ok
+}
+
+// splitsName reports whether node, the name of a Symbol, uses
+// SplitName, possibly wrapped in Or, Not and Binding.
+func splitsName(node Node) bool {
+	switch node := node.(type) {
+	case SplitName:
+		return true
+	case Or:
+		for _, alt := range node.Nodes {
+			if splitsName(alt) {
+				return true
+			}
+		}
+	case Not:
+		return splitsName(node.Node)
+	case Binding:
+		return splitsName(node.Node)
+	}
+	return false
+}
+
+// matchesLabel reports whether node, or one of the nodes it is
+// composed of, is a LabeledStmt, in which case labeled statements
+// must not be unwrapped before matching them against node.
+func matchesLabel(node interface{}) bool {
+	switch node := node.(type) {
+	case LabeledStmt:
+		return true
+	case Or:
+		for _, alt := range node.Nodes {
+			if matchesLabel(alt) {
+				return true
+			}
+		}
+	case Not:
+		return matchesLabel(node.Node)
+	case Binding:
+		return matchesLabel(node.Node)
+	}
+	return false
+}
+
+// recvName returns the name of the receiver type T the way SplitName
+// matches it.
+func recvName(T types.Type) string {
+	prefix := ""
+	if ptr, ok := types.Unalias(T).(*types.Pointer); ok {
+		prefix = "*"
+		T = ptr.Elem()
+	}
+	if named, ok := types.Unalias(T).(*types.Named); ok {
+		return prefix + named.Obj().Name()
+	}
+	return prefix + types.TypeString(T, func(*types.Package) string { return "" })
+}
+
+func (split SplitName) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	name, ok := node.(symbolName)
+	if !ok {
+		return nil, false
+	}
+	m.push()
+	if _, ok := match(m, split.Pkg, name.pkg); !ok {
+		m.pop()
+		return nil, false
+	}
+	if _, ok := match(m, split.Recv, name.recv); !ok {
+		m.pop()
+		return nil, false
+	}
+	if _, ok := match(m, split.Name, name.name); !ok {
+		m.pop()
+		return nil, false
+	}
+	m.merge()
+	return node, true
 }
 
 func (or Or) Match(m *Matcher, node interface{}) (interface{}, bool) {
@@ -690,13 +918,300 @@ func (texpr TrulyConstantExpression) Match(m *Matcher, node interface{}) (interf
 	return expr, ok
 }
 
+func (cexpr ConstantExpression) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	expr, ok := node.(ast.Expr)
+	if !ok {
+		return nil, false
+	}
+	tv, ok := m.TypesInfo.Types[expr]
+	if !ok {
+		return nil, false
+	}
+	if tv.Value == nil {
+		return nil, false
+	}
+	_, ok = match(m, cexpr.Value, tv)
+	return expr, ok
+}
+
+func (l Length) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Slice {
+		// Like the empty list, a length of zero does not match an
+		// untyped Go nil, such as a missing FieldList.
+		return nil, false
+	}
+	_, ok := match(m, l.Value, strconv.Itoa(v.Len()))
+	return node, ok
+}
+
+func (none NoneMatch) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	for i := 0; i < v.Len(); i++ {
+		// Discard bindings made by partial matches, so that they
+		// don't affect later elements or the rest of the pattern.
+		m.push()
+		_, ok := match(m, none.Node, v.Index(i).Interface())
+		m.pop()
+		if ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func (v ValueLT) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	return compareConstant(m, node, token.LSS, v.Value)
+}
+
+func (v ValueLE) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	return compareConstant(m, node, token.LEQ, v.Value)
+}
+
+func (v ValueGT) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	return compareConstant(m, node, token.GTR, v.Value)
+}
+
+func (v ValueGE) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	return compareConstant(m, node, token.GEQ, v.Value)
+}
+
+func (v ValueRange) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	if _, ok := compareConstant(m, node, token.GEQ, v.Min); !ok {
+		return nil, false
+	}
+	return compareConstant(m, node, token.LEQ, v.Max)
+}
+
+// compareConstant reports whether node, which may be an expression or
+// a types.TypeAndValue, has a numeric constant value that compares to
+// the literal in lit as specified by op.
+func compareConstant(m *Matcher, node interface{}, op token.Token, lit Node) (interface{}, bool) {
+	s, ok := lit.(String)
+	if !ok {
+		return nil, false
+	}
+	y := parseNumber(string(s))
+	if y.Kind() == constant.Unknown {
+		return nil, false
+	}
+
+	var x constant.Value
+	switch node := node.(type) {
+	case types.TypeAndValue:
+		x = node.Value
+	case ast.Expr:
+		x = m.TypesInfo.Types[node].Value
+	}
+	if x == nil {
+		return nil, false
+	}
+	switch x.Kind() {
+	case constant.Int, constant.Float:
+		return node, constant.Compare(x, op, y)
+	default:
+		return nil, false
+	}
+}
+
+// parseNumber parses an integer, floating-point or rune literal,
+// optionally preceded by a minus sign. It returns an unknown value if
+// s isn't such a literal.
+func parseNumber(s string) constant.Value {
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	var v constant.Value
+	if strings.HasPrefix(s, "'") {
+		// MakeFromLiteral doesn't verify the quotes of rune
+		// literals.
+		if !strings.HasSuffix(s, "'") {
+			return constant.MakeUnknown()
+		}
+		v = constant.MakeFromLiteral(s, token.CHAR, 0)
+	} else {
+		v = constant.MakeFromLiteral(s, token.INT, 0)
+		if v.Kind() == constant.Unknown {
+			v = constant.MakeFromLiteral(s, token.FLOAT, 0)
+		}
+	}
+	if neg && v.Kind() != constant.Unknown {
+		v = constant.UnaryOp(token.SUB, v, 0)
+	}
+	return v
+}
+
+func (typ Type) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	name, ok := typ.Name.(String)
+	if !ok {
+		return nil, false
+	}
+	expr, T := valueType(m, node)
+	if T == nil {
+		return nil, false
+	}
+	if basic, ok := T.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+		// nil is assignable to too many types to be useful.
+		return nil, false
+	}
+	want := m.lookupType(string(name))
+	if want == nil {
+		return nil, false
+	}
+	return expr, types.AssignableTo(T, want)
+}
+
+func (k Kind) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	kind, ok := k.Kind.(String)
+	if !ok {
+		return nil, false
+	}
+	expr, T := valueType(m, node)
+	if T == nil {
+		return nil, false
+	}
+	return expr, typeKind(T) == string(kind)
+}
+
+func (w WithType) Match(m *Matcher, node interface{}) (interface{}, bool) {
+	expr, ok := node.(ast.Expr)
+	if !ok {
+		return nil, false
+	}
+	T := m.TypesInfo.TypeOf(expr)
+	if T == nil {
+		return nil, false
+	}
+	if _, ok := match(m, w.Type, T.String()); !ok {
+		return nil, false
+	}
+	return match(m, w.Node, node)
+}
+
+// valueType returns node and its type if node is an expression that
+// has a value, as opposed to being a type expression.
+func valueType(m *Matcher, node interface{}) (ast.Expr, types.Type) {
+	expr, ok := node.(ast.Expr)
+	if !ok {
+		return nil, nil
+	}
+	if tv, ok := m.TypesInfo.Types[expr]; ok && tv.IsType() {
+		// Type expressions don't have values.
+		return nil, nil
+	}
+	return expr, m.TypesInfo.TypeOf(expr)
+}
+
+// typeKinds are the kinds that Kind nodes accept, as returned by
+// typeKind.
+var typeKinds = map[string]bool{
+	"array":     true,
+	"basic":     true,
+	"chan":      true,
+	"interface": true,
+	"map":       true,
+	"pointer":   true,
+	"signature": true,
+	"slice":     true,
+	"struct":    true,
+}
+
+// typeKind returns the kind of T's underlying type.
+func typeKind(T types.Type) string {
+	switch T.Underlying().(type) {
+	case *types.Array:
+		return "array"
+	case *types.Basic:
+		return "basic"
+	case *types.Chan:
+		return "chan"
+	case *types.Interface:
+		return "interface"
+	case *types.Map:
+		return "map"
+	case *types.Pointer:
+		return "pointer"
+	case *types.Signature:
+		return "signature"
+	case *types.Slice:
+		return "slice"
+	case *types.Struct:
+		return "struct"
+	default:
+		return ""
+	}
+}
+
+// lookupType returns the type named by name, as described by the
+// documentation of Type, or nil if it can't be found.
+func (m *Matcher) lookupType(name string) types.Type {
+	if T, ok := m.namedTypes[name]; ok {
+		return T
+	}
+	var T types.Type
+	if elem, ok := strings.CutPrefix(name, "*"); ok {
+		if elemT := m.lookupType(elem); elemT != nil {
+			T = types.NewPointer(elemT)
+		}
+	} else if idx := strings.LastIndex(name, "."); idx == -1 {
+		if tn, ok := types.Universe.Lookup(name).(*types.TypeName); ok {
+			T = tn.Type()
+		}
+	} else if m.Pkg != nil {
+		path, typName := name[:idx], name[idx+1:]
+		seen := map[*types.Package]bool{}
+		var find func(pkg *types.Package) *types.Package
+		find = func(pkg *types.Package) *types.Package {
+			if seen[pkg] {
+				return nil
+			}
+			seen[pkg] = true
+			if pkg.Path() == path {
+				return pkg
+			}
+			for _, imp := range pkg.Imports() {
+				if found := find(imp); found != nil {
+					return found
+				}
+			}
+			return nil
+		}
+		if pkg := find(m.Pkg); pkg != nil {
+			if tn, ok := pkg.Scope().Lookup(typName).(*types.TypeName); ok {
+				T = tn.Type()
+			}
+		}
+	}
+
+	if m.namedTypes == nil {
+		m.namedTypes = map[string]types.Type{}
+	}
+	m.namedTypes[name] = T
+	return T
+}
+
 var (
 	// Types of fields in go/ast structs that we want to skip
 	rtTokPos       = reflect.TypeOf(token.Pos(0))
 	rtObject       = reflect.TypeOf((*ast.Object)(nil))
 	rtCommentGroup = reflect.TypeOf((*ast.CommentGroup)(nil))
+
+	rtCallExpr = reflect.TypeOf(ast.CallExpr{})
 )
 
+// ellipsisToken returns token.ELLIPSIS if pos is valid, and nil
+// otherwise.
+func ellipsisToken(pos token.Pos) interface{} {
+	if pos.IsValid() {
+		return token.ELLIPSIS
+	}
+	return nil
+}
+
 var (
 	_ matcher = Binding{}
 	_ matcher = Any{}
@@ -707,8 +1222,21 @@ var (
 	_ matcher = Builtin{}
 	_ matcher = Object{}
 	_ matcher = Symbol{}
+	_ matcher = SplitName{}
 	_ matcher = Or{}
 	_ matcher = Not{}
 	_ matcher = IntegerLiteral{}
 	_ matcher = TrulyConstantExpression{}
+	_ matcher = ConstantExpression{}
+	_ matcher = Length{}
+	_ matcher = Type{}
+	_ matcher = Kind{}
+	_ matcher = WithType{}
+	_ matcher = IString{}
+	_ matcher = NoneMatch{}
+	_ matcher = ValueLT{}
+	_ matcher = ValueLE{}
+	_ matcher = ValueGT{}
+	_ matcher = ValueGE{}
+	_ matcher = ValueRange{}
 )
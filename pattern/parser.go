@@ -37,6 +37,8 @@ func roots(node Node, m map[reflect.Type]struct{}) {
 		roots(node.Node, m)
 	case Binding:
 		roots(node.Node, m)
+	case WithType:
+		roots(node.Node, m)
 	case Nil, nil:
 		// this branch is reached via bindings
 		for _, T := range allTypes {
@@ -144,10 +146,23 @@ var nodeToASTTypes = map[reflect.Type][]reflect.Type{
 	reflect.TypeOf(TypeSpec{}):                {reflect.TypeOf((*ast.TypeSpec)(nil))},
 	reflect.TypeOf(InterfaceType{}):           {reflect.TypeOf((*ast.InterfaceType)(nil))},
 	reflect.TypeOf(BranchStmt{}):              {reflect.TypeOf((*ast.BranchStmt)(nil))},
+	reflect.TypeOf(LabeledStmt{}):             {reflect.TypeOf((*ast.LabeledStmt)(nil))},
 	reflect.TypeOf(IncDecStmt{}):              {reflect.TypeOf((*ast.IncDecStmt)(nil))},
 	reflect.TypeOf(BasicLit{}):                {reflect.TypeOf((*ast.BasicLit)(nil))},
 	reflect.TypeOf(IntegerLiteral{}):          {reflect.TypeOf((*ast.BasicLit)(nil)), reflect.TypeOf((*ast.UnaryExpr)(nil))},
 	reflect.TypeOf(TrulyConstantExpression{}): allTypes, // this is an over-approximation, which is fine
+	reflect.TypeOf(ConstantExpression{}):      allTypes,
+	reflect.TypeOf(Length{}):                  nil,
+	reflect.TypeOf(Type{}):                    allTypes,
+	reflect.TypeOf(Kind{}):                    allTypes,
+	reflect.TypeOf(IString{}):                 nil,
+	reflect.TypeOf(SplitName{}):               nil,
+	reflect.TypeOf(NoneMatch{}):               nil,
+	reflect.TypeOf(ValueLT{}):                 allTypes,
+	reflect.TypeOf(ValueLE{}):                 allTypes,
+	reflect.TypeOf(ValueGT{}):                 allTypes,
+	reflect.TypeOf(ValueGE{}):                 allTypes,
+	reflect.TypeOf(ValueRange{}):              allTypes,
 }
 
 var requiresTypeInfo = map[string]bool{
@@ -156,6 +171,15 @@ var requiresTypeInfo = map[string]bool{
 	"Object":                  true,
 	"IntegerLiteral":          true,
 	"TrulyConstantExpression": true,
+	"ConstantExpression":      true,
+	"Type":                    true,
+	"Kind":                    true,
+	"WithType":                true,
+	"ValueLT":                 true,
+	"ValueLE":                 true,
+	"ValueGT":                 true,
+	"ValueGE":                 true,
+	"ValueRange":              true,
 }
 
 type Parser struct {
@@ -170,6 +194,26 @@ type Parser struct {
 	bindings map[string]int
 }
 
+// A ParseError describes a problem encountered while parsing a pattern.
+type ParseError struct {
+	// Pos is the position in the pattern at which the problem was
+	// found. Its filename is always "pattern".
+	Pos token.Position
+	Msg string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Pos, err.Msg)
+}
+
+// errorf returns a ParseError for the problem at the byte offset off.
+func (p *Parser) errorf(off int, format string, args ...interface{}) error {
+	return &ParseError{
+		Pos: p.lex.f.Position(p.lex.f.Pos(off)),
+		Msg: fmt.Sprintf(format, args...),
+	}
+}
+
 func (p *Parser) bindingIndex(name string) int {
 	if p.bindings == nil {
 		p.bindings = map[string]int{}
@@ -189,7 +233,7 @@ func (p *Parser) Parse(s string) (Pattern, error) {
 
 	fset := token.NewFileSet()
 	p.lex = &lexer{
-		f:     fset.AddFile("<input>", -1, len(s)),
+		f:     fset.AddFile("pattern", -1, len(s)),
 		input: s,
 		items: make(chan item),
 	}
@@ -203,7 +247,12 @@ func (p *Parser) Parse(s string) (Pattern, error) {
 		return Pattern{}, err
 	}
 	if item := <-p.lex.items; item.typ != itemEOF {
-		return Pattern{}, fmt.Errorf("unexpected token %s after end of pattern", item.typ)
+		for range p.lex.items {
+		}
+		if item.typ == itemError {
+			return Pattern{}, p.errorf(item.pos, "%s", item.val)
+		}
+		return Pattern{}, p.errorf(item.pos, "unexpected token %s after end of pattern", item.typ)
 	}
 
 	if len(p.bindings) > 64 {
@@ -259,7 +308,7 @@ func (p *Parser) accept(typ itemType) (item, bool) {
 
 func (p *Parser) unexpectedToken(valid string) error {
 	if p.cur.typ == itemError {
-		return fmt.Errorf("error lexing input: %s", p.cur.val)
+		return p.errorf(p.cur.pos, "%s", p.cur.val)
 	}
 	var got string
 	switch p.cur.typ {
@@ -269,8 +318,7 @@ func (p *Parser) unexpectedToken(valid string) error {
 		got = "'" + p.cur.typ.String() + "'"
 	}
 
-	pos := p.lex.f.Position(token.Pos(p.cur.pos))
-	return fmt.Errorf("%s: expected %s, found %s", pos, valid, got)
+	return p.errorf(p.cur.pos, "expected %s, found %s", valid, got)
 }
 
 func (p *Parser) node() (Node, error) {
@@ -282,6 +330,13 @@ func (p *Parser) node() (Node, error) {
 		return nil, p.unexpectedToken("Node type")
 	}
 
+	if node, ok := macros[typ.val]; ok {
+		if _, ok := p.accept(itemRightParen); !ok {
+			return nil, p.unexpectedToken("')'")
+		}
+		return node, nil
+	}
+
 	var objs []Node
 	for {
 		if _, ok := p.accept(itemRightParen); ok {
@@ -298,7 +353,7 @@ func (p *Parser) node() (Node, error) {
 
 	node, err := p.populateNode(typ.val, objs)
 	if err != nil {
-		return nil, err
+		return nil, p.errorf(typ.pos, "%s", err)
 	}
 	if node, ok := node.(Binding); ok {
 		node.idx = p.bindingIndex(node.Name)
@@ -316,6 +371,22 @@ func populateNode(typ string, objs []Node, allowTypeInfo bool) (Node, error) {
 		return nil, fmt.Errorf("Node %s requires type information", typ)
 	}
 
+	if typ == "Kind" && len(objs) == 1 {
+		if kind, ok := objs[0].(String); ok && !typeKinds[string(kind)] {
+			return nil, fmt.Errorf("unknown kind %q", string(kind))
+		}
+	}
+
+	if typ == "Binding" && len(objs) == 2 {
+		// The type constraint is optional.
+		objs = []Node{objs[0], nil, objs[1]}
+	}
+
+	if typ == "CallExpr" && len(objs) == 2 {
+		// The ellipsis is optional.
+		objs = []Node{objs[0], objs[1], Any{}}
+	}
+
 	pv := reflect.New(T)
 	v := pv.Elem()
 
@@ -348,10 +419,12 @@ func populateNode(typ string, objs []Node, allowTypeInfo bool) (Node, error) {
 		if f.Kind() == reflect.String {
 			if obj, ok := objs[i].(String); ok {
 				f.Set(reflect.ValueOf(string(obj)))
+			} else if typ == "IString" {
+				return nil, fmt.Errorf("argument of (IString value) must be a string, but got %s", objs[i])
 			} else {
 				return nil, fmt.Errorf("first argument of (Binding name node) must be string, but got %s", objs[i])
 			}
-		} else {
+		} else if objs[i] != nil {
 			f.Set(reflect.ValueOf(objs[i]))
 		}
 	}
@@ -407,14 +480,56 @@ var structNodes = map[string]reflect.Type{
 	"TypeSpec":                reflect.TypeOf(TypeSpec{}),
 	"InterfaceType":           reflect.TypeOf(InterfaceType{}),
 	"BranchStmt":              reflect.TypeOf(BranchStmt{}),
+	"LabeledStmt":             reflect.TypeOf(LabeledStmt{}),
 	"IncDecStmt":              reflect.TypeOf(IncDecStmt{}),
 	"BasicLit":                reflect.TypeOf(BasicLit{}),
 	"Object":                  reflect.TypeOf(Object{}),
 	"Symbol":                  reflect.TypeOf(Symbol{}),
+	"SplitName":               reflect.TypeOf(SplitName{}),
 	"Or":                      reflect.TypeOf(Or{}),
 	"Not":                     reflect.TypeOf(Not{}),
 	"IntegerLiteral":          reflect.TypeOf(IntegerLiteral{}),
 	"TrulyConstantExpression": reflect.TypeOf(TrulyConstantExpression{}),
+	"ConstantExpression":      reflect.TypeOf(ConstantExpression{}),
+	"Length":                  reflect.TypeOf(Length{}),
+	"Type":                    reflect.TypeOf(Type{}),
+	"Kind":                    reflect.TypeOf(Kind{}),
+	"WithType":                reflect.TypeOf(WithType{}),
+	"IString":                 reflect.TypeOf(IString{}),
+	"NoneMatch":               reflect.TypeOf(NoneMatch{}),
+	"ValueLT":                 reflect.TypeOf(ValueLT{}),
+	"ValueLE":                 reflect.TypeOf(ValueLE{}),
+	"ValueGT":                 reflect.TypeOf(ValueGT{}),
+	"ValueGE":                 reflect.TypeOf(ValueGE{}),
+	"ValueRange":              reflect.TypeOf(ValueRange{}),
+}
+
+// macros maps the names of shorthand nodes to the nodes they expand to. Macros take no arguments and are expanded by
+// the parser, which means that the matcher never sees them.
+var macros = map[string]Node{
+	// The comparison operators, as defined by the Go specification.
+	"AnyCompareOp": Or{Nodes: []Node{
+		Token(token.EQL),
+		Token(token.NEQ),
+		Token(token.LSS),
+		Token(token.LEQ),
+		Token(token.GTR),
+		Token(token.GEQ),
+	}},
+	// The arithmetic operators, as defined by the Go specification.
+	"AnyArithOp": Or{Nodes: []Node{
+		Token(token.ADD),
+		Token(token.SUB),
+		Token(token.MUL),
+		Token(token.QUO),
+		Token(token.REM),
+		Token(token.AND),
+		Token(token.OR),
+		Token(token.XOR),
+		Token(token.AND_NOT),
+		Token(token.SHL),
+		Token(token.SHR),
+	}},
 }
 
 func (p *Parser) object() (Node, error) {
@@ -445,9 +560,19 @@ func (p *Parser) object() (Node, error) {
 		}
 		var b Binding
 		if _, ok := p.accept(itemAt); ok {
-			o, err := p.node()
-			if err != nil {
-				return nil, err
+			var o Node
+			if _, ok := p.accept(itemBlank); ok {
+				// name@_ always creates a new binding, whereas a bare
+				// name recalls the binding if it has already been
+				// bound.
+				o = Any{}
+			} else {
+				p.rewind()
+				var err error
+				o, err = p.node()
+				if err != nil {
+					return nil, err
+				}
 			}
 			b = Binding{
 				Name: v.val,
@@ -522,5 +647,5 @@ Node ::= itemLeftParen itemTypeName Object* itemRightParen
 Object ::= Node | Array | Binding | itemVariable | itemBlank | itemString
 Array := itemLeftBracket Object* itemRightBracket
 Array := Object itemColon Object
-Binding ::= itemVariable itemAt Node
+Binding ::= itemVariable itemAt (Node | itemBlank)
 */
@@ -3,8 +3,10 @@ package pattern
 import (
 	"fmt"
 	"go/ast"
+	goformat "go/format"
 	goparser "go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -145,3 +147,1120 @@ func _() { _ = Alias(0) }
 		t.Errorf("%s did not match", p2.Root)
 	}
 }
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`(Ident "foo"`, `pattern:1:13: expected object, found 'EOF'`},
+		{`(Ident "foo" "bar")`, `pattern:1:2: tried to initialize node Ident with 2 values, expected 1`},
+		{`Ident`, `pattern:1:1: expected '(', found Ident`},
+		{`(Ident "foo"))`, `pattern:1:14: unexpected token ) after end of pattern`},
+		{"(CallExpr\n\t(Foo _)\n\t_)", `pattern:2:3: unknown node Foo`},
+		{"(CallExpr\n\t(Ident _)\n\t[_ %])", `pattern:3:5: unexpected character %`},
+		{`(Ident "foo)`, `pattern:1:8: unterminated string`},
+	}
+
+	p := Parser{}
+	for _, tt := range tests {
+		_, err := p.Parse(tt.in)
+		if err == nil {
+			t.Errorf("parsing %q succeeded, want error", tt.in)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("parsing %q: got error %q, want %q", tt.in, err, tt.want)
+		}
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("parsing %q: got error of type %T, want *ParseError", tt.in, err)
+		}
+	}
+}
+
+func TestParseMacros(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Node
+	}{
+		{
+			`(AnyCompareOp)`,
+			Or{Nodes: []Node{
+				Token(token.EQL), Token(token.NEQ), Token(token.LSS),
+				Token(token.LEQ), Token(token.GTR), Token(token.GEQ),
+			}},
+		},
+		{
+			`(AnyArithOp)`,
+			Or{Nodes: []Node{
+				Token(token.ADD), Token(token.SUB), Token(token.MUL), Token(token.QUO),
+				Token(token.REM), Token(token.AND), Token(token.OR), Token(token.XOR),
+				Token(token.AND_NOT), Token(token.SHL), Token(token.SHR),
+			}},
+		},
+	}
+
+	p := Parser{}
+	for _, tt := range tests {
+		pat, err := p.Parse(tt.in)
+		if err != nil {
+			t.Errorf("failed to parse %q: %s", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(pat.Root, tt.want) {
+			t.Errorf("%q expanded to %s, want %s", tt.in, pat.Root, tt.want)
+		}
+	}
+
+	if _, err := p.Parse(`(AnyCompareOp _)`); err == nil {
+		t.Errorf("expected error for macro with arguments")
+	}
+}
+
+func TestMatchMacros(t *testing.T) {
+	pat := MustParse(`(BinaryExpr _ op@(AnyCompareOp) _)`)
+
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"a == b", true},
+		{"a >= b", true},
+		{"a + b", false},
+		{"a && b", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, ok := Match(pat, expr)
+		if ok != tt.want {
+			t.Errorf("matching %q: got %t, want %t", tt.in, ok, tt.want)
+			continue
+		}
+		if ok && m.State["op"] != expr.(*ast.BinaryExpr).Op {
+			t.Errorf("matching %q: bound op to %v, want %v", tt.in, m.State["op"], expr.(*ast.BinaryExpr).Op)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	inputs := []string{
+		`(Ident "foo")`,
+		`(CallExpr (Symbol "fmt.Println") _)`,
+		`(Or
+			(CallExpr fn@(Or (Symbol "fmt.Sprint") (Symbol "fmt.Sprintf")) args)
+			(CallExpr fn@(Or (Symbol "fmt.Errorf") (Symbol "errors.New")) args))`,
+		`(IfStmt
+			(AssignStmt [(Ident "_") ok@(Ident _)] ":=" indexexpr@(IndexExpr _ _))
+			ok
+			set@(AssignStmt indexexpr "=" (CallExpr (Builtin "append") indexexpr:values))
+			(AssignStmt indexexpr "=" (CompositeLit _ values)))`,
+		`(ForStmt (AssignStmt initvar@(Ident _) _ (IntegerLiteral "0")) (BinaryExpr initvar (AnyCompareOp) limit) nil (Not (EmptyStmt)))`,
+		`(FuncDecl _ _ _ [(ReturnStmt [(Builtin "nil")]) _:rest])`,
+		`(CallExpr (SelectorExpr recv@_ (Ident "Close")) [])`,
+		`(CompositeLit _ (Length "3"))`,
+		`(FuncLit _ (NoneMatch (GoStmt _)))`,
+		`(CallExpr (Symbol "net/http.Error") [_ _ (IntegerLiteral (ValueRange "100" "599"))])`,
+		`(BinaryExpr (ValueLT "0") "<" (Or (ValueLE "-1.5") (ValueGT "'a'") (ValueGE "0x10")))`,
+		`(CallExpr (SelectorExpr (Binding "recv" (Type "*net/url.URL") nil) (Ident "String")) [])`,
+		`(CallExpr (Symbol "io.Copy") [(Binding "dst" (Type "io.Writer") (Ident _)) _])`,
+		`(Ident (Or (IString "url") (IString "a \"quoted\" string")))`,
+		`(RangeStmt _ _ _ (Kind "chan") _)`,
+		`(CallExpr (Symbol "append") [_ _] "...")`,
+		`(CallExpr fn args nil)`,
+		`(WithType typ (Ident "m"))`,
+		`(BinaryExpr _ "<<" (ConstantExpression n))`,
+		`(LabeledStmt (Ident "outer") (ForStmt _ _ _ body))`,
+		`(Ident (IString "a_very_long_identifier_name_that_exceeds_the_width_of_a_line"))`,
+	}
+
+	p := Parser{AllowTypeInfo: true}
+	for _, input := range inputs {
+		pat, err := p.Parse(input)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %s", input, err)
+		}
+		out := Format(pat.Root)
+		pat2, err := p.Parse(out)
+		if err != nil {
+			t.Errorf("failed to parse formatted pattern %q: %s", out, err)
+			continue
+		}
+		// Tokens are printed as strings, which aren't converted back
+		// to tokens until matching, so compare the canonical
+		// single-line forms instead of the nodes.
+		if pat.Root.String() != pat2.Root.String() {
+			t.Errorf("formatting %q yielded %q, which parses to a different pattern", input, out)
+		}
+		if out2 := Format(pat2.Root); out2 != out {
+			t.Errorf("formatting isn't stable: %q became %q", out, out2)
+		}
+	}
+}
+
+func TestMatchLabeledStmt(t *testing.T) {
+	f, err := goparser.ParseFile(token.NewFileSet(), "", `
+package foo
+func _() {
+outer:
+	for {
+		for {
+			break outer
+		}
+	}
+inner:
+	for {
+		continue inner
+	}
+	for {
+		break
+	}
+}
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[0].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(LabeledStmt (Ident "outer") _)`, []bool{true, false, false}},
+		{`(LabeledStmt _ (ForStmt _ _ _ _))`, []bool{true, true, false}},
+		// Other patterns still see through labels.
+		{`(ForStmt _ _ _ _)`, []bool{true, true, true}},
+		{`(Or (LabeledStmt (Ident "inner") _) (ForStmt _ _ _ [(BranchStmt (IString "break") nil)]))`, []bool{false, true, true}},
+		{`(Not (LabeledStmt _ _))`, []bool{false, false, true}},
+		{`(Or stmt@(LabeledStmt label _))`, []bool{true, true, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			if _, ok := Match(pat, stmt); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+			}
+		}
+	}
+
+	// Find the branch statements that target the outer label.
+	pat := MustParse(`(LabeledStmt label@(Ident _) _)`)
+	m, ok := Match(pat, body[0])
+	if !ok {
+		t.Fatal("labeled statement didn't match")
+	}
+	if label := m.State["label"].(*ast.Ident); label.Name != "outer" {
+		t.Errorf("got label %s, want outer", label.Name)
+	}
+	var found int
+	q := MustParse(`(BranchStmt (IString "break") (Ident "outer"))`)
+	ast.Inspect(body[0], func(node ast.Node) bool {
+		if _, ok := node.(*ast.BranchStmt); !ok {
+			return true
+		}
+		if _, ok := Match(q, node); ok {
+			found++
+		}
+		return true
+	})
+	if found != 1 {
+		t.Errorf("found %d breaks targeting outer, want 1", found)
+	}
+
+	// A pattern whose entry is Any must match each labeled loop once,
+	// not once for the label and again for the loop.
+	var loops int
+	var m2 Matcher
+	m2.MatchAll(MustParse(`(Or (ForStmt _ _ _ _) x@_)`), f, func(node ast.Node, m *Matcher) bool {
+		if _, ok := node.(*ast.LabeledStmt); ok {
+			t.Errorf("labeled statement at %d matched as well as its loop", node.Pos())
+		}
+		if _, ok := node.(*ast.ForStmt); ok {
+			loops++
+		}
+		return true
+	})
+	if loops != 4 {
+		t.Errorf("matched %d loops, want 4", loops)
+	}
+}
+
+func TestParseIStringError(t *testing.T) {
+	_, err := (&Parser{}).Parse(`(Ident (IString (Ident "x")))`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "argument of (IString value) must be a string") {
+		t.Errorf("got unexpected error %q", err)
+	}
+}
+
+func TestMatchListTail(t *testing.T) {
+	expr, err := goparser.ParseExpr(`func() { a(); b(); c() }(x, y, z)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := expr.(*ast.CallExpr)
+
+	m, ok := Match(MustParse(`(CallExpr (FuncLit _ _:stmts) _:args)`), call)
+	if !ok {
+		t.Fatal("pattern didn't match")
+	}
+	args, ok := m.State["args"].([]ast.Expr)
+	if !ok {
+		t.Fatalf("args is bound to %T, want []ast.Expr", m.State["args"])
+	}
+	if len(args) != 2 || args[0] != call.Args[1] || args[1] != call.Args[2] {
+		t.Errorf("args is bound to %v, want %v", args, call.Args[1:])
+	}
+	stmts, ok := m.State["stmts"].([]ast.Stmt)
+	if !ok {
+		t.Fatalf("stmts is bound to %T, want []ast.Stmt", m.State["stmts"])
+	}
+	body := call.Fun.(*ast.FuncLit).Body.List
+	if len(stmts) != 2 || stmts[0] != body[1] || stmts[1] != body[2] {
+		t.Errorf("stmts is bound to %v, want %v", stmts, body[1:])
+	}
+
+	// Bound tails can be spliced into replacements.
+	repl := MustParse(`(CallExpr (Ident "f") (Ident "w"):args)`)
+	var buf strings.Builder
+	if err := goformat.Node(&buf, token.NewFileSet(), NodeToAST(repl.Root, m.State)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "f(w, y, z)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	clause := NodeToAST(MustParse(`(CaseClause [] (ReturnStmt []):stmts)`).Root, m.State).(*ast.CaseClause)
+	if len(clause.Body) != 3 || clause.Body[1] != body[1] || clause.Body[2] != body[2] {
+		t.Errorf("got case clause body %v, want a return followed by %v", clause.Body, body[1:])
+	}
+}
+
+func TestMatchReceiver(t *testing.T) {
+	f, _, info, err := debug.TypeCheck(`
+package foo
+type File struct{}
+func (*File) Close() error { return nil }
+func open() *File { return nil }
+func Close() {}
+func _(f *File, files []File) {
+	f.Close()
+	open().Close()
+	(files[0]).Close()
+	Close()
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pat := MustParse(`(CallExpr (SelectorExpr recv@_ (Ident "Close")) _)`)
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	wants := []string{"*foo.File", "*foo.File", "foo.File", ""}
+	for i, stmt := range body {
+		call := stmt.(*ast.ExprStmt).X.(*ast.CallExpr)
+		m := &Matcher{TypesInfo: info}
+		ok := m.Match(pat, call)
+		if want := wants[i]; !ok {
+			if want != "" {
+				t.Errorf("statement %d didn't match", i)
+			}
+			continue
+		} else if want == "" {
+			t.Errorf("statement %d matched unexpectedly", i)
+			continue
+		}
+
+		recv, ok := m.State["recv"].(ast.Expr)
+		if !ok {
+			t.Errorf("statement %d: recv is bound to %T, want ast.Expr", i, m.State["recv"])
+			continue
+		}
+		if want := ast.Unparen(call.Fun.(*ast.SelectorExpr).X); recv != want {
+			t.Errorf("statement %d: recv is bound to %v, want %v", i, recv, want)
+		}
+		if typ := m.TypeOf("recv"); typ == nil || typ.String() != wants[i] {
+			t.Errorf("statement %d: receiver has type %v, want %s", i, typ, wants[i])
+		}
+	}
+}
+
+func TestMatchSplitName(t *testing.T) {
+	f, _, info, err := debug.TypeCheck(`
+package foo
+import "strings"
+type File struct{}
+func (*File) Close() error { return nil }
+func (File) Name() string { return "" }
+type List[T any] struct{}
+func (*List[T]) Len() int { return 0 }
+func Open() {}
+func _(f *File, l *List[int], b *strings.Builder) {
+	Open()
+	f.Close()
+	f.Name()
+	l.Len()
+	b.WriteString("")
+	strings.ToUpper("")
+	println()
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pat := MustParse(`(CallExpr (Symbol (SplitName pkg recv name)) _)`)
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	wants := [][3]string{
+		{"foo", "", "Open"},
+		{"foo", "*File", "Close"},
+		{"foo", "File", "Name"},
+		{"foo", "*List", "Len"},
+		{"strings", "*Builder", "WriteString"},
+		{"strings", "", "ToUpper"},
+		{"", "", "println"},
+	}
+	for i, stmt := range body {
+		m := &Matcher{TypesInfo: info}
+		if !m.Match(pat, stmt.(*ast.ExprStmt).X) {
+			t.Errorf("statement %d didn't match", i)
+			continue
+		}
+		got := [3]string{}
+		for j, name := range []string{"pkg", "recv", "name"} {
+			got[j], _ = m.State[name].(string)
+		}
+		if got != wants[i] {
+			t.Errorf("statement %d: got %q, want %q", i, got, wants[i])
+		}
+	}
+
+	// The parts can be matched, too, and failed matches don't leave
+	// bindings behind.
+	pat = MustParse(`(CallExpr (Symbol (Or (SplitName pkg "*File" name) (SplitName pkg "File" name))) _)`)
+	for i, stmt := range body {
+		m := &Matcher{TypesInfo: info}
+		ok := m.Match(pat, stmt.(*ast.ExprStmt).X)
+		if want := i == 1 || i == 2; ok != want {
+			t.Errorf("statement %d: got %t, want %t", i, ok, want)
+		}
+		if !ok && len(m.State) != 0 {
+			t.Errorf("statement %d: failed match left bindings %v", i, m.State)
+		}
+	}
+
+	// Predeclared types don't belong to any package.
+	f, _, info, err = debug.TypeCheck(`package foo; var _ = string("")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0]
+	m := &Matcher{TypesInfo: info}
+	if !m.Match(MustParse(`(CallExpr (Symbol (SplitName "" "" "string")) _)`), conv) {
+		t.Errorf("conversion to string didn't match")
+	}
+	if m.Match(MustParse(`(CallExpr (Symbol "io.WriteString") _)`), conv) {
+		t.Errorf("conversion to string matched io.WriteString")
+	}
+}
+
+func TestMatchLength(t *testing.T) {
+	tests := []struct {
+		pat  string
+		in   string
+		want bool
+	}{
+		{`(CompositeLit _ (Length "3"))`, `[]int{1, 2, 3}`, true},
+		{`(CompositeLit _ (Length "3"))`, `[]int{1, 2}`, false},
+		{`(CompositeLit _ (Length "0"))`, `[]int{}`, true},
+		{`(CompositeLit _ (Length "0"))`, `[]int{1}`, false},
+		{`(CallExpr _ (Length "2"))`, `f(a, b)`, true},
+		{`(FuncLit _ (Length "1"))`, `func() { a() }`, true},
+		{`(FuncLit _ (Length "0"))`, `func() {}`, true},
+		{`(FuncLit (FuncType (Length "2") _) _)`, `func(a int, b string) {}`, true},
+		// A missing result list is nil, not an empty list.
+		{`(FuncLit (FuncType _ (Length "0")) _)`, `func() {}`, false},
+		// Only lists have lengths.
+		{`(CallExpr (Length "1") _)`, `f(a)`, false},
+	}
+
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Match(MustParse(tt.pat), expr); ok != tt.want {
+			t.Errorf("matching %q against %s: got %t, want %t", tt.in, tt.pat, ok, tt.want)
+		}
+	}
+
+	expr, err := goparser.ParseExpr(`f(a, b, c)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := Match(MustParse(`(CallExpr _ (Length n))`), expr)
+	if !ok {
+		t.Fatal("pattern didn't match")
+	}
+	if m.State["n"] != "3" {
+		t.Errorf("n is bound to %v, want 3", m.State["n"])
+	}
+}
+
+func TestMatchEllipsis(t *testing.T) {
+	tests := []struct {
+		pat  string
+		in   string
+		want bool
+	}{
+		{`(CallExpr _ _)`, `f(a, b)`, true},
+		{`(CallExpr _ _)`, `f(a, b...)`, true},
+		{`(CallExpr _ _ _)`, `f(a, b...)`, true},
+		{`(CallExpr _ _ "...")`, `f(a, b...)`, true},
+		{`(CallExpr _ _ "...")`, `f(a, b)`, false},
+		{`(CallExpr _ _ nil)`, `f(a, b)`, true},
+		{`(CallExpr _ _ nil)`, `f(a, b...)`, false},
+		// Repeated bindings compare the ellipsis, too.
+		{`(BinaryExpr x@(CallExpr _ _) "==" x)`, `f(a) == f(a)`, true},
+		{`(BinaryExpr x@(CallExpr _ _) "==" x)`, `f(a) == f(a...)`, false},
+	}
+
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Match(MustParse(tt.pat), expr); ok != tt.want {
+			t.Errorf("matching %q against %s: got %t, want %t", tt.in, tt.pat, ok, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		pat  string
+		want string
+	}{
+		{`(CallExpr (Ident "f") [(Ident "a")])`, "f(a)"},
+		{`(CallExpr (Ident "f") [(Ident "a")] nil)`, "f(a)"},
+		{`(CallExpr (Ident "f") [(Ident "a")] "...")`, "f(a...)"},
+	} {
+		var buf strings.Builder
+		if err := goformat.Node(&buf, token.NewFileSet(), NodeToAST(MustParse(tt.pat).Root, State{})); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("converting %s: got %q, want %q", tt.pat, got, tt.want)
+		}
+	}
+
+	expr, err := goparser.ParseExpr(`f(a...)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ASTToNode(expr).String(), `(CallExpr (Ident "f") (Ident "a") "...")`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// Patterns built as Go values may leave the ellipsis unset, which
+	// is the same as omitting it.
+	lit := CallExpr{Fun: Ident{Name: String("f")}, Args: Any{}}
+	for _, in := range []string{`f(a)`, `f(a...)`} {
+		expr, err := goparser.ParseExpr(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Match(Pattern{Root: lit}, expr); !ok {
+			t.Errorf("%s didn't match %q", lit, in)
+		}
+	}
+	if got, want := lit.String(), `(CallExpr (Ident "f") _)`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	var buf strings.Builder
+	if err := goformat.Node(&buf, token.NewFileSet(), NodeToAST(CallExpr{Fun: Ident{Name: String("f")}, Args: List{}}, State{})); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "f()" {
+		t.Errorf("got %q, want %q", got, "f()")
+	}
+}
+
+func TestMatchNoneMatch(t *testing.T) {
+	tests := []struct {
+		pat  string
+		in   string
+		want bool
+	}{
+		{`(FuncLit _ (NoneMatch (GoStmt _)))`, `func() { a(); b() }`, true},
+		{`(FuncLit _ (NoneMatch (GoStmt _)))`, `func() { a(); go b() }`, false},
+		{`(FuncLit _ (NoneMatch (GoStmt _)))`, `func() {}`, true},
+		// Only direct elements are considered.
+		{`(FuncLit _ (NoneMatch (GoStmt _)))`, `func() { if x { go b() } }`, true},
+		// Expression statements are unnested.
+		{`(FuncLit _ (NoneMatch (CallExpr (Ident "panic") _)))`, `func() { a(); panic(1) }`, false},
+		{`(CallExpr _ (NoneMatch (BasicLit _ _)))`, `f(a, b)`, true},
+		{`(CallExpr _ (NoneMatch (BasicLit _ _)))`, `f(a, 1)`, false},
+		{`(CompositeLit _ (NoneMatch (KeyValueExpr _ _)))`, `T{1, 2}`, true},
+		{`(CompositeLit _ (NoneMatch (KeyValueExpr _ _)))`, `T{a: 1}`, false},
+		// Only lists can be matched.
+		{`(CallExpr (NoneMatch (BasicLit _ _)) _)`, `f(a)`, false},
+		// Bindings from outside can be used.
+		{`(CallExpr fn@(Ident _) (NoneMatch fn))`, `f(a, b)`, true},
+		{`(CallExpr fn@(Ident _) (NoneMatch fn))`, `f(a, f)`, false},
+	}
+
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Match(MustParse(tt.pat), expr); ok != tt.want {
+			t.Errorf("matching %q against %s: got %t, want %t", tt.in, tt.pat, ok, tt.want)
+		}
+	}
+
+	// x is bound by the partial match of a + b, which mustn't leak.
+	expr, err := goparser.ParseExpr(`f(a + b)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := Match(MustParse(`(CallExpr _ (NoneMatch (BinaryExpr x@(Ident _) "-" _)))`), expr)
+	if !ok {
+		t.Fatal("pattern didn't match")
+	}
+	if len(m.State) != 0 {
+		t.Errorf("got bindings %v, want none", m.State)
+	}
+}
+
+func BenchmarkNoneMatch(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("func() {")
+	for i := 0; i < 10000; i++ {
+		sb.WriteString("a();")
+	}
+	sb.WriteString("}")
+	expr, err := goparser.ParseExpr(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	pat := MustParse(`(FuncLit _ (NoneMatch (GoStmt _)))`)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := Match(pat, expr); !ok {
+			b.Fatal("pattern didn't match")
+		}
+	}
+}
+
+func TestMatchSymbolMethods(t *testing.T) {
+	f, pkg, info, err := debug.TypeCheck(`
+package foo
+import "net/url"
+type T struct{}
+func (T) M1()  {}
+func (*T) M2() {}
+type E struct{ *url.URL }
+func fn() {}
+func _(t T, pt *T, e E, u *url.URL) {
+	t.M1()
+	pt.M1()
+	pt.M2()
+	T.M1(t)
+	(*T).M2(pt)
+	u.String()
+	u.Query()
+	e.Query()
+	fn()
+	url.Parse("")
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(CallExpr (Symbol "(foo.T).*") _)`, []bool{true, true, false, true, false, false, false, false, false, false}},
+		{`(CallExpr (Symbol "(*foo.T).*") _)`, []bool{false, false, true, false, true, false, false, false, false, false}},
+		{`(CallExpr (Symbol "(*net/url.URL).*") _)`, []bool{false, false, false, false, false, true, true, true, false, false}},
+		{`(CallExpr (Symbol "(net/url.URL).*") _)`, []bool{false, false, false, false, false, false, false, false, false, false}},
+		// Functions don't have receivers.
+		{`(CallExpr (Symbol "(net/url).*") _)`, []bool{false, false, false, false, false, false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			m := &Matcher{TypesInfo: info, Pkg: pkg}
+			if ok := m.Match(pat, stmt.(*ast.ExprStmt).X); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestMatchValueComparison(t *testing.T) {
+	f, pkg, info, err := debug.TypeCheck(`
+package foo
+const big = 1e40
+func sink(...any) {}
+func _(x int) {
+	sink(200)
+	sink(-1)
+	sink(1.5)
+	sink('a')
+	sink(big)
+	sink(600)
+	sink("s")
+	sink(x)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(CallExpr _ [(ValueLT "256")])`, []bool{true, true, true, true, false, false, false, false}},
+		{`(CallExpr _ [(ValueLE "200")])`, []bool{true, true, true, true, false, false, false, false}},
+		{`(CallExpr _ [(ValueGT "1.5")])`, []bool{true, false, false, true, true, true, false, false}},
+		{`(CallExpr _ [(ValueGE "1.5")])`, []bool{true, false, true, true, true, true, false, false}},
+		{`(CallExpr _ [(ValueGE "'a'")])`, []bool{true, false, false, true, true, true, false, false}},
+		{`(CallExpr _ [(ValueGT "-0x2")])`, []bool{true, true, true, true, true, true, false, false}},
+		{`(CallExpr _ [(ValueRange "100" "599")])`, []bool{true, false, false, false, false, false, false, false}},
+		{`(CallExpr _ [(IntegerLiteral (ValueRange "100" "599"))])`, []bool{true, false, false, false, false, false, false, false}},
+		{`(CallExpr _ [(IntegerLiteral (ValueLT "0"))])`, []bool{false, true, false, false, false, false, false, false}},
+		{`(CallExpr _ [(ValueGT "1e30")])`, []bool{false, false, false, false, true, false, false, false}},
+		// Invalid literals never match.
+		{`(CallExpr _ [(ValueLT "foo")])`, []bool{false, false, false, false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			m := &Matcher{TypesInfo: info, Pkg: pkg}
+			if ok := m.Match(pat, stmt.(*ast.ExprStmt).X); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestMatchConstantExpression(t *testing.T) {
+	f, pkg, info, err := debug.TypeCheck(`
+package foo
+const size = 4
+func sink(...any) {}
+func _(x int) {
+	sink(1 + 2)
+	sink(size * 2)
+	sink(x * 2)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(CallExpr _ [(TrulyConstantExpression _)])`, []bool{true, false, false}},
+		{`(CallExpr _ [(ConstantExpression _)])`, []bool{true, true, false}},
+		{`(CallExpr _ [(ConstantExpression (ValueGE "5"))])`, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			m := &Matcher{TypesInfo: info, Pkg: pkg}
+			if ok := m.Match(pat, stmt.(*ast.ExprStmt).X); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+			}
+		}
+	}
+
+	// The constant value is bound, not the expression.
+	m := &Matcher{TypesInfo: info, Pkg: pkg}
+	arg := body[1].(*ast.ExprStmt).X.(*ast.CallExpr).Args[0]
+	if !m.Match(MustParse(`(ConstantExpression v)`), arg) {
+		t.Fatal("constant expression didn't match")
+	}
+	tv, ok := m.State["v"].(types.TypeAndValue)
+	if !ok {
+		t.Fatalf("got binding of type %T, want types.TypeAndValue", m.State["v"])
+	}
+	if got := tv.Value.String(); got != "8" {
+		t.Errorf("got value %s, want 8", got)
+	}
+}
+
+func TestMatchType(t *testing.T) {
+	f, pkg, info, err := debug.TypeCheck(`
+package foo
+import (
+	"bytes"
+	"io"
+	"os"
+)
+type Reader struct{}
+func (Reader) Read([]byte) (int, error) { return 0, nil }
+func sink(...any) {}
+func _(b *bytes.Buffer, r io.Reader, f *os.File, s string, e error) {
+	sink(b)
+	sink(r)
+	sink(f)
+	sink(s)
+	sink(e)
+	sink(Reader{})
+	sink(nil)
+	sink(io.Reader(nil))
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(CallExpr _ [(Binding "x" (Type "io.Reader") _)])`, []bool{true, true, true, false, false, true, false, true}},
+		{`(CallExpr _ [x@(Type "*os.File")])`, []bool{false, false, true, false, false, false, false, false}},
+		{`(CallExpr _ [(Type "error")])`, []bool{false, false, false, false, true, false, false, false}},
+		{`(CallExpr _ [(Type "string")])`, []bool{false, false, false, true, false, false, false, false}},
+		{`(CallExpr _ [(Type "io.Writer")])`, []bool{true, false, true, false, false, false, false, false}},
+		// net/http isn't imported by the package or its imports.
+		{`(CallExpr _ [(Type "net/http.Handler")])`, []bool{false, false, false, false, false, false, false, false}},
+		{`(CallExpr _ [(Type "io.NoSuchType")])`, []bool{false, false, false, false, false, false, false, false}},
+		// The type of a conversion is a type, not a value.
+		{`(CallExpr _ [(CallExpr (Type "io.Reader") _)])`, []bool{false, false, false, false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			call := stmt.(*ast.ExprStmt).X.(*ast.CallExpr)
+			m := &Matcher{TypesInfo: info, Pkg: pkg}
+			if ok := m.Match(pat, call); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+				continue
+			}
+			if x, ok := m.State["x"]; ok && x != call.Args[0] {
+				t.Errorf("statement %d: x is bound to %v, want %v", i, x, call.Args[0])
+			}
+		}
+	}
+
+	p := Parser{}
+	if _, err := p.Parse(`(Binding "x" (Type "io.Reader") _)`); err == nil || !strings.Contains(err.Error(), "requires type information") {
+		t.Errorf("got error %v, want an error about requiring type information", err)
+	}
+}
+
+func TestMatchKind(t *testing.T) {
+	f, _, info, err := debug.TypeCheck(`
+package foo
+type Chan chan int
+type Set map[string]struct{}
+func sink(...any) {}
+func _[T ~[]int](c chan int, rc <-chan int, nc Chan, m map[int]int, s Set, sl []int, a [4]int, p *[4]int, tp T) {
+	sink(c)
+	sink(rc)
+	sink(nc)
+	sink(m)
+	sink(s)
+	sink(sl)
+	sink(a)
+	sink(p)
+	sink(tp)
+	sink(sink)
+	sink([]int(nil))
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want []bool
+	}{
+		{`(CallExpr _ [(Kind "chan")])`, []bool{true, true, true, false, false, false, false, false, false, false, false}},
+		{`(CallExpr _ [(Kind "map")])`, []bool{false, false, false, true, true, false, false, false, false, false, false}},
+		{`(CallExpr _ [(Kind "slice")])`, []bool{false, false, false, false, false, true, false, false, false, false, true}},
+		{`(CallExpr _ [(Kind "array")])`, []bool{false, false, false, false, false, false, true, false, false, false, false}},
+		{`(CallExpr _ [(Kind "pointer")])`, []bool{false, false, false, false, false, false, false, true, false, false, false}},
+		// The underlying type of a type parameter is its constraint.
+		{`(CallExpr _ [(Kind "interface")])`, []bool{false, false, false, false, false, false, false, false, true, false, false}},
+		{`(CallExpr _ [(Kind "signature")])`, []bool{false, false, false, false, false, false, false, false, false, true, false}},
+		// The type of a conversion is a type, not a value.
+		{`(CallExpr _ [(CallExpr (Kind "slice") _)])`, []bool{false, false, false, false, false, false, false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		pat := MustParse(tt.pat)
+		for i, stmt := range body {
+			call := stmt.(*ast.ExprStmt).X.(*ast.CallExpr)
+			m := &Matcher{TypesInfo: info}
+			if ok := m.Match(pat, call); ok != tt.want[i] {
+				t.Errorf("matching statement %d against %s: got %t, want %t", i, tt.pat, ok, tt.want[i])
+			}
+		}
+	}
+
+	p := Parser{AllowTypeInfo: true}
+	if _, err := p.Parse(`(Kind "channel")`); err == nil || err.Error() != `pattern:1:2: unknown kind "channel"` {
+		t.Errorf("got error %v, want an error about an unknown kind", err)
+	}
+}
+
+func TestMatchWithType(t *testing.T) {
+	f, _, info, err := debug.TypeCheck(`
+package foo
+import "net/url"
+type T struct{}
+func sink(...any) {}
+func _(i int, s []string, p *T, u url.URL, m map[string]*url.URL, x, y int) {
+	sink(i)
+	sink(s)
+	sink(p)
+	sink(u)
+	sink(m)
+	sink(x, y)
+	sink(x, s)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[len(f.Decls)-1].(*ast.FuncDecl).Body.List
+	tests := []struct {
+		pat  string
+		want string
+	}{
+		{`(CallExpr _ [(WithType typ _)])`, "int"},
+		{`(CallExpr _ [(WithType typ _)])`, "[]string"},
+		{`(CallExpr _ [(WithType typ _)])`, "*foo.T"},
+		{`(CallExpr _ [(WithType typ _)])`, "net/url.URL"},
+		{`(CallExpr _ [(WithType typ (Ident "m"))])`, "map[string]*net/url.URL"},
+		// Recalling the binding requires the types to be identical.
+		{`(CallExpr _ [(WithType typ _) (WithType typ _)])`, "int"},
+		{`(CallExpr _ [(WithType typ _) (WithType typ _)])`, ""},
+	}
+	for i, tt := range tests {
+		call := body[i].(*ast.ExprStmt).X.(*ast.CallExpr)
+		m := &Matcher{TypesInfo: info}
+		ok := m.Match(MustParse(tt.pat), call)
+		if !ok {
+			if tt.want != "" {
+				t.Errorf("statement %d didn't match %s", i, tt.pat)
+			}
+			continue
+		}
+		if got := m.State["typ"]; got != tt.want {
+			t.Errorf("matching statement %d against %s: typ is bound to %v, want %q", i, tt.pat, got, tt.want)
+		}
+	}
+
+	var p Parser
+	if _, err := p.Parse(`(WithType typ _)`); err == nil {
+		t.Error("parsing WithType without type information succeeded")
+	}
+}
+
+func TestMatchIString(t *testing.T) {
+	f, _, info, err := debug.TypeCheck(`
+package foo
+func _(URL, Url, url, uri int) {
+	_ = URL + Url + url + uri
+	for {
+		break
+	}
+	_ = "Hello"
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := f.Decls[0].(*ast.FuncDecl)
+
+	pat := MustParse(`(Ident (IString "url"))`)
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			want := name.Name != "uri"
+			if _, ok := Match(pat, name); ok != want {
+				t.Errorf("matching %s against %s: got %t, want %t", name.Name, pat.Root, ok, want)
+			}
+		}
+	}
+
+	// Tokens
+	loop := fn.Body.List[1].(*ast.ForStmt)
+	if _, ok := Match(MustParse(`(BranchStmt (IString "Break") nil)`), loop.Body.List[0]); !ok {
+		t.Errorf("break statement didn't match")
+	}
+	if _, ok := Match(MustParse(`(BranchStmt (IString "continue") nil)`), loop.Body.List[0]); ok {
+		t.Errorf("break statement matched continue")
+	}
+
+	// Constant values
+	lit := fn.Body.List[2].(*ast.AssignStmt).Rhs[0]
+	m := &Matcher{TypesInfo: info}
+	if !m.Match(MustParse(`(TrulyConstantExpression (IString "\"HELLO\""))`), lit) {
+		t.Errorf("string constant didn't match")
+	}
+	if m.Match(MustParse(`(TrulyConstantExpression (IString "\"HELLO, WORLD\""))`), lit) {
+		t.Errorf("string constant matched a different string")
+	}
+}
+
+func TestCompiledMatch(t *testing.T) {
+	c := MustCompile(`(BinaryExpr x@(Ident _) "+" (BasicLit _ _))`)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// x is bound before the match fails, and mustn't leak into
+		// the next match.
+		{"a + b", ""},
+		{"c + 1", "c"},
+		{"d - 1", ""},
+		{"e + 2", "e"},
+	}
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, ok := c.Match(nil, nil, expr)
+		if ok != (tt.want != "") {
+			t.Errorf("matching %q: got %t, want %t", tt.in, ok, !ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if len(m.State) != 1 {
+			t.Errorf("matching %q: got bindings %v, want only x", tt.in, m.State)
+		}
+		if x, ok := m.State["x"].(*ast.Ident); !ok || x.Name != tt.want {
+			t.Errorf("matching %q: x is bound to %v, want %s", tt.in, m.State["x"], tt.want)
+		}
+	}
+}
+
+func TestAlternativesMatch(t *testing.T) {
+	alts := NewAlternatives(map[string]Pattern{
+		"append": MustParse(`(CallExpr (Ident "append") [x@(Ident _) _])`),
+		"sum":    MustParse(`(BinaryExpr x@(Ident _) "+" (BasicLit _ _))`),
+		"deref":  MustParse(`(StarExpr x@(Ident _))`),
+	})
+	tests := []struct {
+		in    string
+		label string
+		x     string
+	}{
+		{"append(a, 1)", "append", "a"},
+		{"b + 1", "sum", "b"},
+		{"*c", "deref", "c"},
+		{"d - 1", "", ""},
+		{"append(e)", "", ""},
+		{"f", "", ""},
+	}
+	for _, tt := range tests {
+		expr, err := goparser.ParseExpr(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		label, m, ok := alts.Match(nil, nil, expr)
+		if label != tt.label || ok != (tt.label != "") {
+			t.Errorf("matching %q: got label %q, want %q", tt.in, label, tt.label)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if x, ok := m.State["x"].(*ast.Ident); !ok || x.Name != tt.x {
+			t.Errorf("matching %q: x is bound to %v, want %s", tt.in, m.State["x"], tt.x)
+		}
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	expr, err := goparser.ParseExpr(`f(a + 1, g(b + 2), c - 3, d + 4)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pat := MustParse(`(BinaryExpr x@(Ident _) "+" y@(BasicLit _ _))`)
+
+	var m Matcher
+	var states []State
+	m.MatchAll(pat, expr, func(node ast.Node, m *Matcher) bool {
+		if m.State["x"] != node.(*ast.BinaryExpr).X {
+			t.Errorf("x is bound to %v, want %v", m.State["x"], node.(*ast.BinaryExpr).X)
+		}
+		states = append(states, m.State)
+		return true
+	})
+
+	want := []struct{ x, y string }{{"a", "1"}, {"b", "2"}, {"d", "4"}}
+	if len(states) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(states), len(want))
+	}
+	for i, w := range want {
+		// Every match must retain its own bindings, even after later
+		// matches and failed match attempts.
+		x, _ := states[i]["x"].(*ast.Ident)
+		y, _ := states[i]["y"].(*ast.BasicLit)
+		if x == nil || x.Name != w.x || y == nil || y.Value != w.y {
+			t.Errorf("match %d: got x=%v y=%v, want x=%s y=%s", i, states[i]["x"], states[i]["y"], w.x, w.y)
+		}
+	}
+
+	n := 0
+	m.MatchAll(pat, expr, func(node ast.Node, m *Matcher) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("got %d calls after yield returned false, want 1", n)
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, filepath.Join(runtime.GOROOT(), "src", "fmt", "print.go"), nil, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	const src = `(IfStmt nil (BinaryExpr x@(Ident _) "!=" (Ident "nil")) _ nil)`
+	var nodes []ast.Node
+	ast.Inspect(f, func(node ast.Node) bool {
+		if _, ok := node.(*ast.IfStmt); ok {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+
+	b.Run("Match", func(b *testing.B) {
+		pat := MustParse(src)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, node := range nodes {
+				Match(pat, node)
+			}
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		c := MustCompile(src)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, node := range nodes {
+				c.Match(nil, nil, node)
+			}
+		}
+	})
+}
@@ -55,21 +55,46 @@ var (
 	_ Node = TypeSpec{}
 	_ Node = InterfaceType{}
 	_ Node = BranchStmt{}
+	_ Node = LabeledStmt{}
 	_ Node = IncDecStmt{}
 	_ Node = BasicLit{}
 	_ Node = Nil{}
 	_ Node = Object{}
 	_ Node = Symbol{}
+	_ Node = SplitName{}
 	_ Node = Not{}
 	_ Node = Or{}
 	_ Node = IntegerLiteral{}
 	_ Node = TrulyConstantExpression{}
+	_ Node = ConstantExpression{}
+	_ Node = Length{}
+	_ Node = Type{}
+	_ Node = Kind{}
+	_ Node = WithType{}
+	_ Node = IString{}
+	_ Node = NoneMatch{}
+	_ Node = ValueLT{}
+	_ Node = ValueLE{}
+	_ Node = ValueGT{}
+	_ Node = ValueGE{}
+	_ Node = ValueRange{}
 )
 
 type Symbol struct {
 	Name Node
 }
 
+// A SplitName matches the parts of a symbol's fully qualified name, and can only be used as the name of a Symbol.
+// Pkg matches the symbol's package path, Recv the receiver type of methods, spelled as in the method's declaration
+// but without the package path or type parameters, such as "T" or "*T", and Name the symbol's name. Recv matches the
+// empty string for symbols that aren't methods, and so does Pkg for predeclared symbols. A SplitName may be wrapped in
+// Or, Not and Binding nodes, but can't be mixed with nodes that match the fully qualified name.
+type SplitName struct {
+	Pkg  Node
+	Recv Node
+	Name Node
+}
+
 type Token token.Token
 
 type Nil struct {
@@ -89,6 +114,13 @@ type BranchStmt struct {
 	Label Node
 }
 
+// A LabeledStmt matches a labeled statement. Unlike other patterns, which match the statement that a label is
+// attached to, it does not unwrap the label, making it possible to match the label's name.
+type LabeledStmt struct {
+	Label Node
+	Stmt  Node
+}
+
 type InterfaceType struct {
 	Methods Node
 }
@@ -136,7 +168,11 @@ type CaseClause struct {
 type CallExpr struct {
 	Fun  Node
 	Args Node
-	// XXX handle ellipsis
+	// Ellipsis matches "..." if the final argument is spread, and
+	// nil otherwise. It defaults to Any if omitted, and a nil
+	// Ellipsis is treated like Any, too; use Nil{} to only match
+	// calls without a spread.
+	Ellipsis Node
 }
 
 // TODO(dh): add a ChanDir node, and a way of instantiating it.
@@ -244,6 +280,9 @@ type SendStmt struct {
 
 type Binding struct {
 	Name string
+	// Type, if not nil, constrains the values that can be bound. It is
+	// matched against the node before Node is.
+	Type Node
 	Node Node
 
 	idx int
@@ -321,6 +360,33 @@ type IntegerLiteral struct {
 	Value Node
 }
 
+// ValueLT, ValueLE, ValueGT and ValueGE match numeric constants that are less than, less than or equal to, greater
+// than, and greater than or equal to Value, which must be a string containing an integer, floating-point or rune
+// literal, optionally preceded by a minus sign. They match expressions with constant values, as well as the
+// types.TypeAndValue that IntegerLiteral, TrulyConstantExpression and ConstantExpression match their Value against. For example,
+// (IntegerLiteral (ValueLT "256")) matches integer literals less than 256.
+type ValueLT struct {
+	Value Node
+}
+
+type ValueLE struct {
+	Value Node
+}
+
+type ValueGT struct {
+	Value Node
+}
+
+type ValueGE struct {
+	Value Node
+}
+
+// A ValueRange matches numeric constants in the inclusive range from Min to Max, which are literals as in ValueLT.
+type ValueRange struct {
+	Min Node
+	Max Node
+}
+
 type BinaryExpr struct {
 	X  Node
 	Op Node
@@ -348,16 +414,77 @@ type TrulyConstantExpression struct {
 	Value Node
 }
 
+// A ConstantExpression is any constant expression, including ones that make use of named constants. Unlike a
+// TrulyConstantExpression, its value may depend on build tags.
+type ConstantExpression struct {
+	Value Node
+}
+
+// A Length matches a list, such as the arguments of a call or the elements of a composite literal, whose number of
+// elements matches Value. The length is matched as a string of its decimal representation, so (Length "3") matches
+// lists of three elements, and (Length n) binds n to the length.
+type Length struct {
+	Value Node
+}
+
+// A NoneMatch matches a list, such as the statements of a block, none of whose elements match Node. Unlike List,
+// it considers each element exactly once, which makes it suitable for long lists. Bindings in Node have no effect.
+type NoneMatch struct {
+	Node Node
+}
+
+// An IString matches the same values as a String, but ignores the case of letters.
+type IString struct {
+	Value string
+}
+
+// A Type matches expressions whose type is assignable to the type named by Name, which must be a string. Types are
+// named by their package path and name, such as "io.Reader" or "net/url.URL", or by their name if they are
+// predeclared, such as "error". A leading asterisk names a pointer type. Expressions of interface type only match
+// if their type implements the named interface, regardless of the dynamic type of their value.
+type Type struct {
+	Name Node
+}
+
+// A Kind matches expressions whose type's underlying type is of the kind named by Kind, which must be one of the
+// strings "array", "basic", "chan", "interface", "map", "pointer", "signature", "slice" and "struct". These
+// correspond to the go/types types of the same names. The underlying type of a type parameter is its constraint
+// interface.
+type Kind struct {
+	Kind Node
+}
+
+// A WithType matches what Node matches, provided that the type of the matched expression, as a string in the format
+// of types.Type.String, matches Type. This is usually used with a binding, as in (WithType typ x), to make the name
+// of the type available to the user of the pattern.
+type WithType struct {
+	Type Node
+	Node Node
+}
+
 func stringify(n Node) string {
 	v := reflect.ValueOf(n)
 	var parts []string
 	parts = append(parts, v.Type().Name())
-	for i := 0; i < v.NumField(); i++ {
+	for i := 0; i < numFields(n); i++ {
 		parts = append(parts, fmt.Sprintf("%s", v.Field(i)))
 	}
 	return "(" + strings.Join(parts, " ") + ")"
 }
 
+// numFields returns the number of fields of a struct node that have to
+// be printed. Trailing optional fields that have their default value
+// are omitted.
+func numFields(n Node) int {
+	if expr, ok := n.(CallExpr); ok {
+		switch expr.Ellipsis.(type) {
+		case nil, Any:
+			return 2
+		}
+	}
+	return reflect.ValueOf(n).NumField()
+}
+
 func (stmt AssignStmt) String() string              { return stringify(stmt) }
 func (expr IndexExpr) String() string               { return stringify(expr) }
 func (expr IndexListExpr) String() string           { return stringify(expr) }
@@ -389,6 +516,7 @@ func (typ FuncType) String() string                 { return stringify(typ) }
 func (lit FuncLit) String() string                  { return stringify(lit) }
 func (decl FuncDecl) String() string                { return stringify(decl) }
 func (stmt BranchStmt) String() string              { return stringify(stmt) }
+func (stmt LabeledStmt) String() string             { return stringify(stmt) }
 func (expr CallExpr) String() string                { return stringify(expr) }
 func (clause CaseClause) String() string            { return stringify(clause) }
 func (typ ChanType) String() string                 { return stringify(typ) }
@@ -404,10 +532,23 @@ func (nil Nil) String() string                      { return "nil" }
 func (builtin Builtin) String() string              { return stringify(builtin) }
 func (obj Object) String() string                   { return stringify(obj) }
 func (fn Symbol) String() string                    { return stringify(fn) }
+func (name SplitName) String() string               { return stringify(name) }
 func (el Ellipsis) String() string                  { return stringify(el) }
 func (not Not) String() string                      { return stringify(not) }
 func (lit IntegerLiteral) String() string           { return stringify(lit) }
 func (expr TrulyConstantExpression) String() string { return stringify(expr) }
+func (expr ConstantExpression) String() string      { return stringify(expr) }
+func (l Length) String() string                     { return stringify(l) }
+func (typ Type) String() string                     { return stringify(typ) }
+func (k Kind) String() string                       { return stringify(k) }
+func (w WithType) String() string                   { return stringify(w) }
+func (n NoneMatch) String() string                  { return stringify(n) }
+func (v ValueLT) String() string                    { return stringify(v) }
+func (v ValueLE) String() string                    { return stringify(v) }
+func (v ValueGT) String() string                    { return stringify(v) }
+func (v ValueGE) String() string                    { return stringify(v) }
+func (v ValueRange) String() string                 { return stringify(v) }
+func (s IString) String() string                    { return fmt.Sprintf("(IString %q)", s.Value) }
 
 func (or Or) String() string {
 	s := "(Or"
@@ -452,6 +593,13 @@ func (l List) String() string {
 }
 
 func (bind Binding) String() string {
+	if bind.Type != nil {
+		node := bind.Node
+		if node == nil {
+			node = Nil{}
+		}
+		return fmt.Sprintf("(Binding %q %s %s)", bind.Name, bind.Type, node)
+	}
 	if bind.Node == nil {
 		return bind.Name
 	}
@@ -497,6 +645,7 @@ func (FuncType) isNode()                {}
 func (FuncLit) isNode()                 {}
 func (FuncDecl) isNode()                {}
 func (BranchStmt) isNode()              {}
+func (LabeledStmt) isNode()             {}
 func (CallExpr) isNode()                {}
 func (CaseClause) isNode()              {}
 func (ChanType) isNode()                {}
@@ -512,6 +661,7 @@ func (Nil) isNode()                     {}
 func (Builtin) isNode()                 {}
 func (Object) isNode()                  {}
 func (Symbol) isNode()                  {}
+func (SplitName) isNode()               {}
 func (Ellipsis) isNode()                {}
 func (Or) isNode()                      {}
 func (List) isNode()                    {}
@@ -522,3 +672,15 @@ func (Binding) isNode()                 {}
 func (Not) isNode()                     {}
 func (IntegerLiteral) isNode()          {}
 func (TrulyConstantExpression) isNode() {}
+func (ConstantExpression) isNode()      {}
+func (Length) isNode()                  {}
+func (Type) isNode()                    {}
+func (Kind) isNode()                    {}
+func (WithType) isNode()                {}
+func (IString) isNode()                 {}
+func (NoneMatch) isNode()               {}
+func (ValueLT) isNode()                 {}
+func (ValueLE) isNode()                 {}
+func (ValueGT) isNode()                 {}
+func (ValueGE) isNode()                 {}
+func (ValueRange) isNode()              {}
@@ -43,3 +43,37 @@ func fn() {
 	if x == 1 || x == 2 {
 	}
 }
+
+func fn2(x int) string {
+	if x == 1 { //@ diag(`could use tagged switch on x`)
+		s := "one"
+		return s
+	} else if x == 2 {
+		return "two"
+	} else if x == 3 {
+		return "three"
+	} else {
+		return "many"
+	}
+}
+
+func fn3(x int) string {
+	if x == 1 {
+		return "one"
+	} else if x < 10 {
+		return "few"
+	} else {
+		return "many"
+	}
+}
+
+func fn4(x int) string {
+	// Chains that declare variables in their conditions aren't
+	// converted.
+	if y := x * 2; y == 2 {
+		return "one"
+	} else if y == 4 {
+		return "two"
+	}
+	return ""
+}
@@ -90,8 +90,9 @@ type Suppression struct {
 }
 
 type Fix struct {
-	Description     Message          `json:"description"`
-	ArtifactChanges []ArtifactChange `json:"artifactChanges"`
+	Description     Message                `json:"description"`
+	ArtifactChanges []ArtifactChange       `json:"artifactChanges"`
+	Properties      map[string]interface{} `json:"properties,omitempty"`
 }
 
 type ArtifactChange struct {
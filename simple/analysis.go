@@ -39,6 +39,8 @@ import (
 	"honnef.co/go/tools/simple/s1038"
 	"honnef.co/go/tools/simple/s1039"
 	"honnef.co/go/tools/simple/s1040"
+	"honnef.co/go/tools/simple/s1041"
+	"honnef.co/go/tools/simple/s1042"
 )
 
 var Analyzers = []*lint.Analyzer{
@@ -77,4 +79,6 @@ var Analyzers = []*lint.Analyzer{
 	s1038.SCAnalyzer,
 	s1039.SCAnalyzer,
 	s1040.SCAnalyzer,
+	s1041.SCAnalyzer,
+	s1042.SCAnalyzer,
 }
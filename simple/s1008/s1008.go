@@ -7,6 +7,7 @@ import (
 	"go/token"
 
 	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/facts/generated"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
@@ -88,11 +89,13 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		if ret1.Name == "false" {
 			cond = negate(pass, cond)
 		}
+		ret := &ast.ReturnStmt{Results: []ast.Expr{cond}}
 		report.Report(pass, n1,
 			fmt.Sprintf("should use 'return %s' instead of 'if %s { return %s }; return %s'",
 				report.Render(pass, cond),
 				report.Render(pass, origCond), report.Render(pass, ret1), report.Render(pass, ret2)),
-			report.FilterGenerated())
+			report.FilterGenerated(),
+			report.Fixes(edit.Fix("simplify returning boolean expression", edit.ReplaceWithNode(pass.Fset, edit.Range{n1.Pos(), n2.End()}, ret))))
 	}
 	code.Preorder(pass, fn, (*ast.BlockStmt)(nil))
 	return nil, nil
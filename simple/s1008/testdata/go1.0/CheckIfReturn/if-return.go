@@ -160,3 +160,12 @@ func fn22(x string) bool {
 	}
 	return true
 }
+
+func fn23(x bool) bool {
+	// Don't flag, there is code between the if and the return.
+	if x {
+		return true
+	}
+	println()
+	return false
+}
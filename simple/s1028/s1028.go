@@ -2,12 +2,15 @@ package s1028
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
 
 	"honnef.co/go/tools/analysis/code"
 	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/facts/generated"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
 	"honnef.co/go/tools/pattern"
 
 	"golang.org/x/tools/go/analysis"
@@ -31,20 +34,113 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 
 var Analyzer = SCAnalyzer.Analyzer
 
-var (
-	checkErrorsNewSprintfQ = pattern.MustParse(`(CallExpr (Symbol "errors.New") [(CallExpr (Symbol "fmt.Sprintf") args)])`)
-	checkErrorsNewSprintfR = pattern.MustParse(`(CallExpr (SelectorExpr (Ident "fmt") (Ident "Errorf")) args)`)
-)
+var checkErrorsNewSprintfQ = pattern.MustParse(`(CallExpr (Symbol "errors.New") [(CallExpr (Symbol "fmt.Sprintf") args)])`)
 
 func run(pass *analysis.Pass) (interface{}, error) {
 	fn := func(node ast.Node) {
-		if _, edits, ok := code.MatchAndEdit(pass, checkErrorsNewSprintfQ, checkErrorsNewSprintfR, node); ok {
-			// TODO(dh): the suggested fix may leave an unused import behind
-			report.Report(pass, node, "should use fmt.Errorf(...) instead of errors.New(fmt.Sprintf(...))",
-				report.FilterGenerated(),
-				report.Fixes(edit.Fix("use fmt.Errorf", edits...)))
+		m, ok := code.Match(pass, checkErrorsNewSprintfQ, node)
+		if !ok {
+			return
+		}
+
+		// Refer to fmt the same way the call of fmt.Sprintf does, in
+		// case the package has been renamed or dot-imported.
+		sprintf := astutil.Unparen(node.(*ast.CallExpr).Args[0]).(*ast.CallExpr)
+		var fun ast.Expr
+		switch sfun := astutil.Unparen(sprintf.Fun).(type) {
+		case *ast.SelectorExpr:
+			fun = edit.Selector(sfun.X.(*ast.Ident).Name, "Errorf")
+		default:
+			fun = &ast.Ident{Name: "Errorf"}
 		}
+		call := &ast.CallExpr{Fun: fun, Args: m.State["args"].([]ast.Expr)}
+		edits := []analysis.TextEdit{edit.ReplaceWithNode(pass.Fset, node, call)}
+		if spec, ok := unusedImport(pass, node.(*ast.CallExpr)); ok {
+			edits = append(edits, deleteImport(pass, spec))
+		}
+		report.Report(pass, node, "should use fmt.Errorf(...) instead of errors.New(fmt.Sprintf(...))",
+			report.FilterGenerated(),
+			report.Fixes(edit.Fix("use fmt.Errorf", edits...)))
 	}
 	code.Preorder(pass, fn, (*ast.CallExpr)(nil))
 	return nil, nil
 }
+
+// unusedImport returns the import of the errors package if call, a
+// call of errors.New, is the only use of the package in its file, and
+// the import thus becomes unused when the call is replaced.
+func unusedImport(pass *analysis.Pass, call *ast.CallExpr) (*ast.ImportSpec, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		// errors has been dot-imported
+		return nil, false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	pkgName, ok := pass.TypesInfo.Uses[id].(*types.PkgName)
+	if !ok {
+		return nil, false
+	}
+
+	f := code.File(pass, call)
+	var spec *ast.ImportSpec
+	for _, imp := range f.Imports {
+		var obj types.Object
+		if imp.Name != nil {
+			obj = pass.TypesInfo.Defs[imp.Name]
+		} else {
+			obj = pass.TypesInfo.Implicits[imp]
+		}
+		if obj == pkgName {
+			spec = imp
+			break
+		}
+	}
+	if spec == nil {
+		return nil, false
+	}
+
+	uses := 0
+	ast.Inspect(f, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == pkgName {
+			uses++
+		}
+		return uses < 2
+	})
+	return spec, uses == 1
+}
+
+// deleteImport deletes an import spec, including the line it is on if
+// it is part of a parenthesized import declaration.
+func deleteImport(pass *analysis.Pass, spec *ast.ImportSpec) analysis.TextEdit {
+	f := code.File(pass, spec)
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range decl.Specs {
+			if s != spec {
+				continue
+			}
+			if !decl.Lparen.IsValid() {
+				return edit.Delete(decl)
+			}
+			tf := pass.Fset.File(spec.Pos())
+			line := tf.Line(spec.Pos())
+			if tf.Line(decl.Lparen) == line || tf.Line(decl.Rparen) == line || line == tf.LineCount() {
+				return edit.Delete(spec)
+			}
+			for _, other := range decl.Specs {
+				if other != spec && (tf.Line(other.Pos()) == line || tf.Line(other.End()) == line) {
+					// The line contains more than the spec.
+					return edit.Delete(spec)
+				}
+			}
+			return edit.Delete(edit.Range{tf.LineStart(line), tf.LineStart(line + 1)})
+		}
+	}
+	return edit.Delete(spec)
+}
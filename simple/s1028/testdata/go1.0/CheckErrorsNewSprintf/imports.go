@@ -0,0 +1,10 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func fn2() error {
+	return errors.New(fmt.Sprintf("%d: %s", 0, "x")) //@ diag(`should use fmt.Errorf`)
+}
@@ -0,0 +1,8 @@
+package pkg
+
+import "errors"
+import format "fmt"
+
+func fn3() error {
+	return errors.New(format.Sprintf("%d", 0)) //@ diag(`should use fmt.Errorf`)
+}
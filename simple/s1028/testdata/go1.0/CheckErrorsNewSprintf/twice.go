@@ -0,0 +1,12 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Each fix must keep the import for the other call.
+func fn4() (error, error) {
+	return errors.New(fmt.Sprintf("%d", 0)), //@ diag(`should use fmt.Errorf`)
+		errors.New(fmt.Sprintf("%d", 1)) //@ diag(`should use fmt.Errorf`)
+}
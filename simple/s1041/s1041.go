@@ -0,0 +1,166 @@
+package s1041
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "S1041",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer, generated.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Use a tuple assignment to swap two values`,
+		Text: `Go supports assigning to multiple variables at once, which makes it
+possible to swap two values without the use of a temporary variable.`,
+		Before: `
+tmp := a
+a = b
+b = tmp`,
+		After:   `a, b = b, a`,
+		Since:   "Unreleased",
+		MergeIf: lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	// single returns the only lhs and rhs of an assignment with the given token.
+	single := func(stmt ast.Stmt, tok token.Token) (lhs, rhs ast.Expr, ok bool) {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != tok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return nil, nil, false
+		}
+		return assign.Lhs[0], assign.Rhs[0], true
+	}
+
+	fn := func(node ast.Node) {
+		block := node.(*ast.BlockStmt)
+		if len(block.List) < 3 {
+			return
+		}
+		for i := range block.List[:len(block.List)-2] {
+			// tmp := a
+			lhs1, a, ok := single(block.List[i], token.DEFINE)
+			if !ok {
+				continue
+			}
+			tmp, ok := lhs1.(*ast.Ident)
+			if !ok || tmp.Name == "_" {
+				continue
+			}
+			// a = b
+			lhs2, b, ok := single(block.List[i+1], token.ASSIGN)
+			if !ok || !astutil.Equal(lhs2, a) {
+				continue
+			}
+			// b = tmp
+			lhs3, rhs3, ok := single(block.List[i+2], token.ASSIGN)
+			if !ok || !astutil.Equal(lhs3, b) {
+				continue
+			}
+			if id, ok := rhs3.(*ast.Ident); !ok || pass.TypesInfo.ObjectOf(id) != pass.TypesInfo.ObjectOf(tmp) {
+				continue
+			}
+			if !isSimple(a) || !isSimple(b) || astutil.Equal(a, b) {
+				continue
+			}
+			if code.RefersTo(pass, a, pass.TypesInfo.ObjectOf(tmp)) || code.RefersTo(pass, b, pass.TypesInfo.ObjectOf(tmp)) {
+				continue
+			}
+			if dependsOn(pass, a, b) || dependsOn(pass, b, a) {
+				// The original code evaluates b's operands after
+				// assigning to a, but a tuple assignment evaluates
+				// them before assigning anything, as in
+				// tmp := p; p = p.next; p.next = tmp
+				continue
+			}
+			if numUses(pass, block, tmp) != 1 {
+				// tmp is used for more than just the swap
+				continue
+			}
+
+			swap := fmt.Sprintf("%s, %s = %s, %s", report.Render(pass, a), report.Render(pass, b), report.Render(pass, b), report.Render(pass, a))
+			r := edit.Range{block.List[i].Pos(), block.List[i+2].End()}
+			report.Report(pass, r, fmt.Sprintf("should use tuple assignment to swap values: %s", swap),
+				report.FilterGenerated(),
+				report.Fixes(edit.Fix("use tuple assignment", edit.ReplaceWithString(r, swap))))
+		}
+	}
+	code.Preorder(pass, fn, (*ast.BlockStmt)(nil))
+	return nil, nil
+}
+
+// isSimple reports whether expr is an assignable expression that
+// consists only of identifiers, field selectors, pointer indirections,
+// and indexing with identifiers or literals. Evaluating such
+// expressions has no side effects other than potentially panicking.
+func isSimple(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name != "_"
+	case *ast.ParenExpr:
+		return isSimple(expr.X)
+	case *ast.SelectorExpr:
+		return isSimple(expr.X)
+	case *ast.StarExpr:
+		return isSimple(expr.X)
+	case *ast.IndexExpr:
+		switch expr.Index.(type) {
+		case *ast.Ident, *ast.BasicLit:
+			return isSimple(expr.X)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+}
+
+// dependsOn reports whether evaluating the operands of x reads the
+// variable or location that y denotes.
+func dependsOn(pass *analysis.Pass, x, y ast.Expr) bool {
+	y = astutil.Unparen(y)
+	if id, ok := y.(*ast.Ident); ok {
+		return code.RefersTo(pass, x, pass.TypesInfo.ObjectOf(id))
+	}
+	found := false
+	ast.Inspect(x, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		if expr, ok := node.(ast.Expr); ok && node != x && astutil.Equal(expr, y) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// numUses returns the number of uses of the variable declared by ident
+// in root.
+func numUses(pass *analysis.Pass, root ast.Node, ident *ast.Ident) int {
+	obj := pass.TypesInfo.ObjectOf(ident)
+	n := 0
+	ast.Inspect(root, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == obj {
+			n++
+		}
+		return true
+	})
+	return n
+}
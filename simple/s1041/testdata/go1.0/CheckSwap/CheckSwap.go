@@ -0,0 +1,66 @@
+package pkg
+
+type T struct {
+	x, y int
+	s    []int
+}
+
+func fn(a, b int, s []int, i, j int, t *T) {
+	tmp := a //@ diag(`should use tuple assignment to swap values: a, b = b, a`)
+	a = b
+	b = tmp
+
+	tmp2 := s[i] //@ diag(`should use tuple assignment to swap values: s[i], s[j] = s[j], s[i]`)
+	s[i] = s[j]
+	s[j] = tmp2
+
+	tmp3 := t.x //@ diag(`should use tuple assignment to swap values`)
+	t.x = t.y
+	t.y = tmp3
+
+	// tmp4 is used again afterwards
+	tmp4 := a
+	a = b
+	b = tmp4
+	println(tmp4)
+
+	// not a swap
+	tmp5 := a
+	a = b
+	b = a
+	_ = tmp5
+
+	// index with side effects
+	tmp6 := s[f()]
+	s[f()] = s[j]
+	s[j] = tmp6
+
+	// tmp7 is not fresh
+	var tmp7 int
+	tmp7 = a
+	a = b
+	b = tmp7
+
+	// b depends on a, so a tuple assignment would evaluate it
+	// differently
+	tmp8 := i
+	i = s[i]
+	s[i] = tmp8
+}
+
+type node struct {
+	next *node
+}
+
+func reverse(p *node) {
+	// p.next depends on p
+	tmp := p
+	p = p.next
+	p.next = tmp
+
+	tmp2 := p.next
+	p.next = p.next.next
+	p.next.next = tmp2
+}
+
+func f() int { return 0 }
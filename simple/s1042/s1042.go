@@ -0,0 +1,258 @@
+package s1042
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "S1042",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer, generated.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Use \'strconv\' instead of \'fmt.Sprintf\' to format a single value`,
+		Text: `Formatting a single integer, boolean or string with
+\'fmt.Sprintf\' is slower and less direct than using the corresponding
+function of the \'strconv\' package. Formatting a string with \'%v\'
+doesn't need a function call at all. The similar case of formatting a
+string with \'%s\' is covered by S1025.`,
+		Before: `
+fmt.Sprintf("%d", n)
+fmt.Sprintf("%t", b)
+fmt.Sprintf("%q", s)`,
+		After: `
+strconv.Itoa(n)
+strconv.FormatBool(b)
+strconv.Quote(s)`,
+		Since:   "Unreleased",
+		MergeIf: lint.MergeIfAll,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var checkSprintfStrconvQ = pattern.MustParse(`(CallExpr (Symbol "fmt.Sprintf") [format arg])`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		m, ok := code.Match(pass, checkSprintfStrconvQ, node)
+		if !ok {
+			return
+		}
+		verb, ok := code.ExprToString(pass, m.State["format"].(ast.Expr))
+		if !ok {
+			return
+		}
+		arg := m.State["arg"].(ast.Expr)
+		// We only handle basic types. Named types may implement
+		// fmt.Formatter or fmt.Stringer.
+		basic, ok := types.Unalias(pass.TypesInfo.TypeOf(arg)).(*types.Basic)
+		if !ok {
+			return
+		}
+
+		var fun string
+		var conv string
+		var extra []ast.Expr
+		info := basic.Info()
+		switch {
+		case (verb == "%d" || verb == "%v") && info&types.IsInteger != 0:
+			switch {
+			case basic.Kind() == types.Int:
+				fun = "Itoa"
+			case info&types.IsUnsigned != 0:
+				fun = "FormatUint"
+				if basic.Kind() != types.Uint64 {
+					conv = "uint64"
+				}
+				extra = []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "10"}}
+			default:
+				fun = "FormatInt"
+				if basic.Kind() != types.Int64 {
+					conv = "int64"
+				}
+				extra = []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "10"}}
+			}
+		case (verb == "%t" || verb == "%v") && info&types.IsBoolean != 0:
+			fun = "FormatBool"
+		case verb == "%q" && info&types.IsString != 0:
+			fun = "Quote"
+		case verb == "%v" && info&types.IsString != 0:
+			report.Report(pass, node, "the argument is already a string, there's no need to use fmt.Sprintf",
+				report.FilterGenerated(),
+				report.Fixes(edit.Fix("remove unnecessary call to fmt.Sprintf", edit.ReplaceWithNode(pass.Fset, node, arg))))
+			return
+		default:
+			return
+		}
+
+		var opts []report.Option
+		if edits, ok := strconvEdits(pass, node.(*ast.CallExpr), fun, conv, arg, extra); ok {
+			opts = append(opts, report.Fixes(edit.Fix("use strconv."+fun, edits...)))
+		}
+		opts = append(opts, report.FilterGenerated())
+		report.Report(pass, node, fmt.Sprintf("should use strconv.%s instead of fmt.Sprintf", fun), opts...)
+	}
+	code.Preorder(pass, fn, (*ast.CallExpr)(nil))
+	return nil, nil
+}
+
+// strconvEdits returns the edits that replace call with a call of
+// strconv.fun, which takes arg, converted to conv if it isn't empty,
+// and extra. This includes making sure that strconv is imported and
+// that fmt doesn't remain imported but unused. It returns false if it
+// can't do so.
+func strconvEdits(pass *analysis.Pass, call *ast.CallExpr, fun, conv string, arg ast.Expr, extra []ast.Expr) ([]analysis.TextEdit, bool) {
+	f := code.File(pass, call)
+	fmtName, ok := pkgName(pass, call)
+	if !ok {
+		// fmt has been dot-imported
+		return nil, false
+	}
+	fmtSpec := importSpec(pass, f, fmtName)
+	if fmtSpec == nil {
+		return nil, false
+	}
+	name := "strconv"
+	var strconvSpec *ast.ImportSpec
+	for _, spec := range f.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == "strconv" {
+			if spec.Name != nil {
+				if spec.Name.Name == "." || spec.Name.Name == "_" {
+					continue
+				}
+				name = spec.Name.Name
+			}
+			strconvSpec = spec
+			break
+		}
+	}
+	// Make sure that name refers to strconv at the call site.
+	var want types.Object
+	if strconvSpec != nil {
+		want = importedName(pass, strconvSpec)
+	}
+	if _, obj := pass.Pkg.Scope().Innermost(call.Pos()).LookupParent(name, call.Pos()); obj != want {
+		return nil, false
+	}
+
+	if conv != "" {
+		arg = &ast.CallExpr{Fun: &ast.Ident{Name: conv}, Args: []ast.Expr{arg}}
+	}
+	repl := &ast.CallExpr{
+		Fun:  edit.Selector(name, fun),
+		Args: append([]ast.Expr{arg}, extra...),
+	}
+	edits := []analysis.TextEdit{edit.ReplaceWithNode(pass.Fset, call, repl)}
+
+	fmtUnused := usesOf(pass, f, fmtName) == 1
+	switch {
+	case strconvSpec != nil && !fmtUnused:
+	case strconvSpec == nil && fmtUnused && fmtSpec.Name == nil:
+		// Import strconv instead of fmt
+		edits = append(edits, edit.ReplaceWithString(fmtSpec.Path, strconv.Quote("strconv")))
+	case strconvSpec == nil && !fmtUnused:
+		edits = append(edits, addImport(pass, f, fmtSpec))
+	default:
+		// We'd have to delete the import of fmt.
+		return nil, false
+	}
+	return edits, true
+}
+
+// pkgName returns the package name that call's function is qualified
+// with.
+func pkgName(pass *analysis.Pass, call *ast.CallExpr) (*types.PkgName, bool) {
+	sel, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	pn, ok := pass.TypesInfo.Uses[id].(*types.PkgName)
+	return pn, ok
+}
+
+// importSpec returns the import spec in f that declares pn.
+func importSpec(pass *analysis.Pass, f *ast.File, pn *types.PkgName) *ast.ImportSpec {
+	for _, spec := range f.Imports {
+		if importedName(pass, spec) == pn {
+			return spec
+		}
+	}
+	return nil
+}
+
+// importedName returns the package name declared by spec.
+func importedName(pass *analysis.Pass, spec *ast.ImportSpec) types.Object {
+	if spec.Name != nil {
+		return pass.TypesInfo.Defs[spec.Name]
+	}
+	return pass.TypesInfo.Implicits[spec]
+}
+
+// usesOf returns the number of uses of pn in f.
+func usesOf(pass *analysis.Pass, f *ast.File, pn *types.PkgName) int {
+	n := 0
+	ast.Inspect(f, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == pn {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// addImport returns an edit that adds an import of strconv to the
+// group of imports that contains spec, keeping the group sorted if it
+// is.
+func addImport(pass *analysis.Pass, f *ast.File, spec *ast.ImportSpec) analysis.TextEdit {
+	tf := pass.Fset.File(spec.Pos())
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		i := slices.Index(decl.Specs, ast.Spec(spec))
+		if i == -1 {
+			continue
+		}
+		if !decl.Lparen.IsValid() {
+			return edit.InsertAfter(decl, "\nimport \"strconv\"")
+		}
+		// Find the group of spec, which ends at the first blank line.
+		start, end := i, i+1
+		for start > 0 && tf.Line(decl.Specs[start-1].End())+1 >= tf.Line(decl.Specs[start].Pos()) {
+			start--
+		}
+		for end < len(decl.Specs) && tf.Line(decl.Specs[end-1].End())+1 >= tf.Line(decl.Specs[end].Pos()) {
+			end++
+		}
+		for _, s := range decl.Specs[start:end] {
+			if s.(*ast.ImportSpec).Path.Value > `"strconv"` {
+				return edit.InsertBefore(s, "\"strconv\"\n\t")
+			}
+		}
+		return edit.InsertAfter(decl.Specs[end-1], "\n\t\"strconv\"")
+	}
+	panic("unreachable: import spec not found in any import declaration")
+}
@@ -0,0 +1,15 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+
+	"go/ast"
+)
+
+func fn5(i int) {
+	_ = fmt.Sprintf("%d", i) //@ diag(`should use strconv.Itoa`)
+	fmt.Println()
+	_ = io.EOF
+	_ = ast.Bad
+}
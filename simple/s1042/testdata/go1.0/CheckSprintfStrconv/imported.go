@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func fn2(i int) {
+	_ = fmt.Sprintf("%d", i) //@ diag(`should use strconv.Itoa`)
+	_ = strconv.Itoa(i)
+}
+
+func fn3(i int) {
+	strconv := 1
+	_ = fmt.Sprintf("%d", i) //@ diag(`should use strconv.Itoa`)
+	_ = strconv
+}
@@ -0,0 +1,7 @@
+package pkg
+
+import "fmt"
+
+func fn4(i int) string {
+	return fmt.Sprintf("%d", i) //@ diag(`should use strconv.Itoa`)
+}
@@ -0,0 +1,11 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+func fn6(b bool) {
+	_ = fmt.Sprintf("%t", b) //@ diag(`should use strconv.FormatBool`)
+	fmt.Println(time.Now())
+}
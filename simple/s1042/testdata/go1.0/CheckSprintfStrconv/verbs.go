@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type myInt int
+
+func fn1(i int, i8 int8, i64 int64, u uint, u64 uint64, b bool, s string, m myInt, bs []byte) {
+	_ = fmt.Sprintf("%d", i)   //@ diag(`should use strconv.Itoa instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%v", i)   //@ diag(`should use strconv.Itoa instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%d", i8)  //@ diag(`should use strconv.FormatInt instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%d", i64) //@ diag(`should use strconv.FormatInt instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%d", u)   //@ diag(`should use strconv.FormatUint instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%d", u64) //@ diag(`should use strconv.FormatUint instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%t", b)   //@ diag(`should use strconv.FormatBool instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%q", s)   //@ diag(`should use strconv.Quote instead of fmt.Sprintf`)
+	_ = fmt.Sprintf("%v", s)   //@ diag(`the argument is already a string`)
+	_ = fmt.Sprintf("%d", 5)   //@ diag(`should use strconv.Itoa instead of fmt.Sprintf`)
+
+	// Not flagged
+	_ = fmt.Sprintf("%s", s) // S1025
+	_ = fmt.Sprintf("%d", m)
+	_ = fmt.Sprintf("%q", i)
+	_ = fmt.Sprintf("%x", i)
+	_ = fmt.Sprintf("%5d", i)
+	_ = fmt.Sprintf("%d %d", i, i)
+	_ = fmt.Sprintf("%d-%s", i, s)
+	_ = fmt.Sprintf("%q", bs)
+	_ = fmt.Sprint(i)
+	_ = os.Args
+	_ = strconv.Itoa
+}
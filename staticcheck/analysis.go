@@ -35,10 +35,22 @@ import (
 	"honnef.co/go/tools/staticcheck/sa1030"
 	"honnef.co/go/tools/staticcheck/sa1031"
 	"honnef.co/go/tools/staticcheck/sa1032"
+	"honnef.co/go/tools/staticcheck/sa1033"
+	"honnef.co/go/tools/staticcheck/sa1034"
+	"honnef.co/go/tools/staticcheck/sa1035"
+	"honnef.co/go/tools/staticcheck/sa1036"
+	"honnef.co/go/tools/staticcheck/sa1037"
+	"honnef.co/go/tools/staticcheck/sa1038"
+	"honnef.co/go/tools/staticcheck/sa1039"
+	"honnef.co/go/tools/staticcheck/sa1040"
+	"honnef.co/go/tools/staticcheck/sa1041"
 	"honnef.co/go/tools/staticcheck/sa2000"
 	"honnef.co/go/tools/staticcheck/sa2001"
 	"honnef.co/go/tools/staticcheck/sa2002"
 	"honnef.co/go/tools/staticcheck/sa2003"
+	"honnef.co/go/tools/staticcheck/sa2004"
+	"honnef.co/go/tools/staticcheck/sa2005"
+	"honnef.co/go/tools/staticcheck/sa2006"
 	"honnef.co/go/tools/staticcheck/sa3000"
 	"honnef.co/go/tools/staticcheck/sa3001"
 	"honnef.co/go/tools/staticcheck/sa4000"
@@ -72,6 +84,11 @@ import (
 	"honnef.co/go/tools/staticcheck/sa4030"
 	"honnef.co/go/tools/staticcheck/sa4031"
 	"honnef.co/go/tools/staticcheck/sa4032"
+	"honnef.co/go/tools/staticcheck/sa4033"
+	"honnef.co/go/tools/staticcheck/sa4034"
+	"honnef.co/go/tools/staticcheck/sa4035"
+	"honnef.co/go/tools/staticcheck/sa4036"
+	"honnef.co/go/tools/staticcheck/sa4037"
 	"honnef.co/go/tools/staticcheck/sa5000"
 	"honnef.co/go/tools/staticcheck/sa5001"
 	"honnef.co/go/tools/staticcheck/sa5002"
@@ -84,12 +101,15 @@ import (
 	"honnef.co/go/tools/staticcheck/sa5010"
 	"honnef.co/go/tools/staticcheck/sa5011"
 	"honnef.co/go/tools/staticcheck/sa5012"
+	"honnef.co/go/tools/staticcheck/sa5013"
 	"honnef.co/go/tools/staticcheck/sa6000"
 	"honnef.co/go/tools/staticcheck/sa6001"
 	"honnef.co/go/tools/staticcheck/sa6002"
 	"honnef.co/go/tools/staticcheck/sa6003"
 	"honnef.co/go/tools/staticcheck/sa6005"
 	"honnef.co/go/tools/staticcheck/sa6006"
+	"honnef.co/go/tools/staticcheck/sa6007"
+	"honnef.co/go/tools/staticcheck/sa6008"
 	"honnef.co/go/tools/staticcheck/sa9001"
 	"honnef.co/go/tools/staticcheck/sa9002"
 	"honnef.co/go/tools/staticcheck/sa9003"
@@ -99,6 +119,12 @@ import (
 	"honnef.co/go/tools/staticcheck/sa9007"
 	"honnef.co/go/tools/staticcheck/sa9008"
 	"honnef.co/go/tools/staticcheck/sa9009"
+	"honnef.co/go/tools/staticcheck/sa9010"
+	"honnef.co/go/tools/staticcheck/sa9011"
+	"honnef.co/go/tools/staticcheck/sa9012"
+	"honnef.co/go/tools/staticcheck/sa9013"
+	"honnef.co/go/tools/staticcheck/sa9014"
+	"honnef.co/go/tools/staticcheck/sa9015"
 )
 
 var Analyzers = []*lint.Analyzer{
@@ -133,10 +159,22 @@ var Analyzers = []*lint.Analyzer{
 	sa1030.SCAnalyzer,
 	sa1031.SCAnalyzer,
 	sa1032.SCAnalyzer,
+	sa1033.SCAnalyzer,
+	sa1034.SCAnalyzer,
+	sa1035.SCAnalyzer,
+	sa1036.SCAnalyzer,
+	sa1037.SCAnalyzer,
+	sa1038.SCAnalyzer,
+	sa1039.SCAnalyzer,
+	sa1040.SCAnalyzer,
+	sa1041.SCAnalyzer,
 	sa2000.SCAnalyzer,
 	sa2001.SCAnalyzer,
 	sa2002.SCAnalyzer,
 	sa2003.SCAnalyzer,
+	sa2004.SCAnalyzer,
+	sa2005.SCAnalyzer,
+	sa2006.SCAnalyzer,
 	sa3000.SCAnalyzer,
 	sa3001.SCAnalyzer,
 	sa4000.SCAnalyzer,
@@ -170,6 +208,11 @@ var Analyzers = []*lint.Analyzer{
 	sa4030.SCAnalyzer,
 	sa4031.SCAnalyzer,
 	sa4032.SCAnalyzer,
+	sa4033.SCAnalyzer,
+	sa4034.SCAnalyzer,
+	sa4035.SCAnalyzer,
+	sa4036.SCAnalyzer,
+	sa4037.SCAnalyzer,
 	sa5000.SCAnalyzer,
 	sa5001.SCAnalyzer,
 	sa5002.SCAnalyzer,
@@ -182,12 +225,15 @@ var Analyzers = []*lint.Analyzer{
 	sa5010.SCAnalyzer,
 	sa5011.SCAnalyzer,
 	sa5012.SCAnalyzer,
+	sa5013.SCAnalyzer,
 	sa6000.SCAnalyzer,
 	sa6001.SCAnalyzer,
 	sa6002.SCAnalyzer,
 	sa6003.SCAnalyzer,
 	sa6005.SCAnalyzer,
 	sa6006.SCAnalyzer,
+	sa6007.SCAnalyzer,
+	sa6008.SCAnalyzer,
 	sa9001.SCAnalyzer,
 	sa9002.SCAnalyzer,
 	sa9003.SCAnalyzer,
@@ -197,4 +243,10 @@ var Analyzers = []*lint.Analyzer{
 	sa9007.SCAnalyzer,
 	sa9008.SCAnalyzer,
 	sa9009.SCAnalyzer,
+	sa9010.SCAnalyzer,
+	sa9011.SCAnalyzer,
+	sa9012.SCAnalyzer,
+	sa9013.SCAnalyzer,
+	sa9014.SCAnalyzer,
+	sa9015.SCAnalyzer,
 }
@@ -26,6 +26,9 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 		Since:    "2017.1",
 		Severity: lint.SeverityWarning,
 		MergeIf:  lint.MergeIfAny,
+		// Choosing between context.TODO and context.Background
+		// requires knowing the code's intent.
+		SafeFixes: false,
 	},
 })
 
@@ -2,10 +2,14 @@ package sa1018
 
 import (
 	"fmt"
+	"go/ast"
 	"go/constant"
 
 	"honnef.co/go/tools/analysis/callcheck"
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/go/ir"
 	"honnef.co/go/tools/internal/passes/buildir"
 
@@ -21,7 +25,10 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 	Doc: &lint.RawDocumentation{
 		Title: `\'strings.Replace\' called with \'n == 0\', which does nothing`,
 		Text: `With \'n == 0\', zero instances will be replaced. To replace all
-instances, use a negative number, or use \'strings.ReplaceAll\'.`,
+instances, use a negative number, or use \'strings.ReplaceAll\'.
+
+If \'n\' is the literal \'0\', a fix replacing it with \'-1\' is
+offered.`,
 		Since:    "2017.1",
 		Severity: lint.SeverityWarning,
 		MergeIf:  lint.MergeIfAny, // MergeIfAny if we only flag literals, not named constants
@@ -35,13 +42,40 @@ var rules = map[string]callcheck.Check{
 	"bytes.Replace":   check("bytes.Replace", 3),
 }
 
-func check(name string, arg int) callcheck.Check {
+func check(name string, idx int) callcheck.Check {
 	return func(call *callcheck.Call) {
-		arg := call.Args[arg]
+		arg := call.Args[idx]
 		if k, ok := arg.Value.Value.(*ir.Const); ok && k.Value.Kind() == constant.Int {
 			if v, ok := constant.Int64Val(k.Value); ok && v == 0 {
-				arg.Invalid(fmt.Sprintf("calling %s with n == 0 will return no results, did you mean -1?", name))
+				var opts []report.Option
+				if lit := zeroLiteral(call, idx); lit != nil {
+					fix := edit.Fix("replace all instances", edit.ReplaceWithString(lit, "-1"))
+					opts = append(opts, report.Fixes(fix))
+				}
+				arg.Invalid(fmt.Sprintf("calling %s with n == 0 will return no results, did you mean -1?", name), opts...)
 			}
 		}
 	}
 }
+
+// zeroLiteral returns the idx'th argument of call if it is the integer
+// literal 0. Named constants and other constant expressions may be
+// used elsewhere, so we don't offer to change them.
+func zeroLiteral(call *callcheck.Call, idx int) ast.Expr {
+	var astcall *ast.CallExpr
+	switch source := call.Instr.Source().(type) {
+	case *ast.CallExpr:
+		astcall = source
+	case *ast.DeferStmt:
+		astcall = source.Call
+	case *ast.GoStmt:
+		astcall = source.Call
+	}
+	if astcall == nil || idx >= len(astcall.Args) {
+		return nil
+	}
+	if !code.IsIntegerLiteral(call.Pass, astcall.Args[idx], constant.MakeInt64(0)) {
+		return nil
+	}
+	return astcall.Args[idx]
+}
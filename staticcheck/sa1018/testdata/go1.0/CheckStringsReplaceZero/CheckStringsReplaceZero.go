@@ -1,9 +1,20 @@
 package pkg
 
-import "strings"
+import (
+	"bytes"
+	"strings"
+)
 
-func fn() {
+const zero = 0
+
+func fn(n int) {
 	_ = strings.Replace("", "", "", 0) //@ diag(`calling strings.Replace with n == 0`)
 	_ = strings.Replace("", "", "", -1)
 	_ = strings.Replace("", "", "", 1)
+	_ = bytes.Replace(nil, nil, nil, 0) //@ diag(`calling bytes.Replace with n == 0`)
+
+	// Named constants are flagged, but not rewritten
+	_ = strings.Replace("", "", "", zero) //@ diag(`calling strings.Replace with n == 0`)
+
+	_ = strings.Replace("", "", "", n)
 }
@@ -5,9 +5,11 @@ import (
 	"go/ast"
 	"go/types"
 	"go/version"
+	"regexp"
 	"strings"
 
 	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/facts/deprecated"
 	"honnef.co/go/tools/analysis/facts/generated"
 	"honnef.co/go/tools/analysis/lint"
@@ -39,6 +41,82 @@ func formatGoVersion(s string) string {
 	return "Go " + strings.TrimPrefix(s, "go")
 }
 
+// deprecatedReplacement matches deprecation notices that name a
+// direct replacement, such as "Use NewFoo instead" or "use
+// pkg.NewFoo() instead".
+var deprecatedReplacement = regexp.MustCompile(`(?i)\buse\s+([a-z_]\w*(?:\.[a-z_]\w*)?)(?:\(\))?\s+instead\b`)
+
+// replacementFix returns a fix that replaces the use of the deprecated
+// function or method fn in sel with the replacement named by the
+// deprecation notice. To stay on the safe side, we only offer fixes
+// for replacements that are declared in the same package or on the
+// same type as fn and that have an identical signature, so that
+// renaming the selector is all that is needed.
+func replacementFix(pass *analysis.Pass, deprs deprecated.Result, sel *ast.SelectorExpr, fn *types.Func, msg string) (analysis.SuggestedFix, bool) {
+	ms := deprecatedReplacement.FindAllStringSubmatch(msg, -1)
+	if len(ms) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	name := ms[0][1]
+	qual, unqual, qualified := strings.Cut(name, ".")
+	if qualified {
+		name = unqual
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if sig.TypeParams().Len() != 0 || sig.RecvTypeParams().Len() != 0 {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var repl types.Object
+	if recv := sig.Recv(); recv != nil {
+		if qualified {
+			// The qualifier has to be the name of the receiver's type.
+			T := recv.Type()
+			if ptr, ok := T.(*types.Pointer); ok {
+				T = ptr.Elem()
+			}
+			named, ok := types.Unalias(T).(*types.Named)
+			if !ok || named.Obj().Name() != qual {
+				return analysis.SuggestedFix{}, false
+			}
+		}
+		// Look up the replacement on the type at the use site, not
+		// on fn's receiver, to make sure that the method we'd end up
+		// calling is the one we expect.
+		selection, ok := pass.TypesInfo.Selections[sel]
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		repl, _, _ = types.LookupFieldOrMethod(selection.Recv(), true, pass.Pkg, name)
+		rfn, ok := repl.(*types.Func)
+		if !ok || !types.Identical(rfn.Type().(*types.Signature).Recv().Type(), recv.Type()) {
+			return analysis.SuggestedFix{}, false
+		}
+	} else {
+		if qualified && qual != fn.Pkg().Name() {
+			return analysis.SuggestedFix{}, false
+		}
+		repl = fn.Pkg().Scope().Lookup(name)
+	}
+
+	rfn, ok := repl.(*types.Func)
+	if !ok || rfn == fn || !rfn.Exported() {
+		return analysis.SuggestedFix{}, false
+	}
+	if _, ok := deprs.Objects[rfn]; ok {
+		return analysis.SuggestedFix{}, false
+	}
+	rsig := rfn.Type().(*types.Signature)
+	if rsig.TypeParams().Len() != 0 ||
+		sig.Variadic() != rsig.Variadic() ||
+		!types.Identical(sig.Params(), rsig.Params()) ||
+		!types.Identical(sig.Results(), rsig.Results()) {
+		return analysis.SuggestedFix{}, false
+	}
+	return edit.Fix(fmt.Sprintf("use %s instead", rfn.Name()), edit.ReplaceWithString(sel.Sel, rfn.Name())), true
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	deprs := pass.ResultOf[deprecated.Analyzer].(deprecated.Result)
 
@@ -55,7 +133,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		return !strings.Contains(path, ".")
 	}
 
-	handleDeprecation := func(depr *deprecated.IsDeprecated, node ast.Node, deprecatedObjName string, pkgPath string, tfn types.Object) {
+	handleDeprecation := func(depr *deprecated.IsDeprecated, node ast.Node, deprecatedObjName string, pkgPath string, tfn types.Object, opts ...report.Option) {
 		std, ok := knowledge.StdlibDeprecations[deprecatedObjName]
 		if !ok && isStdlibPath(pkgPath) {
 			// Deprecated object in the standard library, but we don't know the details of the deprecation.
@@ -99,18 +177,18 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			case knowledge.DeprecatedNeverUse:
 				report.Report(pass, node,
 					fmt.Sprintf("%s has been deprecated since %s because it shouldn't be used: %s",
-						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), depr.Msg))
+						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), depr.Msg), opts...)
 			case std.DeprecatedSince, knowledge.DeprecatedUseNoLonger:
 				report.Report(pass, node,
 					fmt.Sprintf("%s has been deprecated since %s: %s",
-						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), depr.Msg))
+						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), depr.Msg), opts...)
 			default:
 				report.Report(pass, node,
 					fmt.Sprintf("%s has been deprecated since %s and an alternative has been available since %s: %s",
-						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), formatGoVersion(std.AlternativeAvailableSince), depr.Msg))
+						report.Render(pass, node), formatGoVersion(std.DeprecatedSince), formatGoVersion(std.AlternativeAvailableSince), depr.Msg), opts...)
 			}
 		} else {
-			report.Report(pass, node, fmt.Sprintf("%s is deprecated: %s", report.Render(pass, node), depr.Msg))
+			report.Report(pass, node, fmt.Sprintf("%s is deprecated: %s", report.Render(pass, node), depr.Msg), opts...)
 		}
 	}
 
@@ -166,7 +244,13 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		if depr, ok := deprs.Objects[obj]; ok {
-			handleDeprecation(depr, sel, code.SelectorName(pass, sel), obj.Pkg().Path(), tfn)
+			var opts []report.Option
+			if fn, ok := obj.(*types.Func); ok {
+				if fix, ok := replacementFix(pass, deprs, sel, fn, depr.Msg); ok {
+					opts = append(opts, report.Fixes(fix))
+				}
+			}
+			handleDeprecation(depr, sel, code.SelectorName(pass, sel), obj.Pkg().Path(), tfn, opts...)
 		}
 		return true
 	}
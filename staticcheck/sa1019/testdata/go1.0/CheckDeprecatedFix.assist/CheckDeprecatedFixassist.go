@@ -0,0 +1,35 @@
+package dep
+
+// Deprecated: Use NewFn instead.
+func OldFn(x int) string { return "" }
+
+// Deprecated: use dep.NewFn() instead.
+func QualifiedFn(x int) string { return "" }
+
+func NewFn(x int) string { return "" }
+
+// Deprecated: Use NewFn2 instead.
+func DifferentArity(x int) string { return "" }
+
+func NewFn2(x, y int) string { return "" }
+
+// Deprecated: Use the standard library instead.
+func Vague() {}
+
+// Deprecated: Use other.Fn instead.
+func OtherPackage() {}
+
+// Deprecated: Use Missing instead.
+func Dangling() {}
+
+type T struct{}
+
+// Deprecated: Use T.NewMethod instead.
+func (T) OldMethod() {}
+
+func (T) NewMethod() {}
+
+// Deprecated: Use NewPtrMethod instead.
+func (*T) OldPtrMethod(s string) {}
+
+func (T) NewPtrMethod(s string) {}
@@ -0,0 +1,18 @@
+package pkg
+
+import "example.com/CheckDeprecatedFix.assist"
+
+func fn() {
+	_ = dep.OldFn(1)          //@ diag(`Use NewFn instead`)
+	_ = dep.QualifiedFn(1)    //@ diag(`use dep.NewFn() instead`)
+	_ = dep.DifferentArity(1) //@ diag(`Use NewFn2 instead`)
+	dep.Vague()               //@ diag(`Use the standard library instead`)
+	dep.OtherPackage()        //@ diag(`Use other.Fn instead`)
+	dep.Dangling()            //@ diag(`Use Missing instead`)
+
+	var t dep.T
+	t.OldMethod()      //@ diag(`Use T.NewMethod instead`)
+	t.OldPtrMethod("") //@ diag(`Use NewPtrMethod instead`)
+	f := dep.OldFn     //@ diag(`Use NewFn instead`)
+	_ = f
+}
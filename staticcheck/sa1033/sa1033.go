@@ -0,0 +1,165 @@
+package sa1033
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1033",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Missing return after writing an error response in an HTTP handler`,
+		Text: `
+Calling \'http.Error\' or \'WriteHeader\' with an error status code
+doesn't stop the execution of an HTTP handler. Without a subsequent
+return, the handler continues and will likely write a second response,
+which results in a "superfluous response.WriteHeader call" warning
+and a corrupted response body:
+
+    func handler(w http.ResponseWriter, r *http.Request) {
+        if err := r.ParseForm(); err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            // missing return
+        }
+        fmt.Fprintln(w, "ok")
+    }
+
+SA1036 flags calls of 'http.Error' that are followed by further
+writes more precisely; this check yields to it.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+		YieldsTo: []string{"SA1036"},
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var (
+	httpErrorQ   = pattern.MustParse(`(CallExpr (Symbol "net/http.Error") w:_)`)
+	writeHeaderQ = pattern.MustParse(`(CallExpr (SelectorExpr recv (Ident "WriteHeader")) [code])`)
+)
+
+func run(pass *analysis.Pass) (any, error) {
+	fn := func(node ast.Node) {
+		var typ *ast.FuncType
+		var body *ast.BlockStmt
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			typ, body = node.Type, node.Body
+		case *ast.FuncLit:
+			typ, body = node.Type, node.Body
+		}
+		if body == nil {
+			return
+		}
+		w, ok := responseWriterParam(pass, typ)
+		if !ok {
+			return
+		}
+
+		// Only look at if statements in the outermost block of the
+		// handler. Anything that follows them is guaranteed to run
+		// unless the if statement's body terminates.
+		for i, stmt := range body.List {
+			if i == len(body.List)-1 {
+				break
+			}
+			ifstmt, ok := stmt.(*ast.IfStmt)
+			if !ok || ifstmt.Else != nil || len(ifstmt.Body.List) == 0 {
+				continue
+			}
+			last, ok := ifstmt.Body.List[len(ifstmt.Body.List)-1].(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			call, ok := last.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if !writesError(pass, call, w) {
+				continue
+			}
+			if code.IsCallTo(pass, call, "net/http.Error") {
+				report.Report(pass, call, "missing return after http.Error")
+			} else {
+				report.Report(pass, call, "missing return after writing an error response, the handler will continue and likely write a second response")
+			}
+		}
+	}
+	code.Preorder(pass, fn, (*ast.FuncDecl)(nil), (*ast.FuncLit)(nil))
+	return nil, nil
+}
+
+// responseWriterParam returns the http.ResponseWriter parameter of a
+// function with the shape of an http.HandlerFunc.
+func responseWriterParam(pass *analysis.Pass, typ *ast.FuncType) (types.Object, bool) {
+	if typ.Results != nil && len(typ.Results.List) != 0 {
+		return nil, false
+	}
+	if typ.Params == nil || typ.Params.NumFields() != 2 {
+		return nil, false
+	}
+	var names []*ast.Ident
+	for _, field := range typ.Params.List {
+		if len(field.Names) == 0 {
+			// Unnamed parameters can't be passed to anything.
+			return nil, false
+		}
+		names = append(names, field.Names...)
+	}
+	w := pass.TypesInfo.Defs[names[0]]
+	r := pass.TypesInfo.Defs[names[1]]
+	if w == nil || r == nil {
+		return nil, false
+	}
+	if !typeutil.IsTypeWithName(w.Type(), "net/http.ResponseWriter") ||
+		!typeutil.IsPointerToTypeWithName(r.Type(), "net/http.Request") {
+		return nil, false
+	}
+	return w, true
+}
+
+// writesError reports whether call writes an error response to w,
+// either via http.Error or via WriteHeader with a status code of 400
+// or higher.
+func writesError(pass *analysis.Pass, call *ast.CallExpr, w types.Object) bool {
+	if m, ok := code.Match(pass, httpErrorQ, call); ok {
+		return refersTo(pass, m.State["w"].(ast.Expr), w)
+	}
+	if m, ok := code.Match(pass, writeHeaderQ, call); ok {
+		// w is known to be an http.ResponseWriter, so there is no
+		// need to resolve the method itself.
+		if !refersTo(pass, m.State["recv"].(ast.Expr), w) {
+			return false
+		}
+		tv := pass.TypesInfo.Types[m.State["code"].(ast.Expr)]
+		if tv.Value == nil {
+			return false
+		}
+		n, ok := constant.Int64Val(constant.ToInt(tv.Value))
+		return ok && n >= 400
+	}
+	return false
+}
+
+// refersTo reports whether expr is an identifier referring to obj.
+func refersTo(pass *analysis.Pass, expr ast.Expr, obj types.Object) bool {
+	ident, ok := astutil.Unparen(expr).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[ident] == obj
+}
@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func fn1(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed) //@ diag(`missing return after http.Error`)
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn3(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest) //@ diag(`missing return`)
+	}
+	if r.URL == nil {
+		w.WriteHeader(500) //@ diag(`missing return`)
+	}
+	if r.Method == "HEAD" {
+		// Not an error status
+		w.WriteHeader(http.StatusOK)
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn4(w http.ResponseWriter, r *http.Request) {
+	// The last statement of the handler; nothing follows.
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func fn5(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	} else {
+		fmt.Fprintln(w, "ok")
+	}
+	println()
+}
+
+func fn6() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed) //@ diag(`missing return after http.Error`)
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// Not a handler
+func fn7(w http.ResponseWriter, code int) {
+	if code != 200 {
+		http.Error(w, "error", code)
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn8(w http.ResponseWriter, r *http.Request, other http.ResponseWriter) {
+	if r.Method != "GET" {
+		http.Error(other, "method not allowed", http.StatusMethodNotAllowed)
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn9(w http.ResponseWriter, r *http.Request) {
+	other := w
+	if r.Method != "GET" {
+		http.Error(other, "method not allowed", http.StatusMethodNotAllowed)
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func fn10(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed) //@ diag(`missing return after http.Error`)
+	}
+	// Doesn't write to w, but still runs after the error response.
+	fmt.Println("done")
+}
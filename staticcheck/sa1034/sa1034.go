@@ -0,0 +1,84 @@
+package sa1034
+
+import (
+	"fmt"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/callcheck"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1034",
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+		Run:      callcheck.Analyzer(rules),
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Invalid target in call to \'errors.As\'`,
+		Text: `The second argument to \'errors.As\' must be a non-nil pointer
+to either a type that implements \'error\', or to any interface type.
+Any other value causes \'errors.As\' to panic.
+
+A common mistake is to pass a value of the error type itself instead
+of a pointer to a variable of that type:
+
+    var perr *fs.PathError
+    if errors.As(err, perr) { // should be &perr
+        ...
+    }
+
+The target of \'errors.Is\' is of type \'error\' and is already
+checked by the compiler.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityError,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var rules = map[string]callcheck.Check{
+	"errors.As": checkAsTarget,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+const invalidTargetMsg = "second argument to errors.As must be a non-nil pointer to either a type that implements error, or to any interface type"
+
+func checkAsTarget(call *callcheck.Call) {
+	arg := call.Args[1]
+	v := arg.Value.Value
+	if k, ok := v.(*ir.Const); ok && k.IsNil() {
+		arg.Invalid(invalidTargetMsg)
+		return
+	}
+
+	T := v.Type()
+	if _, ok := T.Underlying().(*types.Interface); ok {
+		// Includes type parameters; we can't tell what the dynamic
+		// type will be.
+		return
+	}
+	ptr, ok := T.Underlying().(*types.Pointer)
+	if !ok {
+		arg.Invalid(fmt.Sprintf("%s, but got %s", invalidTargetMsg, types.TypeString(T, types.RelativeTo(call.Pass.Pkg))))
+		return
+	}
+	elem := ptr.Elem()
+	if _, ok := elem.Underlying().(*types.Interface); ok {
+		return
+	}
+	if types.Implements(elem, errorType) {
+		return
+	}
+	if types.Implements(T, errorType) {
+		arg.Invalid(fmt.Sprintf("%s; %s implements error, pass a pointer to a variable of that type instead", invalidTargetMsg, types.TypeString(T, types.RelativeTo(call.Pass.Pkg))))
+		return
+	}
+	arg.Invalid(fmt.Sprintf("%s, but %s does not implement error", invalidTargetMsg, types.TypeString(elem, types.RelativeTo(call.Pass.Pkg))))
+}
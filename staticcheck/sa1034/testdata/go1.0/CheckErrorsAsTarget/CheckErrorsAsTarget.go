@@ -0,0 +1,37 @@
+package pkg
+
+import "errors"
+
+type ptrErr struct{}
+
+func (*ptrErr) Error() string { return "" }
+
+type valErr struct{}
+
+func (valErr) Error() string { return "" }
+
+type iface interface{ Foo() }
+
+func fn(err error, target interface{}) {
+	var p *ptrErr
+	var v valErr
+	var pv *valErr
+	var i iface
+	var e error
+	var s string
+	var n int
+
+	errors.As(err, &p)
+	errors.As(err, &v)
+	errors.As(err, &pv)
+	errors.As(err, &i)
+	errors.As(err, &e)
+	errors.As(err, target)
+
+	errors.As(err, p)           //@ diag(`*ptrErr implements error, pass a pointer to a variable of that type instead`)
+	errors.As(err, v)           //@ diag(`but got valErr`)
+	errors.As(err, &s)          //@ diag(`but string does not implement error`)
+	errors.As(err, n)           //@ diag(`but got int`)
+	errors.As(err, nil)         //@ diag(`must be a non-nil pointer`)
+	errors.As(err, (*int)(nil)) //@ diag(`must be a non-nil pointer`)
+}
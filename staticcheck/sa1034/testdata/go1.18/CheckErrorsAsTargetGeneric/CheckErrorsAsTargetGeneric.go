@@ -0,0 +1,8 @@
+package pkg
+
+import "errors"
+
+func generic[T error, P *T](err error, p P, t T) {
+	errors.As(err, p)
+	errors.As(err, t)
+}
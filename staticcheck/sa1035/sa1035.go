@@ -0,0 +1,149 @@
+package sa1035
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1035",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `\'regexp.MustCompile\' called with a non-constant pattern`,
+		Text: `\'regexp.MustCompile\' and \'regexp.MustCompilePOSIX\' panic if
+the pattern is invalid. They are meant for patterns that are known to
+be valid, typically constants used to initialize global variables.
+Compiling a pattern that is computed at runtime, such as one provided
+by the user, can panic far away from where the pattern originated.
+Use \'regexp.Compile\' and handle the error instead.
+
+Calls in initializers of package-level variables and in tests aren't
+flagged, as any panic happens immediately and deterministically.
+
+If the result is assigned to a new variable in a function that only
+returns an error, a fix that returns the error is offered.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var query = pattern.MustParse(`(CallExpr (Symbol name@(Or "regexp.MustCompile" "regexp.MustCompilePOSIX")) [arg])`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node, stack []ast.Node) {
+		m, ok := code.Match(pass, query, node)
+		if !ok {
+			return
+		}
+		if pass.TypesInfo.Types[m.State["arg"].(ast.Expr)].Value != nil {
+			// This includes concatenations of constants.
+			return
+		}
+		sig := enclosingSignature(pass, stack)
+		if sig == nil {
+			// Initializer of a package-level variable
+			return
+		}
+		if code.IsInTest(pass, node) {
+			return
+		}
+
+		name := m.State["name"].(string)
+		var opts []report.Option
+		if fix, ok := returnErrorFix(pass, node.(*ast.CallExpr).Fun, stack, sig); ok {
+			opts = append(opts, report.Fixes(fix))
+		}
+		report.Report(pass, node,
+			fmt.Sprintf("%s panics if the pattern is invalid, use %s and handle the error", name, strings.Replace(name, "MustCompile", "Compile", 1)),
+			opts...)
+	}
+	code.PreorderStack(pass, fn, (*ast.CallExpr)(nil))
+	return nil, nil
+}
+
+// enclosingSignature returns the signature of the innermost function
+// in stack, or nil if there is none.
+func enclosingSignature(pass *analysis.Pass, stack []ast.Node) *types.Signature {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncDecl:
+			return pass.TypesInfo.Defs[n.Name].Type().(*types.Signature)
+		case *ast.FuncLit:
+			return pass.TypesInfo.TypeOf(n).(*types.Signature)
+		}
+	}
+	return nil
+}
+
+// returnErrorFix returns a fix that replaces 'x := regexp.MustCompile(p)'
+// with a call of regexp.Compile whose error is returned. It is only
+// offered in functions whose only result is an error, as we'd
+// otherwise have to come up with the other results.
+func returnErrorFix(pass *analysis.Pass, fun ast.Expr, stack []ast.Node, sig *types.Signature) (analysis.SuggestedFix, bool) {
+	if sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type()) {
+		return analysis.SuggestedFix{}, false
+	}
+	sel, ok := astutil.Unparen(fun).(*ast.SelectorExpr)
+	if !ok {
+		// regexp has been dot-imported
+		return analysis.SuggestedFix{}, false
+	}
+	// The call must be the right-hand side of an assignment that is a
+	// statement of its own, not part of an if, for or switch
+	// statement, so that we can insert the error check after it.
+	i := len(stack) - 2
+	for i >= 0 {
+		if _, ok := stack[i].(*ast.ParenExpr); !ok {
+			break
+		}
+		i--
+	}
+	if i < 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	stmt, ok := stack[i].(*ast.AssignStmt)
+	if !ok || stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	switch stack[i-1].(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+	default:
+		return analysis.SuggestedFix{}, false
+	}
+
+	// Insert the check at the end of the line, after any trailing
+	// comment.
+	tf := pass.Fset.File(stmt.End())
+	line := tf.Line(stmt.End())
+	if line >= tf.LineCount() {
+		return analysis.SuggestedFix{}, false
+	}
+	eol := tf.LineStart(line+1) - 1
+	indent := strings.Repeat("\t", tf.PositionFor(stmt.Pos(), false).Column-1)
+	check := fmt.Sprintf("\n%sif err != nil {\n%s\treturn err\n%s}", indent, indent, indent)
+	name := strings.Replace(sel.Sel.Name, "MustCompile", "Compile", 1)
+	return edit.Fix("use "+name+" and return the error",
+		edit.ReplaceWithString(sel.Sel, name),
+		edit.ReplaceWithString(edit.Range{stmt.Lhs[0].End(), stmt.Lhs[0].End()}, ", err"),
+		edit.ReplaceWithString(edit.Range{eol, eol}, check),
+	), true
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import "regexp"
+
+const prefix = "^foo"
+
+var global = regexp.MustCompile(prefix + userPattern())
+
+func userPattern() string { return "" }
+
+func fn1(s string) {
+	regexp.MustCompile("^foo$")
+	regexp.MustCompile(prefix + "bar$")
+	regexp.MustCompilePOSIX(prefix)
+	regexp.MustCompile(s)                   //@ diag(`regexp.MustCompile panics if the pattern is invalid, use regexp.Compile and handle the error`)
+	regexp.MustCompilePOSIX(s)              //@ diag(`regexp.MustCompilePOSIX panics if the pattern is invalid, use regexp.CompilePOSIX and handle the error`)
+	regexp.MustCompile(prefix + s)          //@ diag(`regexp.MustCompile panics`)
+	regexp.MustCompile(regexp.QuoteMeta(s)) //@ diag(`regexp.MustCompile panics`)
+	_, _ = regexp.Compile(s)
+}
+
+func fn2(s string) error {
+	re := regexp.MustCompile(s) //@ diag(`regexp.MustCompile panics`)
+	_ = re
+	switch {
+	case s != "":
+		re := regexp.MustCompilePOSIX(s) //@ diag(`regexp.MustCompilePOSIX panics`)
+		_ = re
+	}
+	if re := regexp.MustCompile(s); re != nil { //@ diag(`regexp.MustCompile panics`)
+	}
+	f := func() {
+		re := regexp.MustCompile(s) //@ diag(`regexp.MustCompile panics`)
+		_ = re
+	}
+	f()
+	return nil
+}
+
+func fn3(s string) (*regexp.Regexp, error) {
+	re := regexp.MustCompile(s) //@ diag(`regexp.MustCompile panics`)
+	return re, nil
+}
@@ -0,0 +1,189 @@
+package sa1036
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1036",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Missing return after \'http.Error\'`,
+		Text: `\'http.Error\' writes an error response, but it doesn't stop the
+handler. Code that follows it keeps running and may write more data to
+the response, which ends up appended to the error message. Usually,
+the call should be followed by a return.
+
+To avoid false positives, this check only flags calls of \'http.Error\'
+that are followed by code writing to the same \'http.ResponseWriter\',
+without a return in between.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+// writers are functions that write to the http.ResponseWriter passed
+// as their first argument.
+var writers = []string{
+	"fmt.Fprint",
+	"fmt.Fprintf",
+	"fmt.Fprintln",
+	"io.Copy",
+	"io.WriteString",
+	"encoding/json.NewEncoder",
+	"net/http.Error",
+	"net/http.NotFound",
+	"net/http.Redirect",
+	"net/http.ServeContent",
+	"net/http.ServeFile",
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		var body *ast.BlockStmt
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			body = node.Body
+		case *ast.FuncLit:
+			body = node.Body
+		}
+		if body == nil {
+			return
+		}
+		checkStmts(pass, body.List, nil)
+	}
+	code.Preorder(pass, fn, (*ast.FuncDecl)(nil), (*ast.FuncLit)(nil))
+	return nil, nil
+}
+
+// checkStmts checks a list of statements for calls of http.Error.
+// conts holds the statements that execute after stmts completes
+// normally, innermost first.
+func checkStmts(pass *analysis.Pass, stmts []ast.Stmt, conts [][]ast.Stmt) {
+	for i, stmt := range stmts {
+		// Statements that run after stmt, if it completes normally.
+		after := make([][]ast.Stmt, 0, len(conts)+1)
+		after = append(after, stmts[i+1:])
+		after = append(after, conts...)
+
+		switch stmt := stmt.(type) {
+		case *ast.ExprStmt:
+			call, ok := stmt.X.(*ast.CallExpr)
+			if !ok || !code.IsCallTo(pass, call, "net/http.Error") || len(call.Args) == 0 {
+				continue
+			}
+			w, ok := responseWriter(pass, call.Args[0])
+			if !ok {
+				continue
+			}
+			if writesAfter(pass, w, after) {
+				report.Report(pass, call, "missing return after http.Error")
+			}
+		case *ast.BlockStmt:
+			checkStmts(pass, stmt.List, after)
+		case *ast.LabeledStmt:
+			checkStmts(pass, []ast.Stmt{stmt.Stmt}, after)
+		case *ast.IfStmt:
+			checkStmts(pass, stmt.Body.List, after)
+			if stmt.Else != nil {
+				checkStmts(pass, []ast.Stmt{stmt.Else}, after)
+			}
+		case *ast.SwitchStmt:
+			checkClauses(pass, stmt.Body, after)
+		case *ast.TypeSwitchStmt:
+			checkClauses(pass, stmt.Body, after)
+		case *ast.SelectStmt:
+			checkClauses(pass, stmt.Body, after)
+		case *ast.ForStmt:
+			// Where control goes after the loop body depends on the
+			// loop, so we only look at the body itself.
+			checkStmts(pass, stmt.Body.List, nil)
+		case *ast.RangeStmt:
+			checkStmts(pass, stmt.Body.List, nil)
+		}
+	}
+}
+
+func checkClauses(pass *analysis.Pass, body *ast.BlockStmt, conts [][]ast.Stmt) {
+	for _, clause := range body.List {
+		switch clause := clause.(type) {
+		case *ast.CaseClause:
+			checkStmts(pass, clause.Body, conts)
+		case *ast.CommClause:
+			checkStmts(pass, clause.Body, conts)
+		}
+	}
+}
+
+// responseWriter returns the variable that expr refers to, if it is an
+// identifier.
+func responseWriter(pass *analysis.Pass, expr ast.Expr) (*types.Var, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	return v, ok
+}
+
+// writesAfter reports whether the statements in conts write to w before
+// control leaves them by means of a return or branch statement.
+func writesAfter(pass *analysis.Pass, w *types.Var, conts [][]ast.Stmt) bool {
+	for _, stmts := range conts {
+		for _, stmt := range stmts {
+			switch stmt.(type) {
+			case *ast.ReturnStmt, *ast.BranchStmt:
+				return false
+			}
+			if writesTo(pass, w, stmt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writesTo reports whether node contains a call that writes to w. Calls
+// in function literals are ignored, as we don't know when, if ever,
+// they run.
+func writesTo(pass *analysis.Pass, w *types.Var, node ast.Node) bool {
+	isW := func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return ok && pass.TypesInfo.ObjectOf(ident) == w
+	}
+	found := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok && isW(sel.X) {
+				switch sel.Sel.Name {
+				case "Write", "WriteHeader":
+					found = true
+				}
+			} else if len(node.Args) > 0 && isW(node.Args[0]) && code.IsCallToAny(pass, node, writers...) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func fn1(w http.ResponseWriter, r *http.Request) {
+	v, err := load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError) //@ diag(`missing return after http.Error`)
+	}
+	fmt.Fprintln(w, v)
+}
+
+func fn2(w http.ResponseWriter, r *http.Request) {
+	v, err := load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, v)
+}
+
+func fn3(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "bad method", http.StatusMethodNotAllowed) //@ diag(`missing return after http.Error`)
+		fmt.Println("bad method")
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func fn4(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "gone", http.StatusGone) //@ diag(`missing return after http.Error`)
+	json.NewEncoder(w).Encode(nil)
+}
+
+func fn5(w http.ResponseWriter, r *http.Request) {
+	v, err := load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	} else {
+		fmt.Fprintln(w, v)
+	}
+}
+
+// Helpers that only write the error are fine.
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func fn6(w http.ResponseWriter, r *http.Request) {
+	v, err := load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	// Doesn't write to w
+	fmt.Println(v)
+}
+
+func fn7(w http.ResponseWriter, r *http.Request) {
+	for _, x := range []int{1, 2} {
+		if x == 1 {
+			http.Error(w, "one", http.StatusBadRequest)
+			break
+		}
+	}
+	switch r.Method {
+	case "GET":
+		http.Error(w, "get", http.StatusBadRequest) //@ diag(`missing return after http.Error`)
+	case "POST":
+		http.Error(w, "post", http.StatusBadRequest)
+		return
+	}
+	w.Write(nil)
+}
+
+func fn8(w http.ResponseWriter, r *http.Request) {
+	v, err := load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	defer func() {
+		// Don't know when, if ever, this runs.
+		fmt.Fprintln(w, v)
+	}()
+}
+
+func load() (int, error) { return 0, nil }
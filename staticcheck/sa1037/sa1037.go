@@ -0,0 +1,71 @@
+package sa1037
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1037",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Less function of \'sort.Slice\' returns true for equal elements`,
+		Text: `The less function passed to \'sort.Slice\' and \'sort.SliceStable\'
+has to report whether the element at index i must sort before the
+element at index j. It must return false for equal elements. A less
+function that compares elements using \'<=\' or \'>=\' violates this
+contract, which can lead to incorrect and nondeterministic results.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var query = pattern.MustParse(`
+	(CallExpr
+		(Symbol (Or "sort.Slice" "sort.SliceStable"))
+		[_ (FuncLit
+			(FuncType [(Field [i j] _ _)] _)
+			[(ReturnStmt [cmp@(BinaryExpr
+				(IndexExpr s x@(Or i j))
+				(Or "<=" ">=")
+				(IndexExpr s y@(Or i j)))])])])`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		m, ok := code.Match(pass, query, node)
+		if !ok {
+			return
+		}
+		if m.State["x"].(*ast.Ident).Name == m.State["y"].(*ast.Ident).Name {
+			return
+		}
+		cmp := m.State["cmp"].(*ast.BinaryExpr)
+		op := token.LSS
+		if cmp.Op == token.GEQ {
+			op = token.GTR
+		}
+		fix := edit.Fix(fmt.Sprintf("use %s", op),
+			edit.ReplaceWithString(edit.Range{cmp.OpPos, cmp.OpPos + token.Pos(len(cmp.Op.String()))}, op.String()))
+		report.Report(pass, cmp,
+			fmt.Sprintf("less function returns true for equal elements, use %s instead of %s", op, cmp.Op),
+			report.Fixes(fix))
+	}
+	code.Preorder(pass, fn, (*ast.CallExpr)(nil))
+	return nil, nil
+}
@@ -0,0 +1,22 @@
+package pkg
+
+import "sort"
+
+type person struct {
+	name string
+	age  int
+}
+
+func fn(s []int, people []person) {
+	sort.Slice(s, func(i, j int) bool { return s[i] <= s[j] })       //@ diag(`use < instead of <=`)
+	sort.SliceStable(s, func(i, j int) bool { return s[i] >= s[j] }) //@ diag(`use > instead of >=`)
+	sort.Slice(s, func(a, b int) bool { return s[b] <= s[a] })       //@ diag(`use < instead of <=`)
+	sort.Slice(s, func(i, j int) bool {
+		return s[i] <= s[j] //@ diag(`use < instead of <=`)
+	})
+
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	sort.Slice(s, func(i, j int) bool { return s[i] > s[j] })
+	sort.SliceIsSorted(s, func(i, j int) bool { return s[i] <= s[j] })
+	sort.Slice(people, func(i, j int) bool { return people[i].age < people[j].age })
+}
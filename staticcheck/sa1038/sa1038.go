@@ -0,0 +1,110 @@
+package sa1038
+
+import (
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1038",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Copying a \'bytes.Buffer\' after writing to it`,
+		Text: `A \'bytes.Buffer\' that has been written to refers to a backing
+array. Copying the buffer copies this reference, so the original and
+the copy share the array, and writing to one of them can overwrite the
+contents of the other. Once a buffer is in use, it should only be
+passed around by pointer.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+// writers are the methods of bytes.Buffer that make it refer to a
+// backing array.
+var writers = []string{
+	"(*bytes.Buffer).Grow",
+	"(*bytes.Buffer).ReadFrom",
+	"(*bytes.Buffer).Write",
+	"(*bytes.Buffer).WriteByte",
+	"(*bytes.Buffer).WriteRune",
+	"(*bytes.Buffer).WriteString",
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				alloc, ok := instr.(*ir.Alloc)
+				if !ok || !typeutil.IsPointerToTypeWithName(alloc.Type(), "bytes.Buffer") {
+					continue
+				}
+				checkAlloc(pass, alloc)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// checkAlloc flags loads of the buffer alloc, which copy the buffer,
+// that happen after it has been written to.
+func checkAlloc(pass *analysis.Pass, alloc *ir.Alloc) {
+	var writes []*ir.Call
+	var loads []*ir.Load
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *ir.Call:
+			args := ref.Common().Args
+			if len(args) > 0 && args[0] == ir.Value(alloc) && irutil.IsCallToAny(ref.Common(), writers...) {
+				writes = append(writes, ref)
+			}
+		case *ir.Load:
+			loads = append(loads, ref)
+		}
+	}
+	if len(writes) == 0 {
+		return
+	}
+	r := alloc.Parent().Reachability()
+	for _, load := range loads {
+		for _, write := range writes {
+			if reaches(r, write, load) {
+				report.Report(pass, load, "bytes.Buffer is copied after it has been written to, the copy shares the original's backing array",
+					report.Related(write, "the buffer is written to here"))
+				break
+			}
+		}
+	}
+}
+
+// reaches reports whether control can flow from instruction a to
+// instruction b.
+func reaches(r *ir.Reachability, a, b ir.Instruction) bool {
+	ab, bb := a.Block(), b.Block()
+	if ab != bb {
+		return r.Reaches(ab, bb)
+	}
+	if a.ID() < b.ID() {
+		return true
+	}
+	// b comes before a in the same block, so we can only get from a
+	// to b by leaving the block and coming back.
+	for _, succ := range ab.Succs {
+		if r.Reaches(succ, ab) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package pkg
+
+import "bytes"
+
+func use(bytes.Buffer)     {}
+func usePtr(*bytes.Buffer) {}
+func get() bytes.Buffer    { return bytes.Buffer{} }
+
+func fn1() {
+	var buf bytes.Buffer
+	buf.WriteString("hello")
+	use(buf) //@ diag(`bytes.Buffer is copied after it has been written to`)
+}
+
+func fn2() bytes.Buffer {
+	var buf bytes.Buffer
+	buf.WriteByte('x')
+	b2 := buf //@ diag(`bytes.Buffer is copied after it has been written to`)
+	b2.WriteByte('y')
+	return buf //@ diag(`bytes.Buffer is copied after it has been written to`)
+}
+
+func fn3(cond bool) {
+	var buf bytes.Buffer
+	if cond {
+		buf.Write(nil)
+	}
+	use(buf) //@ diag(`bytes.Buffer is copied after it has been written to`)
+}
+
+func fn4() {
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		use(buf) //@ diag(`bytes.Buffer is copied after it has been written to`)
+		buf.WriteString("x")
+	}
+}
+
+func fn5() {
+	buf := new(bytes.Buffer)
+	buf.WriteString("hello")
+	usePtr(buf)
+	use(*buf) //@ diag(`bytes.Buffer is copied after it has been written to`)
+}
+
+// Copying before writing is fine.
+func fn6() {
+	var buf bytes.Buffer
+	use(buf)
+	buf.WriteString("hello")
+}
+
+// Passing pointers is fine.
+func fn7() {
+	var buf bytes.Buffer
+	buf.WriteString("hello")
+	usePtr(&buf)
+	_ = buf.String()
+	_ = buf.Len()
+}
+
+// Reading doesn't make the buffer refer to a backing array.
+func fn8() {
+	buf := get()
+	_ = buf.String()
+	use(buf)
+}
@@ -0,0 +1,81 @@
+package sa1039
+
+import (
+	"go/ast"
+	"go/version"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1039",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Using \'time.After\' in a \'select\' statement inside a loop`,
+		Text: `Every call to \'time.After\' creates a new timer. Before Go 1.23, timers
+that hadn't fired yet couldn't be garbage collected. Using
+\'time.After\' in a \'select\' statement inside a loop creates a new
+timer on every iteration, and if another case is chosen, the timer
+lingers until it fires. With long durations and frequent iterations,
+this leaks a lot of memory.
+
+Create a single timer with \'time.NewTimer\' outside the loop and
+\'Reset\' it on every iteration instead.
+
+Go 1.23 fixes this by allowing timers to be collected even if they
+haven't fired.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var query = pattern.MustParse(`
+	(CommClause
+		(Or
+			(UnaryExpr "<-" call@(CallExpr (Symbol "time.After") _))
+			(AssignStmt _ _ [(UnaryExpr "<-" call@(CallExpr (Symbol "time.After") _))]))
+		_)`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node, stack []ast.Node) {
+		m, ok := code.Match(pass, query, node)
+		if !ok {
+			return
+		}
+		// stack ends in the select statement, its body and the clause.
+		if len(stack) < 3 || len(stack[len(stack)-2].(*ast.BlockStmt).List) < 2 {
+			// A select statement with a single case always waits for
+			// the timer to fire.
+			return
+		}
+		if version.Compare(code.StdlibVersion(pass, node), "go1.23") >= 0 {
+			// Beginning with Go 1.23, the GC is able to collect
+			// timers that haven't fired yet.
+			return
+		}
+	loop:
+		for i := len(stack) - 4; i >= 0; i-- {
+			switch stack[i].(type) {
+			case *ast.ForStmt, *ast.RangeStmt:
+				report.Report(pass, m.State["call"].(ast.Node), "time.After in a loop leaks timers; use time.NewTimer and Reset")
+				break loop
+			case *ast.FuncLit, *ast.FuncDecl:
+				break loop
+			}
+		}
+	}
+	code.PreorderStack(pass, fn, (*ast.CommClause)(nil))
+	return nil, nil
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+func fn1(ch chan int) {
+	for {
+		select {
+		case <-ch:
+		case <-time.After(time.Minute): //@ diag(`time.After in a loop leaks timers`)
+			return
+		}
+	}
+}
+
+func fn2(ctx context.Context, ch chan int) {
+	for range ch {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-time.After(time.Second): //@ diag(`time.After in a loop leaks timers`)
+			println(t.String())
+		}
+	}
+}
+
+func fn3(ch chan int) {
+	for {
+		if true {
+			select {
+			case ch <- 1:
+			case _, ok := <-time.After(time.Second): //@ diag(`time.After in a loop leaks timers`)
+				_ = ok
+			}
+		}
+	}
+}
+
+func fn4(ch chan int) {
+	// A single timer that is reset on every iteration doesn't leak.
+	t := time.NewTimer(time.Minute)
+	defer t.Stop()
+	for {
+		if !t.Stop() {
+			select {
+			case <-t.C:
+			default:
+			}
+		}
+		t.Reset(time.Minute)
+		select {
+		case <-ch:
+		case <-t.C:
+			return
+		}
+	}
+}
+
+func fn5(ch chan int) {
+	// Not in a loop.
+	select {
+	case <-ch:
+	case <-time.After(time.Minute):
+	}
+}
+
+func fn6() {
+	// The only case always waits for the timer to fire.
+	for {
+		select {
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func fn7(ch chan int) {
+	for {
+		go func() {
+			// The loop is outside of the function literal.
+			select {
+			case <-ch:
+			case <-time.After(time.Minute):
+			}
+		}()
+	}
+}
@@ -0,0 +1,14 @@
+package pkg
+
+import "time"
+
+func fn1(ch chan int) {
+	for {
+		select {
+		case <-ch:
+		// Not flagged because this is no longer a problem in Go 1.23.
+		case <-time.After(time.Minute):
+			return
+		}
+	}
+}
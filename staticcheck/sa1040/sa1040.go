@@ -0,0 +1,153 @@
+package sa1040
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"strconv"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1040",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Comparing an error with a sentinel error using \'==\' instead of \'errors.Is\'`,
+		Text: `Errors are often wrapped to add context, for example with
+\'fmt.Errorf\' and the \'%w\' verb. Comparing an error with a sentinel
+error such as \'sql.ErrNoRows\' using \'==\' or \'!=\' only matches the
+sentinel itself, not errors wrapping it. \'errors.Is\' matches both.
+
+Some sentinel errors are documented to never be wrapped, most notably
+\'io.EOF\', which readers must return as is. Comparing with them using
+\'==\' is correct, which is why this check is disabled by default.
+
+Comparisons in \'Is\' methods, which implement \'errors.Is\' for custom
+error types, aren't flagged.`,
+		Before:     `if err == sql.ErrNoRows { ... }`,
+		After:      `if errors.Is(err, sql.ErrNoRows) { ... }`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityWarning,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var query = pattern.MustParse(`(BinaryExpr x (Or "==" "!=") y)`)
+
+var (
+	errorType  = types.Universe.Lookup("error").Type()
+	errorIface = errorType.Underlying().(*types.Interface)
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node, stack []ast.Node) {
+		m, ok := code.Match(pass, query, node)
+		if !ok {
+			return
+		}
+		expr := node.(*ast.BinaryExpr)
+		err, target := m.State["x"].(ast.Expr), m.State["y"].(ast.Expr)
+		if isSentinel(m.TypesInfo, err) {
+			err, target = target, err
+		}
+		if !isSentinel(m.TypesInfo, target) || isSentinel(m.TypesInfo, err) {
+			return
+		}
+		if T := m.TypesInfo.TypeOf(err); !types.IsInterface(T) || !types.Implements(T, errorIface) {
+			// Only error values can hold wrapped errors.
+			return
+		}
+		if version.Compare(code.StdlibVersion(pass, node), "go1.13") < 0 {
+			// errors.Is was added in Go 1.13.
+			return
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if decl, ok := stack[i].(*ast.FuncDecl); ok {
+				if decl.Recv != nil && decl.Name.Name == "Is" {
+					// Is methods implement errors.Is and have to
+					// compare errors directly.
+					return
+				}
+				break
+			}
+		}
+
+		var opts []report.Option
+		if name, ok := errorsName(pass, expr); ok {
+			var repl ast.Expr = &ast.CallExpr{
+				Fun:  edit.Selector(name, "Is"),
+				Args: []ast.Expr{err, target},
+			}
+			if expr.Op == token.NEQ {
+				repl = &ast.UnaryExpr{Op: token.NOT, X: repl}
+			}
+			opts = append(opts, report.Fixes(edit.Fix("use errors.Is", edit.ReplaceWithNode(pass.Fset, expr, repl))))
+		}
+		report.Report(pass, expr,
+			fmt.Sprintf("comparison with %s using %s doesn't match wrapped errors, use errors.Is instead", report.Render(pass, target), expr.Op),
+			opts...)
+	}
+	code.PreorderStack(pass, fn, (*ast.BinaryExpr)(nil))
+	return nil, nil
+}
+
+// isSentinel reports whether expr refers to a package-level variable
+// of type error.
+func isSentinel(info *types.Info, expr ast.Expr) bool {
+	var obj types.Object
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj = info.ObjectOf(expr)
+	case *ast.SelectorExpr:
+		obj = info.ObjectOf(expr.Sel)
+	default:
+		return false
+	}
+	v, ok := obj.(*types.Var)
+	if !ok || v.IsField() || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	return types.Identical(v.Type(), errorType)
+}
+
+// errorsName returns the name that the file containing node imports
+// the errors package as, if it imports it and the name refers to it at
+// node.
+func errorsName(pass *analysis.Pass, node ast.Node) (string, bool) {
+	for _, spec := range code.File(pass, node).Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err != nil || path != "errors" {
+			continue
+		}
+		name := "errors"
+		var obj types.Object = pass.TypesInfo.Implicits[spec]
+		if spec.Name != nil {
+			if spec.Name.Name == "." || spec.Name.Name == "_" {
+				continue
+			}
+			name = spec.Name.Name
+			obj = pass.TypesInfo.Defs[spec.Name]
+		}
+		if _, found := pass.Pkg.Scope().Innermost(node.Pos()).LookupParent(name, node.Pos()); found != obj {
+			return "", false
+		}
+		return name, true
+	}
+	return "", false
+}
@@ -0,0 +1,9 @@
+package pkg
+
+import "io"
+
+func fn(err error) {
+	// errors.Is doesn't exist before Go 1.13.
+	if err == io.EOF {
+	}
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var ErrFoo = errors.New("foo")
+
+var errBar error = errors.New("bar")
+
+var notSentinel = &os.PathError{}
+
+type MyError struct{}
+
+func (*MyError) Error() string { return "" }
+
+func (*MyError) Is(target error) bool {
+	// Is methods have to compare errors directly.
+	return target == ErrFoo
+}
+
+func fn1(err error) {
+	if err == io.EOF { //@ diag(`comparison with io.EOF using == doesn't match wrapped errors`)
+	}
+	if err != ErrFoo { //@ diag(`comparison with ErrFoo using != doesn't match wrapped errors`)
+	}
+	if errBar == err { //@ diag(`comparison with errBar using == doesn't match wrapped errors`)
+	}
+	if (err) == (os.ErrNotExist) { //@ diag(`comparison with os.ErrNotExist`)
+	}
+	_ = err != nil && err != io.ErrUnexpectedEOF //@ diag(`comparison with io.ErrUnexpectedEOF`)
+}
+
+func fn2(err error, perr *os.PathError) {
+	// Comparisons with nil
+	if err == nil {
+	}
+	if err != nil {
+	}
+	// Comparisons with errors that aren't package-level variables of
+	// type error, or of values of concrete types
+	if perr == notSentinel {
+	}
+	local := errors.New("")
+	if err == local {
+	}
+	// Comparisons of two sentinels
+	if ErrFoo == errBar {
+	}
+}
+
+func fn3() {
+	errors := []error{}
+	var err error
+	// errors is shadowed, so there is no fix.
+	if err == io.EOF { //@ diag(`comparison with io.EOF`)
+	}
+	_ = errors
+}
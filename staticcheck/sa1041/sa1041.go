@@ -0,0 +1,105 @@
+package sa1041
+
+import (
+	"fmt"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA1041",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Using the data returned by \'io.ReadAll\' or \'os.ReadFile\' while ignoring the error`,
+		Text: `When \'io.ReadAll\' or \'os.ReadFile\' fail, they still return the
+data that was read up to the point of the failure. Ignoring the error
+but using the data means silently processing data that may be
+truncated, for example because a network connection broke halfway
+through reading a response body.
+
+Calls whose results are both ignored aren't flagged, as they don't
+process any data.`,
+		Before: `
+b, _ := io.ReadAll(resp.Body)
+process(b)`,
+		After: `
+b, err := io.ReadAll(resp.Body)
+if err != nil {
+    return err
+}
+process(b)`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var readFuncs = []string{
+	"io.ReadAll",
+	"io/ioutil.ReadAll",
+	"os.ReadFile",
+	"io/ioutil.ReadFile",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				call, ok := instr.(*ir.Call)
+				if !ok || !irutil.IsCallToAny(call.Common(), readFuncs...) {
+					continue
+				}
+				data, err, ok := results(call)
+				if !ok {
+					continue
+				}
+				if data == nil || irutil.IsDiscarded(data) {
+					// The data isn't used, either. Whether the call is
+					// pointless or only done for its side effects,
+					// nothing is processed.
+					continue
+				}
+				if err != nil && !irutil.IsDiscarded(err) {
+					continue
+				}
+				name := irutil.CallName(call.Common())
+				report.Report(pass, call, fmt.Sprintf("error from %s is ignored but the returned data is used", name))
+			}
+		}
+	}
+	return nil, nil
+}
+
+// results returns the extractions of the data and the error returned
+// by call. Either may be nil if the result isn't extracted at all. It
+// returns false if the tuple is used in ways other than extracting
+// its elements.
+func results(call *ir.Call) (data, err *ir.Extract, ok bool) {
+	for _, ref := range *call.Referrers() {
+		switch ref := ref.(type) {
+		case *ir.DebugRef:
+		case *ir.Extract:
+			switch ref.Index {
+			case 0:
+				data = ref
+			case 1:
+				err = ref
+			}
+		default:
+			// We don't know what is happening to the tuple.
+			return nil, nil, false
+		}
+	}
+	return data, err, true
+}
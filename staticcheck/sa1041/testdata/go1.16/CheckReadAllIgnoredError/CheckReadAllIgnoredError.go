@@ -0,0 +1,65 @@
+package pkg
+
+import "io"
+
+func process([]byte) {}
+
+func fn1(r io.Reader) {
+	b, _ := io.ReadAll(r) //@ diag(`error from io.ReadAll is ignored but the returned data is used`)
+	process(b)
+}
+
+func fn2(r io.Reader) string {
+	b, _ := io.ReadAll(r) //@ diag(`error from io.ReadAll is ignored but the returned data is used`)
+	return string(b)
+}
+
+func fn3(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	process(b)
+	return err
+}
+
+func fn4(r io.Reader) {
+	// Neither result is used.
+	io.ReadAll(r)
+	_, _ = io.ReadAll(r)
+}
+
+func fn5(r io.Reader) {
+	// Only the error is used.
+	_, err := io.ReadAll(r)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func fn6(r io.Reader) {
+	b, err := io.ReadAll(r) //@ diag(`error from io.ReadAll is ignored`)
+	_ = err
+	process(b)
+}
+
+func fn7(r1, r2 io.Reader, cond bool) []byte {
+	var b []byte
+	var err error
+	if cond {
+		b, err = io.ReadAll(r1)
+	} else {
+		b, err = io.ReadAll(r2)
+	}
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func fn8(r1, r2 io.Reader, cond bool) []byte {
+	var b []byte
+	if cond {
+		b, _ = io.ReadAll(r1) //@ diag(`error from io.ReadAll is ignored`)
+	} else {
+		b = []byte("default")
+	}
+	return b
+}
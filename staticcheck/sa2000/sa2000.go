@@ -2,25 +2,40 @@ package sa2000
 
 import (
 	"fmt"
-	"go/ast"
 
-	"honnef.co/go/tools/analysis/code"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
-	"honnef.co/go/tools/pattern"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
 
 	"golang.org/x/tools/go/analysis"
-	"golang.org/x/tools/go/analysis/passes/inspect"
 )
 
 var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 	Analyzer: &analysis.Analyzer{
 		Name:     "SA2000",
 		Run:      run,
-		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
 	},
 	Doc: &lint.RawDocumentation{
-		Title:    `\'sync.WaitGroup.Add\' called inside the goroutine, leading to a race condition`,
+		Title: `\'sync.WaitGroup.Add\' called inside the goroutine, leading to a race condition`,
+		Text: `\'sync.WaitGroup.Add\' has to be called before starting the goroutine
+that calls \'Done\'. Otherwise, \'Wait\' may run before the goroutine had
+a chance to call \'Add\', and return early:
+
+    go func() {
+        wg.Add(1) // races with wg.Wait
+        defer wg.Done()
+        ...
+    }()
+    wg.Wait()
+
+This check flags calls of \'Add\' in function literals started with a
+go statement, on wait groups of the function starting the goroutine.
+Goroutines that are started right after a call of \'Add\' on the same
+wait group, as well as goroutines that call \'Wait\' themselves, are
+assumed to be correct.`,
 		Since:    "2017.1",
 		Severity: lint.SeverityWarning,
 		MergeIf:  lint.MergeIfAny,
@@ -29,20 +44,150 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 
 var Analyzer = SCAnalyzer.Analyzer
 
-var checkWaitgroupAddQ = pattern.MustParse(`
-	(GoStmt
-		(CallExpr
-			(FuncLit
-				_
-				call@(CallExpr (Symbol "(*sync.WaitGroup).Add") _):_) _))`)
+// A waitGroup identifies a wait group in the function starting a
+// goroutine. It is either the value of a pointer to the wait group,
+// or, if deref is true, the address that such a pointer is loaded
+// from.
+type waitGroup struct {
+	v     ir.Value
+	deref bool
+}
+
+func key(v ir.Value) waitGroup {
+	if f := irutil.Flatten(v); f != nil {
+		v = f
+	}
+	if load, ok := v.(*ir.Load); ok {
+		return waitGroup{load.X, true}
+	}
+	return waitGroup{v, false}
+}
 
 func run(pass *analysis.Pass) (interface{}, error) {
-	fn := func(node ast.Node) {
-		if m, ok := code.Match(pass, checkWaitgroupAddQ, node); ok {
-			call := m.State["call"].(ast.Node)
-			report.Report(pass, call, fmt.Sprintf("should call %s before starting the goroutine to avoid a race", report.Render(pass, call)))
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		// Calls of Add in fn, by wait group.
+		adds := map[waitGroup][]ir.Instruction{}
+		var gos []*ir.Go
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ir.Call:
+					common := instr.Common()
+					if irutil.IsCallTo(common, "(*sync.WaitGroup).Add") {
+						k := key(common.Args[0])
+						adds[k] = append(adds[k], instr)
+					}
+				case *ir.Go:
+					gos = append(gos, instr)
+				}
+			}
+		}
+
+		for _, g := range gos {
+			checkGo(pass, g, adds)
 		}
 	}
-	code.Preorder(pass, fn, (*ast.GoStmt)(nil))
 	return nil, nil
 }
+
+func checkGo(pass *analysis.Pass, g *ir.Go, adds map[waitGroup][]ir.Instruction) {
+	var callee *ir.Function
+	var bindings []ir.Value
+	switch v := g.Call.Value.(type) {
+	case *ir.MakeClosure:
+		callee = v.Fn.(*ir.Function)
+		bindings = v.Bindings
+	case *ir.Function:
+		callee = v
+	}
+	if callee == nil || callee.Parent() != g.Parent() {
+		// Not a function literal
+		return
+	}
+
+	// translate maps a wait group in the goroutine to the wait group
+	// in the function starting it.
+	translate := func(v ir.Value) (waitGroup, bool) {
+		v = irutil.Flatten(v)
+		deref := false
+		if load, ok := v.(*ir.Load); ok {
+			v = load.X
+			deref = true
+		}
+		switch v := v.(type) {
+		case *ir.FreeVar:
+			for i, fv := range callee.FreeVars {
+				if fv == v {
+					return waitGroup{bindings[i], deref}, true
+				}
+			}
+		case *ir.Parameter:
+			if deref {
+				return waitGroup{}, false
+			}
+			for i, param := range callee.Params {
+				if param == v {
+					return key(g.Call.Args[i]), true
+				}
+			}
+		}
+		return waitGroup{}, false
+	}
+
+	var calls []*ir.Call
+	waits := map[waitGroup]bool{}
+	for _, b := range callee.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(*ir.Call)
+			if !ok {
+				continue
+			}
+			common := call.Common()
+			switch irutil.CallName(common) {
+			case "(*sync.WaitGroup).Add":
+				calls = append(calls, call)
+			case "(*sync.WaitGroup).Wait":
+				if k, ok := translate(common.Args[0]); ok {
+					waits[k] = true
+				}
+			}
+		}
+	}
+
+	reported := map[waitGroup]bool{}
+	for _, call := range calls {
+		k, ok := translate(call.Common().Args[0])
+		if !ok || waits[k] || reported[k] {
+			continue
+		}
+		if isCovered(g, adds[k]) {
+			continue
+		}
+		// Only report the first Add per wait group.
+		reported[k] = true
+		report.Report(pass, call, fmt.Sprintf("should call %s before starting the goroutine to avoid a race", report.Render(pass, call.Source())))
+	}
+}
+
+// isCovered reports whether the goroutine started by g is covered by
+// one of the calls of Add in adds, that is, whether one of them
+// precedes g in the same block, without other goroutines being
+// started in between.
+func isCovered(g *ir.Go, adds []ir.Instruction) bool {
+	for _, add := range adds {
+		if add.Block() != g.Block() || add.ID() > g.ID() {
+			continue
+		}
+		covered := true
+		for _, instr := range g.Block().Instrs {
+			if other, ok := instr.(*ir.Go); ok && other != g && other.ID() > add.ID() && other.ID() < g.ID() {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
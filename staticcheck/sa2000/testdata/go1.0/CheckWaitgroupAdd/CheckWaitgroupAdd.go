@@ -32,3 +32,62 @@ func fn() {
 func fn2(wg sync.WaitGroup) {
 	wg.Add(1)
 }
+
+func fn3(wg *sync.WaitGroup, ch chan int) {
+	go func() {
+		for range ch {
+			wg.Add(1) //@ diag(`should call wg.Add(1) before starting`)
+			wg.Add(1)
+			wg.Done()
+		}
+	}()
+
+	go func(wg *sync.WaitGroup) {
+		if true {
+			wg.Add(2) //@ diag(`should call wg.Add(2) before starting`)
+		}
+	}(wg)
+	wg.Wait()
+}
+
+func fn4(ch chan int) {
+	// The goroutine is covered by the first Add, and may add more.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range ch {
+			wg.Add(1)
+			go wg.Done()
+		}
+	}()
+	wg.Wait()
+}
+
+func fn5(ch chan int, done chan struct{}) {
+	// The goroutine waits for the wait group itself.
+	go func() {
+		var wg sync.WaitGroup
+		for range ch {
+			wg.Add(1)
+			go func() {
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	var wg sync.WaitGroup
+	go func() {
+		wg.Add(1)
+		wg.Wait()
+	}()
+}
+
+func fn6(wgs []sync.WaitGroup) {
+	// We don't know which wait group the goroutine uses.
+	go func() {
+		wgs[0].Add(1)
+	}()
+}
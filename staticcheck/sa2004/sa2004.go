@@ -0,0 +1,145 @@
+package sa2004
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA2004",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Receive from channel can't tell a sent zero value apart from a closed channel`,
+		Text: `
+Receiving from a closed channel yields the zero value of the channel's
+element type. When the zero value is also a value that gets sent on the
+channel, code such as
+
+    if <-done {
+        ...
+    }
+
+cannot tell whether it received an actual value or whether the channel
+has been closed. Use the two-value form of the receive operation
+instead:
+
+    if v, ok := <-done; ok && v {
+        ...
+    }
+
+This check only flags receives whose result is used directly as a
+condition or compared against the zero value, and only if the package
+sends the zero value on channels of the same type.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityWarning,
+		MergeIf:    lint.MergeIfAll,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	// Element types of channels that the zero value gets sent on.
+	var zeroSent []types.Type
+	var recvs []*ir.Recv
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ir.Send:
+					if k, ok := instr.X.(*ir.Const); ok && isZero(k) {
+						if T, ok := elem(instr.Chan); ok {
+							zeroSent = append(zeroSent, T)
+						}
+					}
+				case *ir.Recv:
+					if !instr.CommaOk {
+						recvs = append(recvs, instr)
+					}
+				}
+			}
+		}
+	}
+
+	sendsZero := func(T types.Type) bool {
+		for _, zT := range zeroSent {
+			if types.Identical(T, zT) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, recv := range recvs {
+		if T, ok := elem(recv.Chan); !ok || !sendsZero(T) {
+			continue
+		}
+		if !usedAsCondition(recv) {
+			continue
+		}
+		report.Report(pass, recv, "received value cannot be told apart from the zero value of a closed channel, use the two-value form of the receive")
+	}
+	return nil, nil
+}
+
+// elem returns the element type of the channel ch. It returns false if
+// ch's type is a type parameter without a core type.
+func elem(ch ir.Value) (types.Type, bool) {
+	T, ok := typeutil.CoreType(ch.Type()).(*types.Chan)
+	if !ok {
+		return nil, false
+	}
+	return T.Elem(), true
+}
+
+func isZero(k *ir.Const) bool {
+	if k.Value == nil {
+		return true
+	}
+	switch k.Value.Kind() {
+	case constant.Bool:
+		return !constant.BoolVal(k.Value)
+	case constant.String:
+		return constant.StringVal(k.Value) == ""
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(k.Value) == 0
+	default:
+		return false
+	}
+}
+
+// usedAsCondition reports whether the result of recv is used directly
+// as a branch condition, or compared against the zero value.
+func usedAsCondition(recv *ir.Recv) bool {
+	for _, ref := range *recv.Referrers() {
+		switch ref := ref.(type) {
+		case *ir.If:
+			return true
+		case *ir.BinOp:
+			if ref.Op != token.EQL && ref.Op != token.NEQ {
+				continue
+			}
+			other := ref.X
+			if other == recv {
+				other = ref.Y
+			}
+			if k, ok := other.(*ir.Const); ok && isZero(k) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package pkg
+
+func fn1(done chan bool) {
+	go func() { done <- false }()
+
+	if <-done { //@ diag(`cannot be told apart`)
+		println()
+	}
+	for <-done { //@ diag(`cannot be told apart`)
+	}
+	if v, ok := <-done; ok && v {
+		println()
+	}
+	_ = <-done
+}
+
+func fn2(ch chan int) {
+	ch <- 0
+
+	if <-ch == 0 { //@ diag(`cannot be told apart`)
+		println()
+	}
+	if <-ch == 1 {
+		println()
+	}
+	x := <-ch
+	println(x)
+}
+
+func fn3(ch chan string) {
+	ch <- "foo"
+
+	// The zero value is never sent on channels of this type.
+	if <-ch == "" {
+		println()
+	}
+}
@@ -0,0 +1,15 @@
+package pkg
+
+func fn1[C ~chan bool](done C) {
+	done <- false
+
+	if <-done { //@ diag(`cannot be told apart`)
+		println()
+	}
+}
+
+func fn2[C interface{ chan int | <-chan int }](ch C) {
+	if <-ch == 0 {
+		println()
+	}
+}
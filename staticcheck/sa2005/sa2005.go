@@ -0,0 +1,88 @@
+package sa2005
+
+import (
+	"fmt"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA2005",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `The cancel function returned by \'context.WithCancel\' and similar functions is never used`,
+		Text: `The functions \'context.WithCancel\', \'context.WithTimeout\',
+\'context.WithDeadline\' and their variants that take a cause return a
+function that cancels the derived context. Until it is called, the
+resources associated with the context, such as timers and the
+goroutines watching the parent context, may not be released. The
+cancel function should be called as soon as the work using the
+context is done, typically with \'defer cancel()\'.
+
+This check only flags cancel functions that are discarded outright,
+either by assigning them to the blank identifier or by not using them
+at all. Cancel functions that are called, deferred, stored, returned
+or passed to other functions are assumed to be taken care of.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var contextFuncs = []string{
+	"context.WithCancel",
+	"context.WithCancelCause",
+	"context.WithTimeout",
+	"context.WithTimeoutCause",
+	"context.WithDeadline",
+	"context.WithDeadlineCause",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				call, ok := instr.(*ir.Call)
+				if !ok || !irutil.IsCallToAny(call.Common(), contextFuncs...) {
+					continue
+				}
+				if isUsed(call) {
+					continue
+				}
+				report.Report(pass, call,
+					fmt.Sprintf("the cancel function returned by %s is never used, which may cause a context leak", irutil.CallName(call.Common())))
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isUsed reports whether the cancel function returned by call is used
+// in any way other than being discarded.
+func isUsed(call *ir.Call) bool {
+	for _, ref := range *call.Referrers() {
+		if _, ok := ref.(*ir.DebugRef); ok {
+			continue
+		}
+		ex, ok := ref.(*ir.Extract)
+		if !ok {
+			// We don't know what is happening to the tuple.
+			return true
+		}
+		if ex.Index == 1 && !irutil.IsDiscarded(ex) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+func fn1(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx) //@ diag(`the cancel function returned by context.WithCancel is never used`)
+	_ = cancel
+	_ = ctx
+}
+
+func fn2(ctx context.Context) {
+	ctx, _ = context.WithTimeout(ctx, time.Second) //@ diag(`the cancel function returned by context.WithTimeout is never used`)
+	_ = ctx
+}
+
+func fn3(ctx context.Context) {
+	context.WithDeadline(ctx, time.Now()) //@ diag(`the cancel function returned by context.WithDeadline is never used`)
+}
+
+func fn4(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	_ = ctx
+}
+
+func fn5(ctx context.Context) context.CancelFunc {
+	_, cancel := context.WithCancel(ctx)
+	return cancel
+}
+
+func fn6(ctx context.Context, f func(context.CancelFunc)) {
+	_, cancel := context.WithCancel(ctx)
+	f(cancel)
+}
+
+var global context.CancelFunc
+
+func fn7(ctx context.Context) {
+	_, global = context.WithCancel(ctx)
+}
+
+func fn8(ctx context.Context) {
+	_, cancel := context.WithCancel(ctx)
+	go func() {
+		cancel()
+	}()
+}
+
+func fn9(ctx context.Context, b bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	if b {
+		cancel()
+	}
+	_ = ctx
+}
+
+func fn10(ctx context.Context, b bool) {
+	ctx, cancel := context.WithCancel(ctx) //@ diag(`never used`)
+	if b {
+		_ = cancel
+	}
+	_ = ctx
+}
+
+type T struct {
+	cancel context.CancelFunc
+}
+
+func (t *T) fn11(ctx context.Context) {
+	_, t.cancel = context.WithCancel(ctx)
+}
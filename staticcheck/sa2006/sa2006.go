@@ -0,0 +1,156 @@
+package sa2006
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA2006",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Locking a copy of a mutex made by a range loop`,
+		Text: `The value variable of a range loop holds a copy of each element.
+When the elements are, or contain, a \'sync.Mutex\' or \'sync.RWMutex\',
+locking the mutex via the value variable locks the copy, not the
+mutex stored in the slice, array, map or channel, which makes the
+locking useless.
+
+Iterate by index and lock the elements in place, or store pointers
+in the collection instead.`,
+		Before: `
+for _, m := range mutexes {
+    m.Lock()
+}`,
+		After: `
+for i := range mutexes {
+    mutexes[i].Lock()
+}`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		loop := node.(*ast.RangeStmt)
+		elem := loop.Value
+		if _, ok := typeutil.CoreType(pass.TypesInfo.TypeOf(loop.X)).(*types.Chan); ok {
+			// When ranging over channels, the key holds the elements.
+			elem = loop.Key
+		}
+		ident, ok := elem.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			return
+		}
+		obj := pass.TypesInfo.ObjectOf(ident)
+		if obj == nil || !containsMutex(obj.Type(), nil) {
+			return
+		}
+
+		found := false
+		ast.Inspect(loop.Body, func(node ast.Node) bool {
+			if found {
+				return false
+			}
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if isMutexMethod(pass, sel) && refersToCopy(pass, sel.X, obj) {
+				found = true
+				return false
+			}
+			return true
+		})
+		if found {
+			report.Report(pass, elem, "range copies the mutex; iterate by index or use pointers")
+		}
+	}
+	code.Preorder(pass, fn, (*ast.RangeStmt)(nil))
+	return nil, nil
+}
+
+// containsMutex reports whether T is, or contains without any
+// indirection, a sync.Mutex or sync.RWMutex.
+func containsMutex(T types.Type, seen map[types.Type]struct{}) bool {
+	if typeutil.IsTypeWithName(T, "sync.Mutex") || typeutil.IsTypeWithName(T, "sync.RWMutex") {
+		return true
+	}
+	if _, ok := seen[T]; ok {
+		return false
+	}
+	if seen == nil {
+		seen = map[types.Type]struct{}{}
+	}
+	seen[T] = struct{}{}
+	switch T := T.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			if containsMutex(T.Field(i).Type(), seen) {
+				return true
+			}
+		}
+	case *types.Array:
+		return containsMutex(T.Elem(), seen)
+	}
+	return false
+}
+
+// isMutexMethod reports whether sel selects a method of sync.Mutex or
+// sync.RWMutex, directly or via embedding, without indirecting
+// through a pointer.
+func isMutexMethod(pass *analysis.Pass, sel *ast.SelectorExpr) bool {
+	selection, ok := pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal || selection.Indirect() {
+		return false
+	}
+	recv := selection.Obj().Type().(*types.Signature).Recv()
+	return typeutil.IsPointerToTypeWithName(recv.Type(), "sync.Mutex") ||
+		typeutil.IsPointerToTypeWithName(recv.Type(), "sync.RWMutex")
+}
+
+// refersToCopy reports whether expr is obj, or a part of obj that is
+// stored in obj itself, such as one of its fields.
+func refersToCopy(pass *analysis.Pass, expr ast.Expr, obj types.Object) bool {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			selection, ok := pass.TypesInfo.Selections[e]
+			if !ok || selection.Kind() != types.FieldVal || selection.Indirect() {
+				return false
+			}
+			expr = e.X
+		case *ast.IndexExpr:
+			if _, ok := pass.TypesInfo.TypeOf(e.X).Underlying().(*types.Array); !ok {
+				return false
+			}
+			expr = e.X
+		case *ast.Ident:
+			return pass.TypesInfo.ObjectOf(e) == obj
+		default:
+			return false
+		}
+	}
+}
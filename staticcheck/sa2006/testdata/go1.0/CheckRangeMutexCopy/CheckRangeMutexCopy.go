@@ -0,0 +1,73 @@
+package pkg
+
+import "sync"
+
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+type embedded struct {
+	sync.RWMutex
+	n int
+}
+
+type indirect struct {
+	mu *sync.Mutex
+}
+
+type nested struct {
+	locks [2]struct {
+		mu sync.Mutex
+	}
+}
+
+func fn1(mutexes []sync.Mutex) {
+	for _, m := range mutexes { //@ diag(`range copies the mutex`)
+		m.Lock()
+	}
+	for i := range mutexes {
+		mutexes[i].Lock()
+	}
+}
+
+func fn2(mutexes []*sync.Mutex) {
+	for _, m := range mutexes {
+		m.Lock()
+	}
+}
+
+func fn3(counters []counter, m map[string]embedded, ch chan counter) {
+	for _, c := range counters { //@ diag(`range copies the mutex`)
+		c.mu.Lock()
+		c.n++
+		c.mu.Unlock()
+	}
+	for _, e := range m { //@ diag(`range copies the mutex`)
+		e.RLock()
+	}
+	for c := range ch { //@ diag(`range copies the mutex`)
+		(c.mu).Lock()
+	}
+	for _, c := range counters {
+		// Reading the copy is fine.
+		_ = c.n
+	}
+}
+
+func fn4(xs []indirect, ys []nested) {
+	for _, x := range xs {
+		x.mu.Lock()
+	}
+	for _, y := range ys { //@ diag(`range copies the mutex`)
+		y.locks[0].mu.Lock()
+	}
+}
+
+func fn5(counters []counter) {
+	for _, c := range counters {
+		_ = c
+		c := &counters[0]
+		c.mu.Lock()
+	}
+}
@@ -26,6 +26,9 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 		Since:    "2017.1",
 		Severity: lint.SeverityWarning,
 		MergeIf:  lint.MergeIfAll,
+		// SA4034 explains why assignments to range loop variables
+		// are never used.
+		YieldsTo: []string{"SA4034"},
 	},
 })
 
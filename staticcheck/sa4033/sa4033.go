@@ -0,0 +1,117 @@
+package sa4033
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA4033",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Select statement with duplicate channel operations`,
+		Text: `When two cases of a select statement receive from the same
+channel, or send the same value on the same channel, the cases are
+interchangeable and only one of them is needed. This is usually the
+result of copying a case and forgetting to update it.
+
+Example:
+
+    select {
+    case v := <-requests:
+        handle(v)
+    case <-requests:
+        return
+    }`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+// commOp returns the channel and, for sends, the sent value of a
+// select case's communication.
+func commOp(comm ast.Stmt) (ch ast.Expr, val ast.Expr, ok bool) {
+	switch comm := comm.(type) {
+	case *ast.SendStmt:
+		return astutil.Unparen(comm.Chan), astutil.Unparen(comm.Value), true
+	case *ast.ExprStmt:
+		return recvChan(comm.X)
+	case *ast.AssignStmt:
+		if len(comm.Rhs) != 1 {
+			return nil, nil, false
+		}
+		return recvChan(comm.Rhs[0])
+	default:
+		return nil, nil, false
+	}
+}
+
+func recvChan(expr ast.Expr) (ast.Expr, ast.Expr, bool) {
+	unary, ok := astutil.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.ARROW {
+		return nil, nil, false
+	}
+	return astutil.Unparen(unary.X), nil, true
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		type op struct {
+			clause *ast.CommClause
+			ch     ast.Expr
+			val    ast.Expr
+		}
+		var ops []op
+		for _, stmt := range node.(*ast.SelectStmt).Body.List {
+			clause := stmt.(*ast.CommClause)
+			if clause.Comm == nil {
+				// default case
+				continue
+			}
+			ch, val, ok := commOp(clause.Comm)
+			if !ok {
+				continue
+			}
+			// Channel expressions with side effects, such as function
+			// calls, may evaluate to different channels.
+			if code.MayHaveSideEffects(pass, ch, nil) || (val != nil && code.MayHaveSideEffects(pass, val, nil)) {
+				continue
+			}
+
+			for _, prev := range ops {
+				if (prev.val == nil) != (val == nil) {
+					continue
+				}
+				if !astutil.Equal(prev.ch, ch) || (val != nil && !astutil.Equal(prev.val, val)) {
+					continue
+				}
+				var msg string
+				if val == nil {
+					msg = fmt.Sprintf("this case receives from %s, like the case on line %d", report.Render(pass, ch), pass.Fset.PositionFor(prev.clause.Pos(), false).Line)
+				} else {
+					msg = fmt.Sprintf("this case sends %s on %s, like the case on line %d", report.Render(pass, val), report.Render(pass, ch), pass.Fset.PositionFor(prev.clause.Pos(), false).Line)
+				}
+				report.Report(pass, clause.Comm, msg, report.Related(prev.clause.Comm, "first case"))
+				break
+			}
+			ops = append(ops, op{clause, ch, val})
+		}
+	}
+	code.Preorder(pass, fn, (*ast.SelectStmt)(nil))
+	return nil, nil
+}
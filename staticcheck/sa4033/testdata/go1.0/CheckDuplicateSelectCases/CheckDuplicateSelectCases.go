@@ -0,0 +1,45 @@
+package pkg
+
+type T struct{ ch chan int }
+
+func fn(ch1, ch2 chan int, t T, chs []chan int, get func() chan int) {
+	select {
+	case <-ch1:
+	case v := <-ch1: //@ diag(`this case receives from ch1, like the case on line 7`)
+		_ = v
+	}
+
+	select {
+	case v, ok := <-t.ch:
+		_, _ = v, ok
+	case <-ch2:
+	case <-(t.ch): //@ diag(`this case receives from t.ch`)
+	default:
+	}
+
+	select {
+	case ch1 <- 1:
+	case ch1 <- 1: //@ diag(`this case sends 1 on ch1`)
+	case ch1 <- 2:
+	case <-ch1:
+	}
+
+	select {
+	case <-chs[0]:
+	case <-chs[0]: //@ diag(`this case receives from chs[0]`)
+	}
+
+	// Distinct channels
+	select {
+	case <-ch1:
+	case <-ch2:
+	case ch1 <- 1:
+	case ch2 <- 1:
+	}
+
+	// Calls may return different channels
+	select {
+	case <-get():
+	case <-get():
+	}
+}
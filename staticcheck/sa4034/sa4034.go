@@ -0,0 +1,213 @@
+package sa4034
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA4034",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Assignment to a range loop variable has no effect on the iteration`,
+		Text: `The variables declared by a range loop are assigned anew at the
+start of each iteration. Modifying the key inside of the loop body
+doesn't skip or repeat elements, and modifying the value doesn't
+modify the element being iterated over, as the value is a copy.
+
+Example:
+
+    for i := range lines {
+        if lines[i] == "" {
+            i++ // doesn't skip the next line
+        }
+    }
+
+    for _, v := range counts {
+        v++ // doesn't increment the element of counts
+    }
+
+Because variables are often reassigned on purpose, such as in
+'v = strings.TrimSpace(v)', plain assignments are only flagged if the
+new value is never used. Incrementing, decrementing or otherwise
+updating the key is always flagged.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node) {
+		loop := node.(*ast.RangeStmt)
+		if loop.Tok != token.DEFINE {
+			// The variables outlive the loop and may be modified
+			// for the benefit of the code following it.
+			return
+		}
+		check := func(expr ast.Expr, isKey bool) {
+			ident, ok := expr.(*ast.Ident)
+			if !ok || ident.Name == "_" {
+				return
+			}
+			obj, ok := pass.TypesInfo.Defs[ident].(*types.Var)
+			if !ok {
+				return
+			}
+			checkVar(pass, loop, obj, isKey)
+		}
+		if loop.Key != nil {
+			check(loop.Key, true)
+		}
+		if loop.Value != nil {
+			check(loop.Value, false)
+		}
+	}
+	code.Preorder(pass, fn, (*ast.RangeStmt)(nil))
+	return nil, nil
+}
+
+func checkVar(pass *analysis.Pass, loop *ast.RangeStmt, obj *types.Var, isKey bool) {
+	// assigns holds the statements assigning to obj, reads the
+	// positions of identifiers reading obj, and loops the loops nested
+	// in the body.
+	var assigns []ast.Stmt
+	var reads []token.Pos
+	var loops []ast.Stmt
+	isObj := func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return ok && pass.TypesInfo.Uses[ident] == obj
+	}
+	escapes := false
+	ast.Inspect(loop.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			// Closures may run after the iteration has ended.
+			if refersTo(pass, node, obj) {
+				escapes = true
+			}
+			return false
+		case *ast.ForStmt, *ast.RangeStmt:
+			loops = append(loops, node.(ast.Stmt))
+		case *ast.UnaryExpr:
+			if node.Op == token.AND && isObj(node.X) {
+				escapes = true
+			}
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				return true
+			}
+			for _, lhs := range node.Lhs {
+				if isObj(lhs) {
+					assigns = append(assigns, node)
+					if node.Tok != token.ASSIGN {
+						// x op= y reads x
+						reads = append(reads, lhs.Pos())
+					}
+				}
+			}
+			for _, rhs := range node.Rhs {
+				ast.Inspect(rhs, func(node ast.Node) bool {
+					if expr, ok := node.(ast.Expr); ok && isObj(expr) {
+						reads = append(reads, expr.Pos())
+					}
+					return true
+				})
+			}
+			// Don't visit the left-hand side, which is a write, not a
+			// read. Index expressions on the left-hand side aren't
+			// assignments to obj and have been handled above.
+			for _, lhs := range node.Lhs {
+				if !isObj(lhs) {
+					ast.Inspect(lhs, func(node ast.Node) bool {
+						if expr, ok := node.(ast.Expr); ok && isObj(expr) {
+							reads = append(reads, expr.Pos())
+						}
+						return true
+					})
+				}
+			}
+			return false
+		case *ast.IncDecStmt:
+			if isObj(node.X) {
+				assigns = append(assigns, node)
+				reads = append(reads, node.X.Pos())
+				return false
+			}
+		case *ast.Ident:
+			if isObj(node) {
+				reads = append(reads, node.Pos())
+			}
+		}
+		return true
+	})
+	if escapes {
+		return
+	}
+
+	name := obj.Name()
+	keyMsg := fmt.Sprintf("assignment to %s has no effect on the iteration of the range loop, which assigns the next key to %s at the start of each iteration", name, name)
+	for _, assign := range assigns {
+		// Incrementing or otherwise stepping the key looks like an
+		// attempt at skipping elements, even if the key is used
+		// afterwards.
+		stepping := true
+		if assign, ok := assign.(*ast.AssignStmt); ok && assign.Tok == token.ASSIGN {
+			stepping = false
+		}
+		if isKey && stepping {
+			report.Report(pass, assign, keyMsg)
+			continue
+		}
+
+		// The new value is used if it is read after the assignment,
+		// or anywhere in a nested loop containing the assignment.
+		start := assign.End()
+		for _, l := range loops {
+			if l.Pos() <= assign.Pos() && assign.End() <= l.End() && l.Pos() < start {
+				start = l.Pos()
+			}
+		}
+		used := false
+		for _, pos := range reads {
+			if pos > start && pos != assign.Pos() {
+				used = true
+				break
+			}
+		}
+		if used {
+			continue
+		}
+		if isKey {
+			report.Report(pass, assign, keyMsg)
+		} else {
+			report.Report(pass, assign, fmt.Sprintf("%s is a copy of the element being iterated over; modifying it doesn't modify the element, and the new value is never used", name))
+		}
+	}
+}
+
+// refersTo reports whether node refers to obj.
+func refersTo(pass *analysis.Pass, node ast.Node, obj types.Object) bool {
+	found := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[ident] == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
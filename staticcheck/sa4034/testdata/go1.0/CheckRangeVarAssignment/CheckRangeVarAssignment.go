@@ -0,0 +1,89 @@
+package pkg
+
+func use(...interface{})   {}
+func trim(s string) string { return s }
+
+type T struct{ n int }
+
+func fn1(lines []string) {
+	for i := range lines {
+		if lines[i] == "" {
+			i++ //@ diag(`assignment to i has no effect on the iteration of the range loop`)
+		}
+		use(lines[i])
+	}
+
+	for i, line := range lines {
+		i += 2 //@ diag(`assignment to i has no effect`)
+		use(i, line)
+	}
+
+	for i := range lines {
+		use(i)
+		i = 0 //@ diag(`assignment to i has no effect`)
+	}
+
+	m := map[string]int{}
+	for k := range m {
+		// The key is reused as a local variable
+		k = trim(k)
+		use(k)
+	}
+}
+
+func fn2(counts []int, ts []T, lines []string) {
+	for _, v := range counts {
+		v++ //@ diag(`v is a copy of the element being iterated over`)
+	}
+
+	for _, v := range counts {
+		if v > 0 {
+			v = 0 //@ diag(`v is a copy of the element being iterated over`)
+		}
+	}
+
+	for _, t := range ts {
+		use(t)
+		t = T{} //@ diag(`t is a copy of the element being iterated over`)
+	}
+
+	// The value is reused as a local variable
+	for _, line := range lines {
+		line = trim(line)
+		use(line)
+	}
+
+	for _, v := range counts {
+		v *= 2
+		use(v)
+	}
+
+	for _, v := range counts {
+		for j := 0; j < 3; j++ {
+			use(v)
+			v--
+		}
+	}
+
+	for _, v := range counts {
+		p := &v
+		*p = 1
+		v = 2
+	}
+
+	for _, v := range counts {
+		fn := func() int { return v }
+		v = 2
+		use(fn)
+	}
+}
+
+func fn3(counts []int) {
+	// The variables outlive the loop
+	var i, v int
+	for i, v = range counts {
+		i++
+		v++
+	}
+	use(i, v)
+}
@@ -0,0 +1,122 @@
+package sa4035
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA4035",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Multiplying two \'time.Duration\' values`,
+		Text: `A \'time.Duration\' is a number of nanoseconds. Multiplying two
+durations yields a number of square nanoseconds, which, interpreted
+as a duration, is nonsensical. For example, if \'timeout\' is 5
+seconds, \'timeout * time.Millisecond\' is about 58 days.
+
+To scale a duration, multiply it by an untyped constant, as in \'5 *
+time.Second\', or by a number converted to a duration, as in
+\'time.Duration(n) * time.Second\'.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var query = pattern.MustParse(`(BinaryExpr x "*" y)`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node, stack []ast.Node) {
+		m, ok := code.Match(pass, query, node)
+		if !ok {
+			return
+		}
+		x := m.State["x"].(ast.Expr)
+		y := m.State["y"].(ast.Expr)
+		if !isDuration(pass, x) || !isDuration(pass, y) {
+			return
+		}
+		for i := len(stack) - 2; i >= 0; i-- {
+			if _, ok := stack[i].(*ast.ParenExpr); ok {
+				continue
+			}
+			if div, ok := stack[i].(*ast.BinaryExpr); ok && div.Op == token.QUO && astutil.Unparen(div.X) == node && isDuration(pass, div.Y) {
+				// Dividing by a duration turns the product back
+				// into a duration, as in d1 * d2 / time.Second.
+				return
+			}
+			break
+		}
+		report.Report(pass, node, "multiplication of two time.Duration values is almost always a bug")
+	}
+	code.PreorderStack(pass, fn, (*ast.BinaryExpr)(nil))
+	return nil, nil
+}
+
+// isDuration reports whether expr is a time.Duration that doesn't
+// merely act as a factor, that is, that isn't an untyped constant or
+// the conversion of a non-duration to a duration.
+func isDuration(pass *analysis.Pass, expr ast.Expr) bool {
+	if !typeutil.IsTypeWithName(pass.TypesInfo.TypeOf(expr), "time.Duration") {
+		return false
+	}
+	if isUntyped(pass, expr) {
+		return false
+	}
+	if call, ok := astutil.Unparen(expr).(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if tv, ok := pass.TypesInfo.Types[call.Fun]; ok && tv.IsType() {
+			return isDuration(pass, call.Args[0])
+		}
+	}
+	return true
+}
+
+// isUntyped reports whether expr is made up of only untyped
+// constants. The type checker records the types that untyped constants
+// have been converted to, so we have to look at the expression's
+// structure.
+func isUntyped(pass *analysis.Pass, expr ast.Expr) bool {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return isUntypedConst(pass.TypesInfo.ObjectOf(expr))
+	case *ast.SelectorExpr:
+		return isUntypedConst(pass.TypesInfo.ObjectOf(expr.Sel))
+	case *ast.UnaryExpr:
+		return isUntyped(pass, expr.X)
+	case *ast.BinaryExpr:
+		if expr.Op == token.SHL || expr.Op == token.SHR {
+			return isUntyped(pass, expr.X)
+		}
+		return isUntyped(pass, expr.X) && isUntyped(pass, expr.Y)
+	default:
+		return false
+	}
+}
+
+func isUntypedConst(obj types.Object) bool {
+	c, ok := obj.(*types.Const)
+	if !ok {
+		return false
+	}
+	basic, ok := c.Type().(*types.Basic)
+	return ok && basic.Info()&types.IsUntyped != 0
+}
@@ -0,0 +1,31 @@
+package pkg
+
+import "time"
+
+const n = 5
+
+type Seconds int
+
+func fn(d, d2 time.Duration, i int, f float64, s Seconds) {
+	_ = 5 * time.Second
+	_ = time.Second * 5
+	_ = n * time.Second
+	_ = (n + 1) * time.Second
+	_ = 1 << 3 * time.Millisecond
+	_ = time.Duration(i) * time.Second
+	_ = time.Second * time.Duration(i)
+	_ = time.Duration(f*1000) * time.Millisecond
+	_ = time.Duration(s) * time.Second
+	_ = d * 2
+	_ = d * time.Duration(i)
+	_ = d * d2 / time.Second
+	_ = (d * d2) / time.Second
+	_ = i * i
+
+	_ = 5 * time.Millisecond * time.Microsecond //@ diag(`multiplication of two time.Duration values`)
+	_ = d * d2                                  //@ diag(`multiplication of two time.Duration values`)
+	_ = d * time.Second                         //@ diag(`multiplication of two time.Duration values`)
+	_ = time.Duration(d) * time.Second          //@ diag(`multiplication of two time.Duration values`)
+	_ = time.Duration(i) * time.Second * d      //@ diag(`multiplication of two time.Duration values`)
+	_ = d * d2 / 2                              //@ diag(`multiplication of two time.Duration values`)
+}
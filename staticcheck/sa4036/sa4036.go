@@ -0,0 +1,61 @@
+package sa4036
+
+import (
+	"go/ast"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA4036",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `The result of \'append\' is discarded`,
+		Text: `\'append\' doesn't modify its argument in place. It returns the
+extended slice, which has to be assigned back, as in \'s = append(s,
+x)\'. Discarding the result makes the call a no-op.
+
+The compiler already rejects calls of \'append\' used as statements,
+but not assignments of the result to the blank identifier, as in
+\'_ = append(s, x)\'.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var (
+	assignQ = pattern.MustParse(`(AssignStmt lhs "=" rhs)`)
+	appendQ = pattern.MustParse(`(CallExpr (Builtin "append") _)`)
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	code.Matches(pass, assignQ, func(node ast.Node, m *pattern.Matcher) {
+		lhs := m.State["lhs"].([]ast.Expr)
+		rhs := m.State["rhs"].([]ast.Expr)
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, x := range rhs {
+			if !astutil.IsBlank(lhs[i]) {
+				continue
+			}
+			if _, ok := code.Match(pass, appendQ, astutil.Unparen(x)); ok {
+				report.Report(pass, x, "result of append is not used; append does not modify its argument in place")
+			}
+		}
+	})
+	return nil, nil
+}
@@ -0,0 +1,21 @@
+package pkg
+
+func fn(s []int, t []string) {
+	_ = append(s, 1)       //@ diag(`result of append is not used`)
+	_ = (append(s, 1, 2))  //@ diag(`result of append is not used`)
+	_, _ = append(s, 1), 1 //@ diag(`result of append is not used`)
+	s, _ = append(s, 1), len(s)
+	s = append(s, 1)
+	t = append(t, "")
+	_ = len(append(s, 1))
+	_ = s
+
+	var x []int
+	x = append(x, s...)
+	_ = x
+}
+
+func shadowed() {
+	append := func([]int, int) []int { return nil }
+	_ = append(nil, 1)
+}
@@ -0,0 +1,210 @@
+package sa4037
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA4037",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Error variable in an inner scope shadows an error variable that is used later`,
+		Text: `Declaring an error variable with \':=\' in an inner block creates a
+new variable, even if a variable of the same name exists in an outer
+scope. Errors assigned to the inner variable don't affect the outer
+one, so code after the block that checks the outer variable sees a
+stale value:
+
+    var err error
+    if cond {
+        x, err := fn()
+        use(x)
+    }
+    if err != nil {
+        // never sees the error returned by fn
+    }
+
+To avoid false positives, this check only flags shadowing of variables
+that haven't been used before, such as variables declared with \'var\'
+or named results, whose values after the block can therefore only be
+the zero value. Declarations in the initialization statements of
+\'if\', \'for\' and \'switch\' statements, such as \'if err :=
+fn(); err != nil\', aren't flagged, as this is an established idiom.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var errorIface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+type shadow struct {
+	fn    ast.Node
+	ident *ast.Ident
+	inner *types.Var
+	outer *types.Var
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	var shadows []shadow
+	// Identifiers that are assigned to, as opposed to read from.
+	assigned := map[*ast.Ident]bool{}
+	// Variables that are declared without a value.
+	zero := map[*types.Var]bool{}
+	addZero := func(names []*ast.Ident) {
+		for _, name := range names {
+			if v, ok := pass.TypesInfo.Defs[name].(*types.Var); ok {
+				zero[v] = true
+			}
+		}
+	}
+	var lits []*ast.FuncLit
+	fn := func(node ast.Node, stack []ast.Node) {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			lits = append(lits, node)
+			return
+		case *ast.ValueSpec:
+			if len(node.Values) == 0 {
+				addZero(node.Names)
+			}
+			return
+		case *ast.FuncType:
+			if node.Results != nil {
+				for _, field := range node.Results.List {
+					addZero(field.Names)
+				}
+			}
+			return
+		}
+		assign := node.(*ast.AssignStmt)
+		switch assign.Tok {
+		case token.ASSIGN:
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					assigned[ident] = true
+				}
+			}
+			return
+		case token.DEFINE:
+			// := assigns to variables that already exist in the same
+			// scope.
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && pass.TypesInfo.Uses[ident] != nil {
+					assigned[ident] = true
+				}
+			}
+		default:
+			return
+		}
+		switch stack[len(stack)-2].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		default:
+			// Initialization statement
+			return
+		}
+		fnNode := enclosingFunc(stack)
+		for _, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			inner, ok := pass.TypesInfo.Defs[ident].(*types.Var)
+			if !ok || !isError(inner.Type()) || inner.Parent() == nil {
+				// The scope may be missing for code with type errors.
+				continue
+			}
+			_, obj := inner.Parent().Parent().LookupParent(ident.Name, ident.Pos())
+			outer, ok := obj.(*types.Var)
+			if !ok || outer.Pos() < fnNode.Pos() || !zero[outer] || !isError(outer.Type()) {
+				continue
+			}
+			shadows = append(shadows, shadow{fnNode, ident, inner, outer})
+		}
+	}
+	code.PreorderStack(pass, fn, (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil), (*ast.FuncType)(nil), (*ast.FuncLit)(nil))
+	if len(shadows) == 0 {
+		return nil, nil
+	}
+
+	uses := map[*types.Var][]*ast.Ident{}
+	for _, s := range shadows {
+		uses[s.outer] = nil
+	}
+	for ident, obj := range pass.TypesInfo.Uses {
+		if v, ok := obj.(*types.Var); ok {
+			if _, ok := uses[v]; ok {
+				uses[v] = append(uses[v], ident)
+			}
+		}
+	}
+
+shadowLoop:
+	for _, s := range shadows {
+		// Find the first use of the outer variable after the inner
+		// variable has gone out of scope. If it overwrites the
+		// variable, its old value doesn't matter. Uses in function
+		// literals, such as deferred functions, happen at unknown
+		// times and are ignored. If the outer variable has been used
+		// before the shadowing, it may hold a meaningful value.
+		end := s.inner.Parent().End()
+		var first *ast.Ident
+		for _, ident := range uses[s.outer] {
+			if ident.Pos() < s.ident.Pos() {
+				continue shadowLoop
+			}
+			if ident.Pos() > end && (first == nil || ident.Pos() < first.Pos()) && !inFuncLit(lits, s.fn, ident) {
+				first = ident
+			}
+		}
+		if first == nil || assigned[first] {
+			continue
+		}
+		name := s.ident.Name
+		report.Report(pass, s.ident,
+			fmt.Sprintf("inner %s shadows outer %s; the outer value may be stale", name, name),
+			report.Related(s.outer, fmt.Sprintf("outer %s is declared here", name)),
+			report.Related(first, fmt.Sprintf("outer %s is used here", name)))
+	}
+	return nil, nil
+}
+
+func enclosingFunc(stack []ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return stack[i]
+		}
+	}
+	panic("assignment statement outside of function")
+}
+
+// inFuncLit reports whether ident is in a function literal nested in
+// fn.
+func inFuncLit(lits []*ast.FuncLit, fn ast.Node, ident *ast.Ident) bool {
+	for _, lit := range lits {
+		if lit.Pos() > fn.Pos() && lit.End() <= fn.End() && lit.Pos() <= ident.Pos() && ident.End() <= lit.End() {
+			return true
+		}
+	}
+	return false
+}
+
+func isError(T types.Type) bool {
+	return types.Implements(T, errorIface)
+}
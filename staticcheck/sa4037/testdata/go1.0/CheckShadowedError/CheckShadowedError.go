@@ -0,0 +1,134 @@
+package pkg
+
+func fn1(cond bool) error {
+	var err error
+	if cond {
+		x, err := get() //@ diag(`inner err shadows outer err; the outer value may be stale`)
+		println(x, err)
+	}
+	return err
+}
+
+func fn2(cond bool) error {
+	err := do()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 10; i++ {
+		// The outer err has a meaningful value.
+		x, err := get()
+		if err != nil {
+			println(x)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func fn3(cond bool) error {
+	err := do()
+	if err != nil {
+		return err
+	}
+	if cond {
+		// The outer err isn't used after this block.
+		x, err := get()
+		if err != nil {
+			return err
+		}
+		println(x)
+	}
+	return nil
+}
+
+func fn4(cond bool) error {
+	err := do()
+	if cond {
+		// The outer err is overwritten after this block.
+		x, err := get()
+		println(x, err)
+	}
+	err = do()
+	return err
+}
+
+func fn5() error {
+	err := do()
+	if err := do(); err != nil {
+		return err
+	}
+	return err
+}
+
+func fn6(cond bool) (err error) {
+	switch {
+	case cond:
+		err := do() //@ diag(`inner err shadows outer err; the outer value may be stale`)
+		println(err)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func fn7() error {
+	err := do()
+	func() {
+		// Different function
+		err := do()
+		println(err)
+	}()
+	return err
+}
+
+func fn8(cond bool) error {
+	var err error
+	if cond {
+		// Not an error
+		err := 1
+		println(err)
+	}
+	return err
+}
+
+func fn9(cond bool) error {
+	var err error
+	if cond {
+		err = do()
+	}
+	if cond {
+		// The outer err may have been assigned.
+		x, err := get()
+		println(x, err)
+	}
+	return err
+}
+
+func fn10(cond bool) (err error) {
+	defer func() {
+		// Runs at the end of the function
+		println(err)
+	}()
+	if cond {
+		x, err := get()
+		println(x, err)
+	}
+	return nil
+}
+
+func fn11(cond bool) (err error) {
+	if cond {
+		x, err := get()
+		println(x, err)
+	}
+	// Assigns to the named result
+	y, err := get()
+	println(y)
+	return err
+}
+
+func get() (int, error) { return 0, nil }
+func do() error         { return nil }
@@ -3,12 +3,14 @@ package sa5008
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"sort"
 	"strings"
 	"unicode"
 
 	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/go/types/typeutil"
@@ -19,6 +21,11 @@ import (
 	"golang.org/x/tools/go/analysis/passes/inspect"
 )
 
+var (
+	jsonOptions = []string{"string", "omitzero", "omitempty", "nocase", "inline", "unknown"}
+	xmlOptions  = []string{"attr", "chardata", "cdata", "innerxml", "comment", "omitempty", "any"}
+)
+
 var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 	Analyzer: &analysis.Analyzer{
 		Name:     "SA5008",
@@ -26,7 +33,11 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 		Requires: []*analysis.Analyzer{inspect.Analyzer},
 	},
 	Doc: &lint.RawDocumentation{
-		Title:    `Invalid struct tag`,
+		Title: `Invalid struct tag`,
+		Text: `Struct tags that can't be parsed, duplicate keys, and malformed
+\'json\' and \'xml\' tags are flagged. For misspelled options, such
+as \'omitempty\' spelled \'ommitempty\', a fix that replaces them
+with the closest known option is offered.`,
 		Since:    "2019.2",
 		Severity: lint.SeverityWarning,
 		MergeIf:  lint.MergeIfAny,
@@ -105,6 +116,7 @@ func checkJSONTag(pass *analysis.Pass, field *ast.Field, tag string) {
 		tag = tag[:i]
 	}
 	fields := strings.Split(tag, ",")
+	offset := len(fields[0]) + 1
 	for _, r := range fields[0] {
 		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("!#$%&()*+-./:<=>?@[]^_{|}~ ", r) {
 			report.Report(pass, field.Tag, fmt.Sprintf("invalid JSON field name %q", fields[0]))
@@ -112,6 +124,8 @@ func checkJSONTag(pass *analysis.Pass, field *ast.Field, tag string) {
 	}
 	options := make(map[string]int)
 	for _, s := range fields[1:] {
+		start := offset
+		offset += len(s) + 1
 		switch s {
 		case "":
 			// allow stuff like "-,"
@@ -137,7 +151,8 @@ func checkJSONTag(pass *analysis.Pass, field *ast.Field, tag string) {
 		case "omitzero", "omitempty", "nocase", "inline", "unknown":
 			options[s]++
 		default:
-			report.Report(pass, field.Tag, fmt.Sprintf("unknown JSON option %q", s))
+			report.Report(pass, field.Tag, fmt.Sprintf("unknown JSON option %q", s),
+				optionFix(field, "json", tag, start, s, jsonOptions)...)
 		}
 	}
 	var duplicates []string
@@ -160,7 +175,10 @@ func checkXMLTag(pass *analysis.Pass, field *ast.Field, tag string) {
 	}
 	fields := strings.Split(tag, ",")
 	counts := map[string]int{}
+	offset := len(fields[0]) + 1
 	for _, s := range fields[1:] {
+		start := offset
+		offset += len(s) + 1
 		switch s {
 		case "attr", "chardata", "cdata", "innerxml", "comment":
 			counts[s]++
@@ -168,7 +186,8 @@ func checkXMLTag(pass *analysis.Pass, field *ast.Field, tag string) {
 			counts[s]++
 		case "":
 		default:
-			report.Report(pass, field.Tag, fmt.Sprintf("invalid XML tag: unknown option %q", s))
+			report.Report(pass, field.Tag, fmt.Sprintf("invalid XML tag: unknown option %q", s),
+				optionFix(field, "xml", tag, start, s, xmlOptions)...)
 		}
 	}
 	for k, v := range counts {
@@ -177,3 +196,53 @@ func checkXMLTag(pass *analysis.Pass, field *ast.Field, tag string) {
 		}
 	}
 }
+
+// optionFix returns a fix that replaces the unknown option s, found at
+// offset start in the value of key in field's tag, with the most
+// similar known option, if there is one that s is likely a misspelling
+// of.
+func optionFix(field *ast.Field, key, value string, start int, s string, known []string) []report.Option {
+	best, bestDist := "", -1
+	for _, opt := range known {
+		d := levenshtein(s, opt)
+		if bestDist == -1 || d < bestDist {
+			best, bestDist = opt, d
+		} else if d == bestDist {
+			// Ambiguous
+			best = ""
+		}
+	}
+	if best == "" || bestDist > 2 || bestDist*3 > len(best) {
+		return nil
+	}
+
+	i := strings.Index(field.Tag.Value, key+`:"`+value+`"`)
+	if i == -1 {
+		return nil
+	}
+	pos := field.Tag.Pos() + token.Pos(i+len(key)+2+start)
+	fix := edit.Fix(fmt.Sprintf("replace %q with %q", s, best),
+		edit.ReplaceWithString(edit.Range{pos, pos + token.Pos(len(s))}, best))
+	return []report.Option{report.Fixes(fix)}
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,10 @@
+package pkg
+
+type T1 struct {
+	A int `json:"a,ommitempty"`       //@ diag(`unknown JSON option "ommitempty"`)
+	B int `json:",strng"`             //@ diag(`unknown JSON option "strng"`)
+	C int `json:"c,string,omitemtpy"` //@ diag(`unknown JSON option "omitemtpy"`)
+	D int `json:"d,foreign"`          //@ diag(`unknown JSON option "foreign"`)
+	E int `xml:",atrr"`               //@ diag(`invalid XML tag: unknown option "atrr"`)
+	F int `xml:"f,chardta"`           //@ diag(`invalid XML tag: unknown option "chardta"`)
+}
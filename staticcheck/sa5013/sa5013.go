@@ -0,0 +1,119 @@
+package sa5013
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA5013",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Comparing interface values holding non-comparable types`,
+		Text: `
+Slices, maps and functions can't be compared with \'==\' and \'!=\', and
+doing so directly is a compile-time error. When the values are stored
+in interfaces, however, the comparison compiles and instead panics at
+runtime if both interfaces hold values of the same non-comparable
+type:
+
+    var a, b []byte
+    interface{}(a) == interface{}(b) // panics
+
+The same is true when only one of the interfaces is known to hold a
+non-comparable value, in which case the comparison panics if the
+other interface holds a value of the same type:
+
+    var i interface{} = []int{1}
+    if i == other { // panics if other holds a []int
+        ...
+    }
+
+Use a typed comparison instead, such as \'bytes.Equal\' or
+\'slices.Equal\'.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityError,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				binop, ok := ins.(*ir.BinOp)
+				if !ok || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+					continue
+				}
+				if !types.IsInterface(binop.X.Type()) || !types.IsInterface(binop.Y.Type()) {
+					continue
+				}
+				tx := dynamicType(binop.X)
+				ty := dynamicType(binop.Y)
+				if tx == nil && ty == nil {
+					continue
+				}
+				if tx == nil || ty == nil {
+					// Only one of the dynamic types is known. The
+					// comparison panics if the other interface holds
+					// a value of the same, non-comparable type.
+					known, other := tx, binop.Y
+					if known == nil {
+						known, other = ty, binop.X
+					}
+					if types.Comparable(known) {
+						continue
+					}
+					if _, ok := irutil.Flatten(other).(*ir.Const); ok {
+						// Comparing against nil never panics.
+						continue
+					}
+					report.Report(pass, binop,
+						fmt.Sprintf("comparing an interface value holding %s with another interface value will panic at runtime if the other value is of the same type, because the type is not comparable",
+							types.TypeString(known, types.RelativeTo(pass.Pkg))))
+					continue
+				}
+				if !types.Identical(tx, ty) {
+					// Interfaces holding values of different types
+					// compare as unequal without panicking.
+					continue
+				}
+				if types.Comparable(tx) {
+					continue
+				}
+				report.Report(pass, binop,
+					fmt.Sprintf("comparing interface values holding %s will panic at runtime, because the type is not comparable",
+						types.TypeString(tx, types.RelativeTo(pass.Pkg))))
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dynamicType returns the dynamic type of the interface value v, if
+// it is statically known.
+func dynamicType(v ir.Value) types.Type {
+	iface, ok := irutil.Flatten(v).(*ir.MakeInterface)
+	if !ok {
+		return nil
+	}
+	typ := iface.X.Type()
+	if _, ok := typ.(*types.TypeParam); ok {
+		return nil
+	}
+	return typ
+}
@@ -0,0 +1,39 @@
+package pkg
+
+type T struct {
+	s []int
+}
+
+func fn1(a, b []byte, m1, m2 map[string]int, f1, f2 func()) {
+	_ = interface{}(a) == interface{}(b)     //@ diag(`comparing interface values holding []byte will panic`)
+	_ = interface{}(a) != interface{}(b)     //@ diag(`will panic`)
+	_ = interface{}(m1) == interface{}(m2)   //@ diag(`holding map[string]int`)
+	_ = interface{}(f1) == interface{}(f2)   //@ diag(`holding func()`)
+	_ = interface{}(T{}) == interface{}(T{}) //@ diag(`holding T`)
+
+	var x, y interface{} = a, b
+	if x == y { //@ diag(`will panic`)
+		println()
+	}
+}
+
+func fn2(a []byte, s1, s2 string, x, y interface{}) {
+	// Different dynamic types compare as unequal
+	_ = interface{}(a) == interface{}(s1)
+	// Comparable types
+	_ = interface{}(s1) == interface{}(s2)
+	// Comparing against nil
+	_ = interface{}(a) == nil
+	// Unknown dynamic types
+	_ = x == y
+	_ = interface{}(s1) == y
+}
+
+func fn3(a []byte, m map[string]int, other interface{}) {
+	var i interface{} = []int{1}
+	if i == other { //@ diag(`comparing an interface value holding []int with another interface value will panic at runtime if the other value is of the same type`)
+		println()
+	}
+	_ = other != interface{}(m) //@ diag(`holding map[string]int`)
+	_ = interface{}(a) == other //@ diag(`holding []byte`)
+}
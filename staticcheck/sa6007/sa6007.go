@@ -0,0 +1,111 @@
+package sa6007
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/pattern"
+	"honnef.co/go/tools/printf"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA6007",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Using \'fmt.Sprintf\' to build a composite map key`,
+		Text: `
+Building map keys by formatting several values into a string, as in
+
+    m[fmt.Sprintf("%d-%d", a, b)]
+
+allocates and formats a new string for every map access. Go allows
+any comparable type to be used as a map key, including structs, so the
+same can be achieved more efficiently with a struct key:
+
+    type key struct{ a, b int }
+    m[key{a, b}]
+
+This check only flags calls whose format string consists of plain
+verbs, and whose arguments are all integers, strings or booleans.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityInfo,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var sprintfKeyQ = pattern.MustParse(`(IndexExpr m call@(CallExpr (Symbol "fmt.Sprintf") format:args))`)
+
+func run(pass *analysis.Pass) (any, error) {
+	fn := func(node ast.Node) {
+		m, ok := code.Match(pass, sprintfKeyQ, node)
+		if !ok {
+			return
+		}
+		if _, ok := pass.TypesInfo.TypeOf(m.State["m"].(ast.Expr)).Underlying().(*types.Map); !ok {
+			return
+		}
+		args := m.State["args"].([]ast.Expr)
+		if len(args) < 2 {
+			// A single value can be used as the key directly, which is a different suggestion.
+			return
+		}
+		k := pass.TypesInfo.Types[m.State["format"].(ast.Expr)].Value
+		if k == nil || k.Kind() != constant.String {
+			return
+		}
+		if !isSimpleFormat(constant.StringVal(k), len(args)) {
+			return
+		}
+		for _, arg := range args {
+			basic, ok := pass.TypesInfo.TypeOf(arg).Underlying().(*types.Basic)
+			if !ok || basic.Info()&(types.IsInteger|types.IsString|types.IsBoolean) == 0 {
+				return
+			}
+		}
+		report.Report(pass, m.State["call"].(ast.Node), "map key is built with fmt.Sprintf, consider using a comparable struct as the key instead")
+	}
+	code.Preorder(pass, fn, (*ast.IndexExpr)(nil))
+	return nil, nil
+}
+
+// isSimpleFormat reports whether f consists of exactly n verbs without
+// flags, widths, precisions or explicit argument indices.
+func isSimpleFormat(f string, n int) bool {
+	actions, err := printf.Parse(f)
+	if err != nil {
+		return false
+	}
+	verbs := 0
+	for _, action := range actions {
+		verb, ok := action.(printf.Verb)
+		if !ok || verb.Value == 0 {
+			continue
+		}
+		if verb.Value != -1 || verb.Flags != "" {
+			return false
+		}
+		if verb.Width != (printf.Default{}) || verb.Precision != (printf.Default{}) {
+			return false
+		}
+		switch verb.Letter {
+		case 'd', 's', 'v', 't', 'q':
+		default:
+			return false
+		}
+		verbs++
+	}
+	return verbs == n
+}
@@ -0,0 +1,25 @@
+package pkg
+
+import "fmt"
+
+type key struct {
+	a, b int
+}
+
+func fn(a, b int, s string, f float64) {
+	m := map[string]int{}
+	_ = m[fmt.Sprintf("%d-%d", a, b)]  //@ diag(`consider using a comparable struct`)
+	m[fmt.Sprintf("%s:%d", s, a)] = 1  //@ diag(`consider using a comparable struct`)
+	_ = m[fmt.Sprintf("%v%%%v", a, s)] //@ diag(`consider using a comparable struct`)
+
+	_ = m[fmt.Sprintf("%d", a)]
+	_ = m[fmt.Sprintf("%05d-%d", a, b)]
+	_ = m[fmt.Sprintf("%d-%f", a, f)]
+	_ = m[fmt.Sprintf("%[2]d-%[1]d", a, b)]
+
+	m2 := map[key]int{}
+	_ = m2[key{a, b}]
+
+	sl := []int{}
+	_ = sl[len(fmt.Sprintf("%d-%d", a, b))]
+}
@@ -0,0 +1,181 @@
+package sa6008
+
+import (
+	"fmt"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/facts/purity"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA6008",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer, purity.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Loop-invariant call to a function without side effects`,
+		Text: `
+A call to a function that has no side effects, whose arguments don't
+change between iterations of a loop, computes the same result in every
+iteration. The call can be moved above the loop and its result reused:
+
+    for _, s := range items {
+        if strings.ToLower(s) == strings.ToLower(prefix) { ... }
+    }
+
+can be rewritten as
+
+    lprefix := strings.ToLower(prefix)
+    for _, s := range items {
+        if strings.ToLower(s) == lprefix { ... }
+    }
+
+This check only flags calls that are executed in every iteration of
+the loop.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityInfo,
+		MergeIf:    lint.MergeIfAll,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	pure := pass.ResultOf[purity.Analyzer].(purity.Result)
+
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		if !fn.HasLoops() || code.IsInTest(pass, fn) {
+			// Tests and benchmarks call functions repeatedly on purpose.
+			continue
+		}
+		reported := map[*ir.Call]struct{}{}
+		for _, latch := range fn.Blocks {
+			for _, header := range latch.Succs {
+				if !header.Dominates(latch) {
+					continue
+				}
+				body := naturalLoop(header, latch)
+				for b := range body {
+					if !b.Dominates(latch) {
+						// The block isn't executed in every iteration,
+						// hoisting calls out of it may introduce
+						// panics or needless work.
+						continue
+					}
+					for _, ins := range b.Instrs {
+						call, ok := ins.(*ir.Call)
+						if !ok {
+							continue
+						}
+						if _, ok := reported[call]; ok {
+							continue
+						}
+						callee := call.Common().StaticCallee()
+						if callee == nil || callee.Object() == nil {
+							continue
+						}
+						if _, ok := pure[callee.Object().(*types.Func)]; !ok {
+							continue
+						}
+						refs := call.Referrers()
+						if refs == nil || len(irutil.FilterDebug(*refs)) == 0 {
+							// Discarded results are flagged by SA4017.
+							continue
+						}
+						if len(call.Common().Args) == 0 {
+							// Pure functions without arguments are
+							// either trivial, or, like time.Now, only
+							// considered pure for the purpose of
+							// flagging discarded results.
+							continue
+						}
+						if !isInvariant(call.Common().Args, body) {
+							continue
+						}
+						reported[call] = struct{}{}
+						report.Report(pass, call,
+							fmt.Sprintf("call to %s has no side effects and its arguments don't change inside the loop, consider moving it out of the loop", callee.Object().Name()))
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// naturalLoop returns the blocks of the natural loop of the back edge
+// from latch to header, that is, header and all blocks that can reach
+// latch without going through header.
+func naturalLoop(header, latch *ir.BasicBlock) map[*ir.BasicBlock]struct{} {
+	body := map[*ir.BasicBlock]struct{}{header: {}}
+	work := []*ir.BasicBlock{latch}
+	for len(work) > 0 {
+		b := work[len(work)-1]
+		work = work[:len(work)-1]
+		if _, ok := body[b]; ok {
+			continue
+		}
+		body[b] = struct{}{}
+		work = append(work, b.Preds...)
+	}
+	return body
+}
+
+// isInvariant reports whether all args are defined outside of the
+// loop consisting of the blocks in body. Sigma and phi nodes inside the
+// loop are looked through, as they merely forward values.
+func isInvariant(args []ir.Value, body map[*ir.BasicBlock]struct{}) bool {
+	inLoop := func(v ir.Value) bool {
+		ins, ok := v.(ir.Instruction)
+		if !ok || ins.Block() == nil {
+			return false
+		}
+		_, ok = body[ins.Block()]
+		return ok
+	}
+
+	for _, arg := range args {
+		var def ir.Value
+		seen := map[ir.Value]struct{}{}
+		var resolve func(v ir.Value) bool
+		resolve = func(v ir.Value) bool {
+			if _, ok := seen[v]; ok {
+				return true
+			}
+			seen[v] = struct{}{}
+			if inLoop(v) {
+				switch v := v.(type) {
+				case *ir.Sigma:
+					return resolve(v.X)
+				case *ir.Phi:
+					for _, e := range v.Edges {
+						if !resolve(e) {
+							return false
+						}
+					}
+					return true
+				default:
+					return false
+				}
+			}
+			if def == nil {
+				def = v
+			}
+			return def == v
+		}
+		if !resolve(arg) {
+			return false
+		}
+	}
+	return true
+}
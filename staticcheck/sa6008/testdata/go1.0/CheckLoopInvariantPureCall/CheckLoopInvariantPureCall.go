@@ -0,0 +1,65 @@
+package pkg
+
+import "strings"
+
+func add(a, b int) int { return a + b }
+
+func fn1(items []string, prefix string) int {
+	n := 0
+	for _, s := range items {
+		if strings.ToLower(s) == strings.ToLower(prefix) { //@ diag(`consider moving it out of the loop`)
+			n++
+		}
+	}
+	return n
+}
+
+func fn2(x, y int) int {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		sum += add(x, y) //@ diag(`call to add has no side effects`)
+	}
+	return sum
+}
+
+func fn3(x int) int {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		// Depends on the loop variable
+		sum += add(x, i)
+	}
+	return sum
+}
+
+func fn4(x, y int) int {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		// Not executed in every iteration
+		if i%2 == 0 {
+			sum += add(x, y)
+		}
+		println(sum)
+	}
+	return sum
+}
+
+func fn5(x, y int) {
+	for i := 0; i < 10; i++ {
+		// Result is discarded
+		add(x, y)
+	}
+}
+
+func fn6(x, y int) int {
+	// Not in a loop
+	return add(x, y)
+}
+
+func fn7(items []string) int {
+	n := 0
+	for _, s := range items {
+		s = strings.TrimSpace(s)
+		n += len(strings.ToUpper(s))
+	}
+	return n
+}
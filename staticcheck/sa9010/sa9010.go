@@ -0,0 +1,81 @@
+package sa9010
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/pattern"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9010",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Unexported struct field has an encoding struct tag`,
+		Text: `
+Packages such as \'encoding/json\' and \'encoding/xml\' only operate on
+exported struct fields. Unexported fields are ignored entirely, which means
+that struct tags such as \'json:"name"\' have no effect on them. This is
+usually a sign that the field was meant to be exported, or that the tag
+is a leftover and should be removed.
+
+This check flags unexported fields with \'json\', \'xml\' or \'yaml\' struct
+tags. Embedded fields are not flagged, as the encoders do consider the
+exported fields of embedded structs, even if the embedded type is
+unexported.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var taggedFieldQ = pattern.MustParse(`(Field names@(Ident _):_ _ tag@(BasicLit "STRING" _))`)
+
+var encodingTags = []string{"json", "xml", "yaml"}
+
+func run(pass *analysis.Pass) (any, error) {
+	fn := func(node ast.Node) {
+		for _, field := range node.(*ast.StructType).Fields.List {
+			m, ok := code.Match(pass, taggedFieldQ, field)
+			if !ok {
+				continue
+			}
+			lit := m.State["tag"].(*ast.BasicLit)
+			s, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				continue
+			}
+			tags := reflect.StructTag(s)
+
+			for _, key := range encodingTags {
+				v, ok := tags.Lookup(key)
+				if !ok || v == "-" {
+					continue
+				}
+				for _, name := range field.Names {
+					if name.IsExported() || name.Name == "_" {
+						continue
+					}
+					report.Report(pass, name,
+						fmt.Sprintf("unexported field %s has a %s struct tag, which has no effect; export the field or remove the tag", name.Name, key))
+				}
+				break
+			}
+		}
+	}
+	code.Preorder(pass, fn, (*ast.StructType)(nil))
+	return nil, nil
+}
@@ -0,0 +1,21 @@
+package pkg
+
+type T1 struct {
+	Exported   int `json:"exported"`
+	unexported int `json:"unexported"` //@ diag(`unexported field unexported has a json struct tag`)
+	a, B       int `xml:"a"`           //@ diag(`unexported field a has a xml struct tag`)
+	c          int `yaml:"c"`          //@ diag(`unexported field c has a yaml struct tag`)
+	d          int `json:"-"`
+	e          int `db:"e"`
+	f          int
+	_          int `json:"pad"`
+	embedded   `json:"embedded"`
+}
+
+type embedded struct{}
+
+func fn() {
+	_ = struct {
+		x int `json:"x"` //@ diag(`unexported field x`)
+	}{}
+}
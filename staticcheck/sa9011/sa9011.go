@@ -0,0 +1,209 @@
+package sa9011
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9011",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Deferred method call on a variable that is reassigned later`,
+		Text: `
+The receiver and arguments of a deferred call are evaluated when the
+defer statement executes, not when the deferred call runs. In
+
+    f, err := os.Open("a")
+    defer f.Close()
+    ...
+    f, err = os.Open("b")
+
+the deferred call closes the first file, and the second file is never
+closed. This check flags deferred method calls whose receiver variable
+is assigned a new value that may reach the end of the function without
+being deferred again.
+
+Methods with pointer receivers called on addressable values aren't
+affected, as the deferred call receives a pointer to the variable.`,
+		Since:    "Unreleased",
+		Severity: lint.SeverityWarning,
+		MergeIf:  lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		// Map identifiers on the left-hand side of assignments to the
+		// DebugRefs of the values being assigned.
+		assigns := map[*ast.Ident]*ir.DebugRef{}
+		var defers []*ir.Defer
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				switch ins := ins.(type) {
+				case *ir.DebugRef:
+					if ident, ok := ins.Expr.(*ast.Ident); ok && !ins.IsAddr {
+						assigns[ident] = ins
+					}
+				case *ir.Defer:
+					defers = append(defers, ins)
+				}
+			}
+		}
+
+		// Map variables to all deferred method calls on them. A
+		// reassignment is covered by any of them, not just by the one
+		// deferring a call on the variable's previous value.
+		deferredOn := map[*types.Var][]ir.Instruction{}
+		for _, d := range defers {
+			if _, _, obj, ok := deferredMethod(pass, d); ok {
+				deferredOn[obj] = append(deferredOn[obj], d)
+			}
+		}
+
+		for _, d := range defers {
+			stmt, sel, obj, ok := deferredMethod(pass, d)
+			if !ok {
+				continue
+			}
+			recv := sel.X.(*ast.Ident)
+			if obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() {
+				// Only local variables; globals can be modified from
+				// anywhere.
+				continue
+			}
+			selection, ok := pass.TypesInfo.Selections[sel]
+			if !ok || selection.Kind() != types.MethodVal {
+				continue
+			}
+			if _, ok := selection.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer); ok {
+				if _, ok := obj.Type().Underlying().(*types.Pointer); !ok {
+					// The method is called on the variable's address.
+					continue
+				}
+			}
+
+			ast.Inspect(fn.Source(), func(node ast.Node) bool {
+				assign, ok := node.(*ast.AssignStmt)
+				if !ok {
+					return true
+				}
+				for _, lhs := range assign.Lhs {
+					ident, ok := lhs.(*ast.Ident)
+					if !ok || pass.TypesInfo.Uses[ident] != obj {
+						// Definitions of new variables, including
+						// the same variable in a new loop iteration,
+						// aren't reassignments.
+						continue
+					}
+					ref, ok := assigns[ident]
+					if !ok {
+						// The assignment is in a different function
+						continue
+					}
+					if reaches(d, ref) && exitsAvoiding(ref, deferredOn[obj]) {
+						report.Report(pass, stmt,
+							fmt.Sprintf("deferred call to %s.%s uses the value %s has at the time of the defer statement, but %s is reassigned later",
+								recv.Name, sel.Sel.Name, recv.Name, recv.Name),
+							report.Related(assign, fmt.Sprintf("%s is reassigned here", recv.Name)))
+						return false
+					}
+				}
+				return true
+			})
+		}
+	}
+	return nil, nil
+}
+
+// deferredMethod returns the defer statement of d, the selector of
+// the method it calls, and the variable it calls the method on. It
+// returns false if d doesn't defer a method call on a variable.
+func deferredMethod(pass *analysis.Pass, d *ir.Defer) (*ast.DeferStmt, *ast.SelectorExpr, *types.Var, bool) {
+	stmt, ok := d.Source().(*ast.DeferStmt)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	sel, ok := stmt.Call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	recv, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	obj, ok := pass.TypesInfo.Uses[recv].(*types.Var)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return stmt, sel, obj, true
+}
+
+func index(ins ir.Instruction) int {
+	for i, other := range ins.Block().Instrs {
+		if other == ins {
+			return i
+		}
+	}
+	return -1
+}
+
+// reaches reports whether b can execute after a.
+func reaches(a, b ir.Instruction) bool {
+	if a.Block() == b.Block() && index(a) < index(b) {
+		return true
+	}
+	for _, succ := range a.Block().Succs {
+		if irutil.Reachable(succ, b.Block()) {
+			return true
+		}
+	}
+	return false
+}
+
+// exitsAvoiding reports whether the function can return after
+// executing from without executing any of the instructions in avoid.
+func exitsAvoiding(from ir.Instruction, avoid []ir.Instruction) bool {
+	avoidBlocks := map[*ir.BasicBlock]struct{}{}
+	for _, ins := range avoid {
+		if from.Block() == ins.Block() && index(from) < index(ins) {
+			return false
+		}
+		avoidBlocks[ins.Block()] = struct{}{}
+	}
+	seen := map[*ir.BasicBlock]struct{}{}
+	var dfs func(b *ir.BasicBlock) bool
+	dfs = func(b *ir.BasicBlock) bool {
+		if len(b.Succs) == 0 {
+			return true
+		}
+		for _, succ := range b.Succs {
+			if _, ok := avoidBlocks[succ]; ok {
+				continue
+			}
+			if _, ok := seen[succ]; ok {
+				continue
+			}
+			seen[succ] = struct{}{}
+			if dfs(succ) {
+				return true
+			}
+		}
+		return false
+	}
+	return dfs(from.Block())
+}
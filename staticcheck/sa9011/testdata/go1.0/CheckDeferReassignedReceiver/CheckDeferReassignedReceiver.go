@@ -0,0 +1,99 @@
+package pkg
+
+type File struct{ fd int }
+
+func (f *File) Close() error { return nil }
+
+type Value struct{ n int }
+
+func (v Value) Close() error          { return nil }
+func (v *Value) PtrClose() error      { return nil }
+func Open(name string) (*File, error) { return &File{}, nil }
+
+func fn1() {
+	f, _ := Open("a")
+	defer f.Close() //@ diag(`deferred call to f.Close uses the value f has at the time of the defer statement`)
+	println(f)
+	f, _ = Open("b")
+	println(f)
+}
+
+func fn2() {
+	f, _ := Open("a")
+	defer f.Close()
+	println(f)
+}
+
+func fn3(names []string) {
+	// A new variable in every iteration
+	for _, name := range names {
+		f, _ := Open(name)
+		defer f.Close()
+	}
+}
+
+func fn4(names []string) {
+	var f *File
+	// Every value of f is deferred
+	for _, name := range names {
+		f, _ = Open(name)
+		defer f.Close()
+	}
+}
+
+func fn5() {
+	var v Value
+	defer v.Close() //@ diag(`deferred call to v.Close`)
+	v = Value{1}
+	println(v.n)
+}
+
+func fn6() {
+	var v Value
+	// The deferred call receives &v
+	defer v.PtrClose()
+	v = Value{1}
+	println(v.n)
+}
+
+func fn7(cond bool) {
+	f, _ := Open("a")
+	// Reassigned before the defer
+	f, _ = Open("b")
+	defer f.Close()
+	if cond {
+		println(f)
+	}
+}
+
+func fn8(cond bool) {
+	f, _ := Open("a")
+	defer f.Close() //@ diag(`deferred call to f.Close`)
+	if cond {
+		f, _ = Open("b")
+		println(f)
+	}
+	println(f)
+}
+
+func fn9() {
+	f, _ := Open("a")
+	defer f.Close()
+	println(f)
+	// The new value is deferred, too
+	f, _ = Open("b")
+	defer f.Close()
+	println(f)
+}
+
+func fn10(cond bool) {
+	f, _ := Open("a")
+	defer f.Close() //@ diag(`deferred call to f.Close`)
+	println(f)
+	f, _ = Open("b")
+	if cond {
+		// Doesn't cover the path where cond is false
+		defer f.Close()
+	}
+	println(f)
+}
@@ -0,0 +1,179 @@
+package sa9012
+
+import (
+	"go/types"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9012",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Error is both logged and returned`,
+		Text: `
+Logging an error and then returning it, as in
+
+    if err != nil {
+        log.Printf("couldn't open file: %v", err)
+        return err
+    }
+
+handles the same error twice. The caller, which can't know that the
+error has already been logged, will usually log it again, leading to
+the same failure being reported at several layers. An error should
+either be handled, for example by logging it, or be returned to the
+caller, possibly with added context, but not both.
+
+This check flags calls to the printing functions of the \'log\'
+package that are immediately followed by a return of the logged
+error.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityInfo,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+var logFuncs = []string{
+	"log.Print",
+	"log.Printf",
+	"log.Println",
+	"(*log.Logger).Print",
+	"(*log.Logger).Printf",
+	"(*log.Logger).Println",
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		if fn.Exit == nil {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			instrs := irutil.FilterDebug(b.Instrs)
+			// The log call has to be immediately followed by the
+			// return, save for the conversion of the returned values
+			// to the result types.
+			i := len(instrs) - 2
+			for i >= 0 && isConversion(instrs[i]) {
+				i--
+			}
+			if i < 0 {
+				continue
+			}
+			call, ok := instrs[i].(*ir.Call)
+			if !ok || !irutil.IsCallToAny(call.Common(), logFuncs...) {
+				continue
+			}
+			results := returnedValues(fn, instrs[len(instrs)-1])
+			if results == nil {
+				continue
+			}
+			for _, arg := range loggedValues(call) {
+				if !types.Implements(arg.Type(), errorType) {
+					continue
+				}
+				for _, res := range results {
+					if unwrap(res) == arg {
+						report.Report(pass, call, "error is logged and then returned, which will likely cause it to be handled twice; either handle the error or return it")
+						break
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// returnedValues returns the values that the function returns after
+// executing term, or nil if term doesn't return from the function.
+func returnedValues(fn *ir.Function, term ir.Instruction) []ir.Value {
+	switch term := term.(type) {
+	case *ir.Return:
+		return term.Results
+	case *ir.Jump:
+		if term.Block().Succs[0] != fn.Exit {
+			return nil
+		}
+		ret, ok := fn.Exit.Control().(*ir.Return)
+		if !ok {
+			return nil
+		}
+		pred := -1
+		for i, b := range fn.Exit.Preds {
+			if b == term.Block() {
+				pred = i
+			}
+		}
+		out := make([]ir.Value, len(ret.Results))
+		for i, res := range ret.Results {
+			if phi, ok := res.(*ir.Phi); ok && phi.Block() == fn.Exit {
+				res = phi.Edges[pred]
+			}
+			out[i] = res
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// loggedValues returns the values passed to a call of one of the
+// variadic logging functions.
+func loggedValues(call *ir.Call) []ir.Value {
+	args := call.Common().Args
+	if len(args) == 0 {
+		return nil
+	}
+	slice, ok := args[len(args)-1].(*ir.Slice)
+	if !ok {
+		return nil
+	}
+	vals, ok := irutil.Vararg(slice)
+	if !ok {
+		return nil
+	}
+	for i, v := range vals {
+		vals[i] = unwrap(v)
+	}
+	return vals
+}
+
+func isConversion(instr ir.Instruction) bool {
+	switch instr.(type) {
+	case *ir.MakeInterface, *ir.ChangeInterface:
+		return true
+	default:
+		return false
+	}
+}
+
+// unwrap returns the value that v was derived from by conversions to
+// interfaces and σ-nodes.
+func unwrap(v ir.Value) ir.Value {
+	for {
+		switch vv := v.(type) {
+		case *ir.Sigma:
+			v = vv.X
+		case *ir.MakeInterface:
+			v = vv.X
+		case *ir.ChangeInterface:
+			v = vv.X
+		default:
+			return v
+		}
+	}
+}
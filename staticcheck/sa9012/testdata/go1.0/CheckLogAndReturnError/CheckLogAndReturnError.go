@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+type MyError struct{}
+
+func (*MyError) Error() string { return "" }
+
+func do() error               { return nil }
+func doMine() *MyError        { return nil }
+func doValue() (int, error)   { return 0, nil }
+func handle(err error)        {}
+func fn0(l *log.Logger) error { return nil }
+
+func fn1() error {
+	err := do()
+	if err != nil {
+		log.Printf("failed: %v", err) //@ diag(`error is logged and then returned`)
+		return err
+	}
+	return nil
+}
+
+func fn2() (int, error) {
+	n, err := doValue()
+	if err != nil {
+		log.Println("failed:", err) //@ diag(`error is logged and then returned`)
+		return 0, err
+	}
+	return n, nil
+}
+
+func fn3(l *log.Logger) error {
+	if err := do(); err != nil {
+		l.Print(err) //@ diag(`error is logged and then returned`)
+		return err
+	}
+	return nil
+}
+
+func fn4() error {
+	if err := doMine(); err != nil {
+		log.Print(err) //@ diag(`error is logged and then returned`)
+		return err
+	}
+	return nil
+}
+
+func fn5() (err error) {
+	if err = do(); err != nil {
+		log.Print(err) //@ diag(`error is logged and then returned`)
+		return
+	}
+	return nil
+}
+
+func fn6() {
+	// Logging terminates the error's handling
+	if err := do(); err != nil {
+		log.Printf("failed: %v", err)
+		return
+	}
+}
+
+func fn7() error {
+	if err := do(); err != nil {
+		log.Printf("failed: %v", err)
+		return nil
+	}
+	return nil
+}
+
+func fn8() error {
+	// The returned error is a different error
+	if err := do(); err != nil {
+		log.Printf("failed: %v", err)
+		return fmt.Errorf("doing: %w", err)
+	}
+	return nil
+}
+
+func fn9() error {
+	// Not immediately returned
+	if err := do(); err != nil {
+		log.Printf("failed: %v", err)
+		handle(err)
+		return err
+	}
+	return nil
+}
+
+func fn10() error {
+	if err := do(); err != nil {
+		log.Printf("failed: %v", errors.New("other"))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package sa9013
+
+import (
+	"fmt"
+	"go/ast"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9013",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Address of a local variable is stored in a package-level variable`,
+		Text: `Storing the address of a local variable in a package-level variable,
+or in memory reachable from one, as in
+
+    var current *Config
+
+    func load() {
+        var cfg Config
+        ...
+        current = &cfg
+    }
+
+makes the variable outlive the function call that declared it and
+shares it with all code that has access to the package-level
+variable. Further modifications of the local variable are visible
+globally, and each call of the function replaces the shared state.
+This is rarely intended and often the cause of data races.
+
+This check is a heuristic and may flag code that deliberately
+publishes a local variable.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityWarning,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				var addr, val ir.Value
+				switch instr := instr.(type) {
+				case *ir.Store:
+					addr, val = instr.Addr, instr.Val
+				case *ir.MapUpdate:
+					addr, val = instr.Map, instr.Value
+				default:
+					continue
+				}
+				global := rootGlobal(addr)
+				if global == nil {
+					continue
+				}
+				local := rootLocal(val)
+				if local == nil {
+					continue
+				}
+				report.Report(pass, instr,
+					fmt.Sprintf("the address of local variable %s is stored in package-level variable %s, which makes it outlive the function and shares it globally",
+						local.Name, global.Name()))
+			}
+		}
+	}
+	return nil, nil
+}
+
+// rootGlobal returns the package-level variable that the address v is
+// derived from, either directly, or by accessing fields, elements or
+// pointees.
+func rootGlobal(v ir.Value) *ir.Global {
+	for {
+		switch vv := v.(type) {
+		case *ir.Global:
+			return vv
+		case *ir.FieldAddr:
+			v = vv.X
+		case *ir.IndexAddr:
+			v = vv.X
+		case *ir.Slice:
+			v = vv.X
+		case *ir.Load:
+			v = vv.X
+		default:
+			return nil
+		}
+	}
+}
+
+// rootLocal returns the identifier of the local variable whose
+// address, or the address of one of whose fields or elements, is v.
+func rootLocal(v ir.Value) *ast.Ident {
+	for {
+		switch vv := v.(type) {
+		case *ir.Alloc:
+			// Allocs of composite literals and implicitly allocated
+			// memory aren't variables.
+			id, _ := vv.Source().(*ast.Ident)
+			return id
+		case *ir.FieldAddr:
+			v = vv.X
+		case *ir.IndexAddr:
+			v = vv.X
+		case *ir.MakeInterface:
+			v = vv.X
+		case *ir.ChangeType:
+			v = vv.X
+		case *ir.ChangeInterface:
+			v = vv.X
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pkg
+
+type T struct {
+	p *int
+	t *T
+	n int
+}
+
+var (
+	g  *int
+	gt T
+	gp *T
+	gi interface{}
+	gs []*int
+	gm = map[string]*int{}
+)
+
+func fn1(param int) {
+	x := 1
+	g = &x //@ diag(`the address of local variable x is stored in package-level variable g`)
+	y := 2
+	gt.p = &y //@ diag(`local variable y`)
+	z := 3
+	gp.t.p = &z //@ diag(`local variable z`)
+	var t T
+	gp = &t      //@ diag(`local variable t`)
+	gi = &t      //@ diag(`local variable t`)
+	gs[0] = &x   //@ diag(`local variable x`)
+	gm["a"] = &x //@ diag(`local variable x`)
+	g = &t.n     //@ diag(`local variable t`)
+	g = &param   //@ diag(`local variable param`)
+}
+
+func fn2() {
+	// Composite literals aren't variables
+	gp = &T{}
+	g = &[]int{1}[0]
+
+	// Storing values, or the addresses of locals in locals, is fine
+	x := 1
+	gt.p = new(int)
+	*gt.p = x
+	var t T
+	t.p = &x
+	_ = t
+
+	// The address of x is stored in the global, but x isn't global.
+	p := &x
+	*p = 2
+}
+
+func fn3() *int {
+	// Returning the address is fine
+	x := 1
+	return &x
+}
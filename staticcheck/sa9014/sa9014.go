@@ -0,0 +1,206 @@
+package sa9014
+
+import (
+	"go/constant"
+	"go/token"
+	"go/version"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9014",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Resetting a timer that may have fired without draining its channel`,
+		Text: `Before Go 1.23, a timer that fired sent the current time on its
+channel, where the value stayed until it was received. Resetting
+such a timer doesn't discard the stale value, and the next receive
+from the channel returns immediately instead of when the reset
+timer fires. The documentation of \'time.Timer.Reset\' recommends
+stopping the timer and draining its channel before resetting it:
+
+    if !t.Stop() {
+        <-t.C
+    }
+    t.Reset(d)
+
+This check flags calls of \'Reset\' on timers created by
+\'time.NewTimer\' in the same function that are neither preceded by a
+call of \'Stop\', nor by a receive from the timer's channel. To keep
+the number of false positives low, timers that are used in any other
+way, such as being passed to other functions, are ignored.
+
+Go 1.23 changed timers so that resetting them discards stale values.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityWarning,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		if fn.Pos() == token.NoPos || version.Compare(code.StdlibVersion(pass, fn), "go1.23") >= 0 {
+			// Beginning with Go 1.23, Reset discards values that
+			// haven't been received yet.
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				call, ok := instr.(*ir.Call)
+				if !ok || !irutil.IsCallTo(call.Common(), "time.NewTimer") {
+					continue
+				}
+				checkTimer(pass, call)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// checkTimer flags calls of Reset on the timer created by call that
+// aren't dominated by a call of Stop or by a receive from the timer's
+// channel.
+func checkTimer(pass *analysis.Pass, timer *ir.Call) {
+	// resets are the calls of Reset, and drains are the instructions
+	// after which the timer's channel is known to be empty.
+	var resets, drains []ir.Instruction
+	seen := map[ir.Value]bool{}
+	var visit func(v ir.Value) bool
+	visit = func(v ir.Value) bool {
+		if seen[v] {
+			return true
+		}
+		seen[v] = true
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ir.DebugRef:
+			case *ir.Phi:
+				if !visit(ref) {
+					return false
+				}
+			case *ir.Sigma:
+				if !visit(ref) {
+					return false
+				}
+			case *ir.Call:
+				common := ref.Common()
+				if len(common.Args) == 0 || common.Args[0] != v {
+					return false
+				}
+				switch irutil.CallName(common) {
+				case "(*time.Timer).Reset":
+					resets = append(resets, ref)
+				case "(*time.Timer).Stop":
+					drains = append(drains, ref)
+				default:
+					return false
+				}
+			case *ir.FieldAddr:
+				if ref.Field != 0 {
+					return false
+				}
+				ds, ok := channelReceives(ref)
+				if !ok {
+					return false
+				}
+				drains = append(drains, ds...)
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	if !visit(timer) {
+		// The timer is used in ways we don't understand.
+		return
+	}
+
+	for _, reset := range resets {
+		drained := false
+		for _, drain := range drains {
+			if drain != reset && ir.InstructionDominates(drain, reset) {
+				drained = true
+				break
+			}
+		}
+		if !drained {
+			report.Report(pass, reset, "the timer may have fired without its channel being drained, which makes the next receive return immediately; stop the timer and drain its channel before resetting it")
+		}
+	}
+}
+
+// channelReceives returns the instructions after which a value has
+// been received from the channel loaded from addr. If the channel is
+// used for anything but receives, it returns false.
+func channelReceives(addr *ir.FieldAddr) ([]ir.Instruction, bool) {
+	var out []ir.Instruction
+	for _, ref := range *addr.Referrers() {
+		if _, ok := ref.(*ir.DebugRef); ok {
+			continue
+		}
+		load, ok := ref.(*ir.Load)
+		if !ok {
+			return nil, false
+		}
+		for _, ref := range *load.Referrers() {
+			switch ref := ref.(type) {
+			case *ir.DebugRef:
+			case *ir.Recv:
+				out = append(out, ref)
+			case *ir.Select:
+				for i, st := range ref.States {
+					if st.Chan != load {
+						continue
+					}
+					if body := selectCase(ref, i); body != nil {
+						out = append(out, body.Instrs[0])
+					}
+				}
+			default:
+				return nil, false
+			}
+		}
+	}
+	return out, true
+}
+
+// selectCase returns the block that executes when the select statement
+// proceeds with the i'th state, or nil if it cannot be determined.
+func selectCase(sel *ir.Select, i int) *ir.BasicBlock {
+	for _, ref := range *sel.Referrers() {
+		ex, ok := ref.(*ir.Extract)
+		if !ok || ex.Index != 0 {
+			continue
+		}
+		for _, ref := range *ex.Referrers() {
+			sw, ok := ref.(*ir.ConstantSwitch)
+			if !ok || sw.Tag != ex {
+				continue
+			}
+			for j, cond := range sw.Conds {
+				k, ok := cond.(*ir.Const)
+				if !ok || k.Value == nil {
+					continue
+				}
+				if n, ok := constant.Int64Val(k.Value); ok && n == int64(i) {
+					return sw.Block().Succs[j]
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package pkg
+
+import "time"
+
+func fn1(d time.Duration) {
+	t := time.NewTimer(d)
+	<-t.C
+	t.Reset(d)
+}
+
+func fn2(d time.Duration) {
+	t := time.NewTimer(d)
+	if !t.Stop() {
+		<-t.C
+	}
+	t.Reset(d)
+}
+
+func fn3(ch chan int, d time.Duration) {
+	t := time.NewTimer(d)
+	for {
+		select {
+		case <-t.C:
+			t.Reset(d)
+		case <-ch:
+			t.Reset(d) //@ diag(`stop the timer and drain its channel`)
+		}
+	}
+}
+
+func fn4(ch chan int, d time.Duration) {
+	t := time.NewTimer(d)
+	for {
+		select {
+		case <-t.C:
+			return
+		case <-ch:
+			if !t.Stop() {
+				<-t.C
+			}
+			t.Reset(d)
+		}
+	}
+}
+
+func fn5(ch chan int, d time.Duration) {
+	t := time.NewTimer(d)
+	for range ch {
+		t.Reset(d) //@ diag(`stop the timer`)
+		<-t.C
+	}
+}
+
+func fn6(d time.Duration) {
+	// The timer escapes, we don't know who receives from it.
+	t := time.NewTimer(d)
+	consume(t)
+	t.Reset(d)
+}
+
+func fn7(d time.Duration) {
+	// The channel escapes.
+	t := time.NewTimer(d)
+	go consumeChan(t.C)
+	t.Reset(d)
+}
+
+func fn8(ch chan int, d time.Duration) {
+	t := time.NewTimer(d)
+	for {
+		select {
+		case <-t.C:
+		default:
+		}
+		t.Reset(d) //@ diag(`stop the timer`)
+	}
+}
+
+func consume(*time.Timer)          {}
+func consumeChan(<-chan time.Time) {}
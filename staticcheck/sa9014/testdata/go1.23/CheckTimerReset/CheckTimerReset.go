@@ -0,0 +1,15 @@
+package pkg
+
+import "time"
+
+func fn(ch chan int, d time.Duration) {
+	t := time.NewTimer(d)
+	for {
+		select {
+		case <-t.C:
+			return
+		case <-ch:
+			t.Reset(d)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package sa9015
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "SA9015",
+		Run:      run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Deferred calls in a loop accumulate until the function returns`,
+		Text: `Deferred calls run when the surrounding function returns, not at
+the end of the current loop iteration. A \'defer\' statement in a loop
+therefore accumulates one pending call per iteration, holding on to
+resources such as open files or database rows for much longer than
+intended:
+
+    for _, name := range names {
+        f, err := os.Open(name)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        // ...
+    }
+
+Move the body of the loop into a function, so that the deferred call
+runs at the end of each iteration:
+
+    for _, name := range names {
+        err := func() error {
+            f, err := os.Open(name)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            // ...
+        }()
+        if err != nil {
+            return err
+        }
+    }
+
+Deferred calls that are followed by a \'return\' statement in the
+same block don't accumulate and aren't flagged. Neither are defers
+in loops over channels, which are covered by SA9001.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Severity:   lint.SeverityWarning,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	fn := func(node ast.Node, stack []ast.Node) {
+		if returnsAfter(node.(*ast.DeferStmt), stack[len(stack)-2]) {
+			return
+		}
+		for i := len(stack) - 2; i >= 0; i-- {
+			switch loop := stack[i].(type) {
+			case *ast.FuncDecl, *ast.FuncLit:
+				// The defer runs when this function returns, which
+				// might happen once per iteration of an outer loop.
+				return
+			case *ast.RangeStmt:
+				if _, ok := typeutil.CoreType(pass.TypesInfo.TypeOf(loop.X)).(*types.Chan); ok {
+					// SA9001 flags defers in loops over channels.
+					return
+				}
+				report.Report(pass, node, "defer inside a loop is not executed until the surrounding function returns")
+				return
+			case *ast.ForStmt:
+				report.Report(pass, node, "defer inside a loop is not executed until the surrounding function returns")
+				return
+			}
+		}
+	}
+	code.PreorderStack(pass, fn, (*ast.DeferStmt)(nil))
+	return nil, nil
+}
+
+// returnsAfter reports whether the statement list in parent that
+// contains stmt returns from the function after executing stmt.
+func returnsAfter(stmt ast.Stmt, parent ast.Node) bool {
+	var stmts []ast.Stmt
+	switch parent := parent.(type) {
+	case *ast.BlockStmt:
+		stmts = parent.List
+	case *ast.CaseClause:
+		stmts = parent.Body
+	case *ast.CommClause:
+		stmts = parent.Body
+	default:
+		return false
+	}
+	seen := false
+	for _, s := range stmts {
+		if s == stmt {
+			seen = true
+		} else if _, ok := s.(*ast.ReturnStmt); ok && seen {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package pkg
+
+import "os"
+
+func fn1(names []string) error {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //@ diag(`defer inside a loop is not executed until the surrounding function returns`)
+	}
+
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			defer println(i) //@ diag(`defer inside a loop`)
+		}
+	}
+
+	for {
+		switch len(names) {
+		case 0:
+			defer println() //@ diag(`defer inside a loop`)
+		}
+		break
+	}
+	return nil
+}
+
+func fn2(names []string) error {
+	// The closure's deferred call runs at the end of each iteration.
+	for _, name := range names {
+		err := func() error {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		func() {
+			for range names {
+				defer println(name) //@ diag(`defer inside a loop`)
+			}
+		}()
+	}
+	return nil
+}
+
+func fn3(names []string) (*os.File, error) {
+	// Only a single defer ever runs.
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer println("found", name)
+		return f, nil
+	}
+	defer println("not found")
+	return nil, nil
+}
+
+func fn4(ch chan int) {
+	// Left to SA9001
+	for range ch {
+		defer println()
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"honnef.co/go/tools/stylecheck/st1021"
 	"honnef.co/go/tools/stylecheck/st1022"
 	"honnef.co/go/tools/stylecheck/st1023"
+	"honnef.co/go/tools/stylecheck/st1024"
+	"honnef.co/go/tools/stylecheck/st1025"
 )
 
 var Analyzers = []*lint.Analyzer{
@@ -43,4 +45,6 @@ var Analyzers = []*lint.Analyzer{
 	st1021.SCAnalyzer,
 	st1022.SCAnalyzer,
 	st1023.SCAnalyzer,
+	st1024.SCAnalyzer,
+	st1025.SCAnalyzer,
 }
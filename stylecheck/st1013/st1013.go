@@ -31,17 +31,19 @@ package provides constants for all status codes that are part of the
 various specifications. It is recommended to use these constants
 instead of hard-coding magic numbers, to vastly improve the
 readability of your code.`,
-		Since:   "2019.1",
-		Options: []string{"http_status_code_whitelist"},
-		MergeIf: lint.MergeIfAny,
+		Since:     "2019.1",
+		Options:   []string{"http_status_code_whitelist", "http_status_code_min"},
+		MergeIf:   lint.MergeIfAny,
+		SafeFixes: true,
 	},
 })
 
 var Analyzer = SCAnalyzer.Analyzer
 
 func run(pass *analysis.Pass) (interface{}, error) {
+	cfg := config.For(pass)
 	whitelist := map[string]bool{}
-	for _, code := range config.For(pass).HTTPStatusCodeWhitelist {
+	for _, code := range cfg.HTTPStatusCodeWhitelist {
 		whitelist[code] = true
 	}
 	fn := func(node ast.Node) {
@@ -71,7 +73,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		if !ok {
 			return
 		}
-		if whitelist[strconv.FormatInt(n, 10)] {
+		if n < int64(cfg.HTTPStatusCodeMin) || whitelist[strconv.FormatInt(n, 10)] {
 			return
 		}
 
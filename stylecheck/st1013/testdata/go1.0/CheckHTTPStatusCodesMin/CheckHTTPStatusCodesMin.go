@@ -0,0 +1,16 @@
+// Package pkg ...
+package pkg
+
+import "net/http"
+
+func fn() {
+	// Don't flag codes below the minimum
+	http.StatusText(102)
+	http.StatusText(418)
+
+	// Codes at or above the minimum are flagged, unless they are on
+	// the whitelist
+	http.StatusText(500)
+	http.StatusText(501) //@ diag(`http.StatusNotImplemented`)
+	http.StatusText(506) //@ diag(`http.StatusVariantAlsoNegotiates`)
+}
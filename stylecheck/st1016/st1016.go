@@ -2,16 +2,15 @@ package st1016
 
 import (
 	"fmt"
+	"go/ast"
 	"go/types"
-	"sort"
-	"strings"
 
 	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/edit"
 	"honnef.co/go/tools/analysis/facts/generated"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/go/types/typeutil"
-	"honnef.co/go/tools/internal/passes/buildir"
 
 	"golang.org/x/tools/go/analysis"
 )
@@ -20,10 +19,16 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 	Analyzer: &analysis.Analyzer{
 		Name:     "ST1016",
 		Run:      run,
-		Requires: []*analysis.Analyzer{buildir.Analyzer, generated.Analyzer},
+		Requires: []*analysis.Analyzer{generated.Analyzer},
 	},
 	Doc: &lint.RawDocumentation{
-		Title:      `Use consistent method receiver names`,
+		Title: `Use consistent method receiver names`,
+		Text: `All methods of a type should use the same name for their receiver.
+Methods whose receiver name differs from the name used by the
+majority of the type's methods are flagged. If no name is in the
+majority, the name that was seen first wins. Methods with unnamed
+receivers, or receivers named _, are ignored, as are methods declared
+in generated code.`,
 		Since:      "2019.1",
 		NonDefault: true,
 		MergeIf:    lint.MergeIfAny,
@@ -32,43 +37,102 @@ var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
 
 var Analyzer = SCAnalyzer.Analyzer
 
-func run(pass *analysis.Pass) (interface{}, error) {
-	irpkg := pass.ResultOf[buildir.Analyzer].(*buildir.IR).Pkg
-	for _, m := range irpkg.Members {
-		names := map[string]int{}
+type method struct {
+	decl *ast.FuncDecl
+	recv *ast.Ident
+}
 
-		var firstFn *types.Func
-		if T, ok := m.Object().(*types.TypeName); ok && !T.IsAlias() {
-			ms := typeutil.IntuitiveMethodSet(T.Type(), nil)
-			for _, sel := range ms {
-				fn := sel.Obj().(*types.Func)
-				recv := fn.Type().(*types.Signature).Recv()
-				if code.IsGenerated(pass, recv.Pos()) {
-					// Don't concern ourselves with methods in generated code
-					continue
-				}
-				if typeutil.Dereference(recv.Type()) != T.Type() {
-					// skip embedded methods
-					continue
-				}
-				if firstFn == nil {
-					firstFn = fn
-				}
-				if recv.Name() != "" && recv.Name() != "_" {
-					names[recv.Name()]++
-				}
+func run(pass *analysis.Pass) (interface{}, error) {
+	var order []*types.TypeName
+	methods := map[*types.TypeName][]method{}
+	for _, f := range pass.Files {
+		if code.IsGenerated(pass, f.Pos()) {
+			// Don't concern ourselves with methods in generated code
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || len(fn.Recv.List[0].Names) != 1 {
+				continue
+			}
+			recv := fn.Recv.List[0].Names[0]
+			if recv.Name == "_" {
+				continue
+			}
+			obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
 			}
+			named, ok := typeutil.Dereference(obj.Type().(*types.Signature).Recv().Type()).(*types.Named)
+			if !ok {
+				continue
+			}
+			T := named.Origin().Obj()
+			if _, ok := methods[T]; !ok {
+				order = append(order, T)
+			}
+			methods[T] = append(methods[T], method{fn, recv})
 		}
+	}
 
-		if len(names) > 1 {
-			var seen []string
-			for name, count := range names {
-				seen = append(seen, fmt.Sprintf("%dx %q", count, name))
+	for _, T := range order {
+		ms := methods[T]
+		counts := map[string]int{}
+		for _, m := range ms {
+			counts[m.recv.Name]++
+		}
+		if len(counts) < 2 {
+			continue
+		}
+		// Pick the most common name, preferring the name that was seen first in case of ties.
+		var want string
+		for _, m := range ms {
+			if counts[m.recv.Name] > counts[want] {
+				want = m.recv.Name
 			}
-			sort.Strings(seen)
+		}
 
-			report.Report(pass, firstFn, fmt.Sprintf("methods on the same type should have the same receiver name (seen %s)", strings.Join(seen, ", ")))
+		for _, m := range ms {
+			if m.recv.Name == want {
+				continue
+			}
+			var opts []report.Option
+			if edits, ok := renameReceiver(pass, m, want); ok {
+				opts = append(opts, report.Fixes(edit.Fix(fmt.Sprintf("rename receiver to %s", want), edits...)))
+			}
+			report.Report(pass, m.recv,
+				fmt.Sprintf("receiver name %s should be consistent with previously seen receiver name %s", m.recv.Name, want),
+				opts...)
 		}
 	}
 	return nil, nil
 }
+
+// renameReceiver returns the edits needed to rename the receiver of m, as well as all of its uses, to name. It fails if
+// name is already in use anywhere in the method, as renaming the receiver could then change the meaning of the code.
+func renameReceiver(pass *analysis.Pass, m method, name string) ([]analysis.TextEdit, bool) {
+	obj := pass.TypesInfo.Defs[m.recv]
+	if obj == nil {
+		return nil, false
+	}
+	edits := []analysis.TextEdit{edit.ReplaceWithString(m.recv, name)}
+	ok := true
+	ast.Inspect(m.decl, func(node ast.Node) bool {
+		if !ok {
+			return false
+		}
+		ident, isIdent := node.(*ast.Ident)
+		if !isIdent {
+			return true
+		}
+		if ident.Name == name {
+			ok = false
+			return false
+		}
+		if pass.TypesInfo.Uses[ident] == obj {
+			edits = append(edits, edit.ReplaceWithString(ident, name))
+		}
+		return true
+	})
+	return edits, ok
+}
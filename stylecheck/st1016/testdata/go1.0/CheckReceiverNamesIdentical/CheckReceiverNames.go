@@ -3,14 +3,35 @@ package pkg
 
 type T1 int
 
-func (x T1) Fn1()    {} //@ diag(`methods on the same type should have the same receiver name`)
-func (y T1) Fn2()    {}
+func (x T1) Fn1()    {}
+func (y T1) Fn2()    {} //@ diag(`receiver name y should be consistent with previously seen receiver name x`)
 func (x T1) Fn3()    {}
 func (T1) Fn4()      {}
 func (_ T1) Fn5()    {}
-func (self T1) Fn6() {}
+func (self T1) Fn6() {} //@ diag(`receiver name self should be consistent with previously seen receiver name x`)
 
-func (bar T3) Fn2()  {} //@ diag(`1x "bar", 1x "meow"`)
-func (meow T3) Fn3() {}
+func (bar T3) Fn2()  {}
+func (meow T3) Fn3() {} //@ diag(`receiver name meow should be consistent with previously seen receiver name bar`)
 
 func (bar T4) Fn2() {}
+
+type T5 struct{ f int }
+
+func (t *T5) Fn1() int { return t.f }
+func (t *T5) Fn2() int { return t.f }
+func (t *T5) Fn6() int { return t.f }
+func (t *T5) Fn7() int { return t.f }
+func (s *T5) Fn3() int { //@ diag(`receiver name s should be consistent with previously seen receiver name t`)
+	if s == nil {
+		return 0
+	}
+	fn := func(s *T5) int { return s.f }
+	return s.f + fn(s)
+}
+func (s T5) Fn4(t int) int { //@ diag(`receiver name s should be consistent with previously seen receiver name t`)
+	return s.f + t
+}
+func (s T5) Fn5() int { //@ diag(`receiver name s should be consistent with previously seen receiver name t`)
+	t := 1
+	return s.f + t
+}
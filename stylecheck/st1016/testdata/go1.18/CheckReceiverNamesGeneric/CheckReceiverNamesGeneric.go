@@ -0,0 +1,7 @@
+package pkg
+
+type G[T any] struct{ v T }
+
+func (g G[T]) Fn1() T  { return g.v }
+func (g *G[T]) Fn2() T { return g.v }
+func (x *G[E]) Fn3() E { return x.v } //@ diag(`receiver name x should be consistent with previously seen receiver name g`)
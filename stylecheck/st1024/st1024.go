@@ -0,0 +1,125 @@
+package st1024
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "ST1024",
+		Run:      run,
+		Requires: []*analysis.Analyzer{generated.Analyzer, inspect.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: "Exported functions shouldn't return unexported types",
+		Text: `Callers of an exported function that returns an unexported
+type can't refer to the type by name. They can't declare variables of
+the type, store it in struct fields, or pass it to their own functions,
+which makes the result awkward to use.
+
+Either export the type, or return an exported interface that the type
+implements. Unexported interface types aren't flagged, as callers can
+still use them structurally, by assigning them to interfaces of their
+own.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Name() == "main" {
+		// Nothing can import package main.
+		return nil, nil
+	}
+
+	fn := func(node ast.Node) {
+		decl := node.(*ast.FuncDecl)
+		if !decl.Name.IsExported() || decl.Type.Results == nil || code.IsInTest(pass, decl) {
+			return
+		}
+		obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			return
+		}
+		sig := obj.Type().(*types.Signature)
+		if recv := sig.Recv(); recv != nil {
+			named, ok := types.Unalias(typeutil.Dereference(recv.Type())).(*types.Named)
+			if !ok || !named.Obj().Exported() {
+				// Methods on unexported types are usually only
+				// reachable through interfaces.
+				return
+			}
+		}
+
+		i := 0
+		for _, field := range decl.Type.Results.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			if tn := unexportedType(pass, sig.Results().At(i).Type()); tn != nil {
+				report.Report(pass, field.Type,
+					fmt.Sprintf("exported %s %s returns unexported type %s, which callers can't refer to by name",
+						kind(sig), decl.Name.Name, tn.Name()),
+					report.FilterGenerated())
+			}
+			i += n
+		}
+	}
+	code.Preorder(pass, fn, (*ast.FuncDecl)(nil))
+	return nil, nil
+}
+
+func kind(sig *types.Signature) string {
+	if sig.Recv() != nil {
+		return "method"
+	}
+	return "function"
+}
+
+// unexportedType returns the unexported type declared in the current
+// package that typ refers to, either directly, via a composite type,
+// or as a type argument.
+func unexportedType(pass *analysis.Pass, typ types.Type) *types.TypeName {
+	switch typ := types.Unalias(typ).(type) {
+	case *types.Pointer:
+		return unexportedType(pass, typ.Elem())
+	case *types.Slice:
+		return unexportedType(pass, typ.Elem())
+	case *types.Array:
+		return unexportedType(pass, typ.Elem())
+	case *types.Chan:
+		return unexportedType(pass, typ.Elem())
+	case *types.Map:
+		if tn := unexportedType(pass, typ.Key()); tn != nil {
+			return tn
+		}
+		return unexportedType(pass, typ.Elem())
+	case *types.Named:
+		obj := typ.Origin().Obj()
+		if obj.Pkg() == pass.Pkg && !obj.Exported() && !types.IsInterface(typ) {
+			return obj
+		}
+		targs := typ.TypeArgs()
+		for i := 0; i < targs.Len(); i++ {
+			if tn := unexportedType(pass, targs.At(i)); tn != nil {
+				return tn
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package pkg
+
+type unexported struct{}
+
+type iface interface{ Foo() }
+
+type Exported struct{}
+
+type Generic[T any] struct{ v T }
+
+type Alias = unexported
+
+type Reader interface{ Read([]byte) (int, error) }
+
+func (unexported) Foo()                     {}
+func (unexported) Read([]byte) (int, error) { return 0, nil }
+
+func Fn1() unexported          { return unexported{} }          //@ diag(`exported function Fn1 returns unexported type unexported`)
+func Fn2() *unexported         { return nil }                   //@ diag(`returns unexported type unexported`)
+func Fn3() []unexported        { return nil }                   //@ diag(`returns unexported type unexported`)
+func Fn4() (int, unexported)   { return 0, unexported{} }       //@ diag(`returns unexported type unexported`)
+func Fn5() Generic[unexported] { return Generic[unexported]{} } //@ diag(`returns unexported type unexported`)
+func Fn6() Alias               { return Alias{} }               //@ diag(`returns unexported type unexported`)
+func Fn7() iface               { return nil }                   // callers can use the interface structurally
+
+func Fn8() Exported                   { return Exported{} }
+func Fn9() Reader                     { return unexported{} }
+func Fn10() Generic[int]              { return Generic[int]{} }
+func Fn11[T any]() T                  { var x T; return x }
+func fn12() unexported                { return unexported{} }
+func Fn13() (a, b int, c *unexported) { return 0, 0, nil } //@ diag(`returns unexported type unexported`)
+
+func (Exported) Method() unexported   { return unexported{} } //@ diag(`exported method Method returns unexported type unexported`)
+func (unexported) Method() unexported { return unexported{} }
@@ -0,0 +1,58 @@
+package st1025
+
+import (
+	"fmt"
+	"go/ast"
+
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/internal/passes/buildir"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var SCAnalyzer = lint.InitializeAnalyzer(&lint.Analyzer{
+	Analyzer: &analysis.Analyzer{
+		Name:     "ST1025",
+		Run:      run,
+		Requires: []*analysis.Analyzer{buildir.Analyzer, config.Analyzer, generated.Analyzer},
+	},
+	Doc: &lint.RawDocumentation{
+		Title: `Function is too complex`,
+		Text: `Functions with many branches and loops are hard to read, test and
+modify. This check flags functions whose cyclomatic complexity, the
+number of linearly independent paths through the function, exceeds
+the limit set by the \'max_cyclomatic_complexity\' option.
+
+Function literals are measured separately from the functions that
+contain them and aren't flagged.`,
+		Since:      "Unreleased",
+		NonDefault: true,
+		Options:    []string{"max_cyclomatic_complexity"},
+		MergeIf:    lint.MergeIfAny,
+	},
+})
+
+var Analyzer = SCAnalyzer.Analyzer
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	limit := config.For(pass).MaxCyclomaticComplexity
+	if limit <= 0 {
+		return nil, nil
+	}
+	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
+		decl, ok := fn.Source().(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if n := ir.CyclomaticComplexity(fn); n > limit {
+			report.Report(pass, decl.Name,
+				fmt.Sprintf("%s has a cyclomatic complexity of %d, which exceeds the limit of %d", decl.Name.Name, n, limit),
+				report.FilterGenerated())
+		}
+	}
+	return nil, nil
+}
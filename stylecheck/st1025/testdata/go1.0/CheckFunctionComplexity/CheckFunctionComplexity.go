@@ -0,0 +1,48 @@
+package pkg
+
+func simple(x int) int {
+	if x > 0 {
+		return x
+	}
+	return -x
+}
+
+func complicated(xs []int, s string) int { //@ diag(`complicated has a cyclomatic complexity of 7, which exceeds the limit of 5`)
+	n := 0
+	for _, x := range xs {
+		if x > 10 {
+			n += x
+		} else if x < 0 {
+			n -= x
+		}
+	}
+	switch s {
+	case "a":
+		n++
+	case "b":
+		n--
+	case "c":
+		n *= 2
+	}
+	return n
+}
+
+func withClosure(xs []int) func() int {
+	return func() int {
+		n := 0
+		for _, x := range xs {
+			if x > 10 {
+				n += x
+			} else if x < 0 {
+				n -= x
+			} else if x == 5 {
+				n *= x
+			}
+		}
+		switch n {
+		case 1, 2, 3:
+			n++
+		}
+		return n
+	}
+}